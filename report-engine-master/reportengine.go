@@ -91,16 +91,54 @@ func main() {
 	mysqlUserTokenRepository := mysqlDB.NewMysqlUserTokenRepository(mysqlUserRepository, logger)
 	mysqlWorkspaceRepository := mysqlDB.NewMysqlWorkspaceRepository(mysqlConn, logger)
 	mysqlPostingTaskRepository := mysqlDB.NewMySQLPostReportTaskRepository(mysqlConn, logger)
+	mysqlProcessedMessageRepository := mysqlDB.NewMySQLProcessedMessageRepository(mysqlConn, logger)
 
 	powerBiClient := powerbi.NewServiceClient(conf.OAuthConfig, &conf.PowerBiClient, mysqlUserTokenRepository, logger)
 
 	dbQueryTimeout := time.Duration(conf.DB.Timeout) * time.Second
 
+	if conf.MessageQueue.DeadLetterURL == "" {
+		logger.Error("dead letter queue must be set")
+
+		return
+	}
+
+	scheduledConfig := *conf.MessageQueue
+	scheduledConfig.URL = conf.MessageQueue.ScheduledURL
+
+	deadLetterConfig := *conf.MessageQueue
+	deadLetterConfig.URL = conf.MessageQueue.DeadLetterURL
+
 	mq := messagequeue.MessageQueue(nil)
+	scheduledMQ := messagequeue.MessageQueue(nil)
+	deadLetters := messagequeue.MessageQueue(nil)
 	switch conf.MessageQueue.Implementation {
 	case config.MQSQS:
+		// NOTE: Failed reports are retried after a delay.
+		if conf.MessageQueue.DelayURL == "" {
+			logger.Error("delay queue must be set")
+
+			return
+		}
+
 		q := sqs.New(awsSession)
 		mq = messagequeue.NewSQSMessageQueue(q, conf.MessageQueue, logger)
+		if scheduledConfig.URL != "" {
+			scheduledMQ = messagequeue.NewSQSMessageQueue(q, &scheduledConfig, logger)
+		}
+
+		forwardCtx, cancelForwarding := context.WithCancel(context.Background())
+		defer cancelForwarding()
+		utils.SafeRoutine(func() {
+			messagequeue.ForwardDelayed(forwardCtx, q, conf.MessageQueue, logger)
+		})
+
+		deadLetters = messagequeue.NewSQSMessageQueue(q, &deadLetterConfig, logger)
+
+	case config.MQMySQL:
+		mq = messagequeue.NewMySQLMessageQueue(mysqlConn, conf.MessageQueue, logger)
+		scheduledMQ = messagequeue.NewMySQLMessageQueue(mysqlConn, &scheduledConfig, logger)
+		deadLetters = messagequeue.NewMySQLMessageQueue(mysqlConn, &deadLetterConfig, logger)
 
 	default:
 		logger.Error("unknown message queue implementation", zap.String("implementation", string(conf.MessageQueue.Implementation)))
@@ -108,8 +146,30 @@ func main() {
 		return
 	}
 
+	if scheduledMQ != nil {
+		mq = messagequeue.NewPrioritizedMessageQueue(mq, scheduledMQ, conf.MessageQueue.InteractiveRatio)
+	}
+
+	mq = messagequeue.NewValidatingMessageQueue(mq, messagequeue.DefaultRegistry)
+	deadLetters = messagequeue.NewValidatingMessageQueue(deadLetters, messagequeue.DefaultRegistry)
+
 	cdpEngine := reportengine.NewCDPReportEngine(conf.Browser, logger)
-	reportengine.SetDefaultReportEngine(cdpEngine)
+
+	// NOTE: A token isn't refreshed here, so a report w/ an expired one is just rendered w/o cache; the same goes for a slow Power BI API.
+	resolveRefresh := func(ctx context.Context, o *utils.ShareOptions) (time.Time, error) {
+		resolveCtx, cancelResolve := context.WithTimeout(ctx, conf.ImageCache.RefreshTimeout)
+		defer cancelResolve()
+
+		return powerBiClient.GetDatasetRefreshTime(resolveCtx, nil, useCase.Token{AccessToken: o.AccessToken}, o.ReportID)
+	}
+	reportEngine, err := reportengine.NewReportEngine(cdpEngine, conf, resolveRefresh, logger)
+	if err != nil {
+		logger.Error("couldn't create report engine", zap.Error(err))
+
+		return
+	}
+
+	reportengine.SetDefaultReportEngine(reportEngine)
 	err = cdpEngine.Start(context.Background())
 	if err != nil {
 		logger.Error("couldn't start Chrome instance", zap.Error(err))
@@ -125,7 +185,7 @@ func main() {
 		}
 	}()
 
-	retryStrategy := reportengine.NewRetryStrategy(logger, conf.MessageQueue.URL, conf.MaxAttempts, awsSession)
+	retryStrategy := reportengine.NewRetryStrategy(logger, mq, conf.RetryStrategy)
 	userUsecase := useCase.NewUserUsecase(mysqlUserRepository, dbQueryTimeout, conf.DB.UserIDHashCost, conf.OAuthConfig, logger)
 	reportUsecase := useCase.NewReportUsecase(*powerBiClient, mysqlWorkspaceRepository, mysqlPostingTaskRepository, mysqlUserRepository, mq, dbQueryTimeout, logger, retryStrategy)
 	workspaceUsecase := useCase.NewWorkspaceUsecase(mysqlWorkspaceRepository, dbQueryTimeout)
@@ -133,8 +193,15 @@ func main() {
 	analytics.SetDefaultAmplitudeClient(amplitude.NewClient(conf.AmplitudeKey), logger)
 
 	handleMessagesCtx, cancelHandling := context.WithCancel(context.Background())
-	dispatcher := messageHandler.NewMessageDispatcher(mq, conf.MessageHandler, logger)
-	handleReportMessages := messageHandler.NewReportWorker(reportUsecase, userUsecase, workspaceUsecase, logger)
+	dispatcher := messageHandler.NewMessageDispatcher(mq, deadLetters, conf.MessageHandler, logger)
+	keyRing, err := messagequeue.NewKeyRing(conf.MessageSecrets.Keys, conf.MessageSecrets.CurrentKeyID)
+	if err != nil {
+		logger.Error("couldn't create key ring", zap.Error(err))
+
+		return
+	}
+
+	handleReportMessages := messageHandler.NewReportWorker(reportUsecase, userUsecase, workspaceUsecase, mysqlProcessedMessageRepository, conf.Dedupe, keyRing, conf.MessageSecrets, logger)
 	err = dispatcher.RegisterWorker(handleReportMessages)
 	if err != nil {
 		logger.Error("couldn't register worker", zap.Error(err))
@@ -142,11 +209,21 @@ func main() {
 		return
 	}
 
+	distributeReports := messageHandler.NewDistributeReportWorker(reportUsecase, userUsecase, workspaceUsecase, mysqlProcessedMessageRepository, conf.Dedupe, keyRing, conf.MessageSecrets, logger)
+	err = dispatcher.RegisterWorker(distributeReports)
+	if err != nil {
+		logger.Error("couldn't register worker", zap.Error(err))
+
+		return
+	}
+
 	dispatcher.Start(handleMessagesCtx)
+	messageHandler.StartProcessedMessagesCleanup(handleMessagesCtx, mysqlProcessedMessageRepository, conf.Dedupe, logger)
 
 	defer func() {
 		logger.Debug("stopping message handling")
-		stopHandlingCtx, cancelStopping := context.WithTimeout(handleMessagesCtx, time.Duration(conf.Host.ShutdownTimeout)*time.Second)
+		// NOTE: handleMessagesCtx is cancelled by now, while in-flight messages should be handled till shutdown timeout.
+		stopHandlingCtx, cancelStopping := context.WithTimeout(context.Background(), time.Duration(conf.Host.ShutdownTimeout)*time.Second)
 		defer cancelStopping()
 		err := dispatcher.Stop(stopHandlingCtx)
 		if err != nil && err != context.Canceled {
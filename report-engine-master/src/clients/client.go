@@ -1,6 +1,7 @@
 package clients
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -42,7 +43,12 @@ func DeserializeToken(b io.ReadCloser) (*Token, error) {
 
 // HandleHTTPRequest executes requests
 func HandleHTTPRequest(method string, url string, headers map[string]string, body io.Reader, isCloseBody bool) (*http.Response, error) {
-	req, err := http.NewRequest(method, url, body)
+	return HandleHTTPRequestWithContext(context.Background(), method, url, headers, body, isCloseBody)
+}
+
+// HandleHTTPRequestWithContext executes requests till ctx is done
+func HandleHTTPRequestWithContext(ctx context.Context, method string, url string, headers map[string]string, body io.Reader, isCloseBody bool) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 
 	if err != nil {
 		return nil, err
@@ -7,13 +7,19 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
 )
 
 const (
-	reportsURI = "/reports"
+	reportsURI    = "/reports"
+	datasetsURI   = "/datasets"
+	dashboardsURI = "/dashboards"
+	tilesURI      = "/tiles"
+	// NOTE: A refresh in progress is listed first, so a few more are fetched to find a completed one.
+	datasetRefreshesTop = 5
 )
 
 // TokenCacheManager interface describe a contract for working with token cache
@@ -45,9 +51,24 @@ func NewServiceClient(
 	}
 }
 
-// GetReport returns report by reportID
+// GetReport returns report by reportID; a dashboard or a tile is returned as a report, so it's posted the same way.
 func (c *ServiceClient) GetReport(consumerID interface{}, accessData domain.AccessData, reportID string) (*domain.Report, error) {
-	r, err := c.get(consumerID, accessData, reportsURI+"/"+reportID, func(b io.ReadCloser) (interface{}, error) {
+	return c.getReport(context.Background(), consumerID, accessData, reportID)
+}
+
+func (c *ServiceClient) getReport(ctx context.Context, consumerID interface{}, accessData domain.AccessData, reportID string) (*domain.Report, error) {
+	ref, err := domain.ParseReportID(reportID)
+	if err != nil {
+		c.logger.Error("couldn't parse report id", zap.Error(err), zap.String("reportID", reportID))
+
+		return nil, err
+	}
+
+	if ref.Type == domain.ReportTypeDashboard || ref.Type == domain.ReportTypeTile {
+		return c.getDashboardOrTile(ctx, consumerID, accessData, reportID, ref)
+	}
+
+	r, err := c.get(ctx, consumerID, accessData, reportsURI+"/"+ref.ID, func(b io.ReadCloser) (interface{}, error) {
 		return domain.DeserializeReport(b)
 	})
 	if err != nil {
@@ -59,6 +80,76 @@ func (c *ServiceClient) GetReport(consumerID interface{}, accessData domain.Acce
 	return r.(*domain.Report), nil
 }
 
+// NOTE: A tile keeps a dataset of its own, so a tile is cached as a report is; a dashboard has many of them, so it's never cached.
+func (c *ServiceClient) getDashboardOrTile(ctx context.Context, consumerID interface{}, accessData domain.AccessData, reportID string, ref *domain.ReportRef) (*domain.Report, error) {
+	dashboardID := ref.ID
+	if ref.Type == domain.ReportTypeTile {
+		dashboardID = ref.DashboardID
+	}
+
+	d, err := c.get(ctx, consumerID, accessData, dashboardsURI+"/"+dashboardID, func(b io.ReadCloser) (interface{}, error) {
+		return domain.DeserializeDashboard(b)
+	})
+	if err != nil {
+		c.logger.Error("couldn't get dashboard", zap.Error(err), zap.String("dashboardID", dashboardID))
+
+		return nil, err
+	}
+
+	report := domain.Report{
+		ID:     reportID,
+		Name:   d.(*domain.Dashboard).GetName(),
+		WebURL: d.(*domain.Dashboard).GetWebURL(),
+	}
+	if ref.Type != domain.ReportTypeTile {
+		return &report, nil
+	}
+
+	t, err := c.get(ctx, consumerID, accessData, fmt.Sprintf("%v/%v%v/%v", dashboardsURI, dashboardID, tilesURI, ref.ID), func(b io.ReadCloser) (interface{}, error) {
+		return domain.DeserializeTile(b, d.(*domain.Dashboard))
+	})
+	if err != nil {
+		c.logger.Error("couldn't get tile", zap.Error(err), zap.String("tileID", ref.ID))
+
+		return nil, err
+	}
+
+	report.Name = t.(*domain.Tile).GetName()
+	report.DatasetID = t.(*domain.Tile).DatasetID
+
+	return &report, nil
+}
+
+// GetDatasetRefreshTime returns the time data of a report was last refreshed at; it's zero if the dataset was never refreshed (e.g. DirectQuery one).
+// NOTE: Requests are canceled along w/ ctx, so a slow Power BI API doesn't hold a render.
+func (c *ServiceClient) GetDatasetRefreshTime(ctx context.Context, consumerID interface{}, accessData domain.AccessData, reportID string) (time.Time, error) {
+	r, err := c.getReport(ctx, consumerID, accessData, reportID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if r.DatasetID == "" {
+		return time.Time{}, nil
+	}
+
+	rs, err := c.get(ctx, consumerID, accessData, datasetRefreshesURI(r.DatasetID), func(b io.ReadCloser) (interface{}, error) {
+		return domain.DeserializeDatasetRefreshesContainer(b)
+	})
+	if err != nil {
+		c.logger.Error("couldn't get dataset refreshes", zap.Error(err), zap.String("datasetID", r.DatasetID))
+
+		return time.Time{}, err
+	}
+
+	for _, r := range rs.(*domain.DatasetRefreshesContainer).Value {
+		if r.Status == domain.DatasetRefreshCompleted {
+			return r.EndTime, nil
+		}
+	}
+
+	return time.Time{}, nil
+}
+
 // RefreshTokens implements "refresh_token" grant type.
 func (c *ServiceClient) RefreshTokens(refreshToken string) (domain.AccessData, error) {
 	headers := map[string]string{
@@ -98,8 +189,11 @@ func pagesURI(reportID string) string {
 	return fmt.Sprintf("%v/%v/pages", reportsURI, reportID)
 }
 
-func (c *ServiceClient) get(consumerID interface{}, accessData domain.AccessData, resource string, deserialize func(reader io.ReadCloser) (interface{}, error)) (interface{}, error) {
-	ctx := context.Background()
+func datasetRefreshesURI(datasetID string) string {
+	return fmt.Sprintf("%v/%v/refreshes?$top=%v", datasetsURI, datasetID, datasetRefreshesTop)
+}
+
+func (c *ServiceClient) get(ctx context.Context, consumerID interface{}, accessData domain.AccessData, resource string, deserialize func(reader io.ReadCloser) (interface{}, error)) (interface{}, error) {
 	if consumerID != nil {
 		var err error
 		accessData, err = c.tokenCache.Get(ctx, consumerID)
@@ -123,7 +217,7 @@ func (c *ServiceClient) executeHTTPRequest(ctx context.Context, consumerID inter
 		constants.HTTPHeaderAuthorization: constants.BearerTokenType + accessData.GetAccessToken(),
 	}
 
-	res, err := clients.HandleHTTPRequest(http.MethodGet, c.config.APIURL+resource, headers, nil, false)
+	res, err := clients.HandleHTTPRequestWithContext(ctx, http.MethodGet, c.config.APIURL+resource, headers, nil, false)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,8 @@
 package teams
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -15,14 +17,19 @@ import (
 
 const (
 	graphEndpoint = "https://graph.microsoft.com/v1.0/"
+	methodGET     = "GET"
 	methodPOST    = "POST"
+	methodPUT     = "PUT"
 	retryAttempts = 5
 	failedMessage = "{\"body\": {\"content\": \"Sorry, we couldn't generate report %v\"}}"
 )
 
 func sendRequest(method string, u string, headers map[string]string, body string) error {
-	payload := strings.NewReader(body)
+	return doRequest(method, u, headers, strings.NewReader(body), nil)
+}
 
+// doRequest sends a request to Graph API; a response body is unmarshalled into res unless it's nil.
+func doRequest(method string, u string, headers map[string]string, payload io.Reader, res interface{}) error {
 	client := &http.Client{}
 	address, _ := url.Parse(graphEndpoint)
 	address.Path = path.Join(address.Path, u)
@@ -37,23 +44,28 @@ func sendRequest(method string, u string, headers map[string]string, body string
 		req.Header.Add(h, v)
 	}
 
-	res, err := client.Do(req)
+	r, err := client.Do(req)
 	if err != nil {
 		return err
 	}
 
-	// Checked, if status code isn't 2**, then exception
-	if res.StatusCode/100 != 2 {
-		return domain.ErrUnexpectedStatusCode(res.StatusCode)
-	}
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
 		if err != nil {
 			return
 		}
-	}(res.Body)
+	}(r.Body)
 
-	return nil
+	// Checked, if status code isn't 2**, then exception
+	if r.StatusCode/100 != 2 {
+		return domain.ErrUnexpectedStatusCode(r.StatusCode)
+	}
+
+	if res == nil {
+		return nil
+	}
+
+	return json.NewDecoder(r.Body).Decode(res)
 }
 
 func getHeadersAndEndpoint(authToken string, o *utils.ShareOptions) (map[string]string, string) {
@@ -67,10 +79,17 @@ func getHeadersAndEndpoint(authToken string, o *utils.ShareOptions) (map[string]
 	return headers, endpoint
 }
 
-func SendMessage(encodedReport string, o *utils.ShareOptions, botToken string) error {
+// SendMessage posts an image of width & height pixels; an image of unknown size (0) is shown as Teams sizes it.
+func SendMessage(encodedReport string, contentType string, width, height int, o *utils.ShareOptions, botToken string) error {
+	// NOTE: Teams shows an image at the size given, so a mobile layout, a single visual or a downscaled image isn't stretched to a landscape page.
+	size := ""
+	if width > 0 && height > 0 {
+		size = fmt.Sprintf(` height=\"%v\" width=\"%v\" style=\"vertical-align:bottom; width:%vpx; height:%vpx\"`, height, width, width, height)
+	}
+
 	err := retry.Do(
 		func() error {
-			body := fmt.Sprintf("{\n  \"messageType\": \"message\",\n  \"body\": {\n    \"contentType\": \"html\",\n    \"content\": \"Report generated</br><div><span><img height=\\\"720\\\" src=\\\"../hostedContents/1/$value\\\" width=\\\"1280\\\" style=\\\"vertical-align:bottom; width:1280px; height:720px\\\"></span>\\n</div>\"\n  },\n  \"hostedContents\": [\n    {\n      \"@microsoft.graph.temporaryId\": \"1\",\n      \"contentBytes\": \"%s\",\n      \"contentType\": \"image/png\"\n    }\n  ]\n}", encodedReport)
+			body := fmt.Sprintf("{\n  \"messageType\": \"message\",\n  \"body\": {\n    \"contentType\": \"html\",\n    \"content\": \"Report generated</br><div><span><img src=\\\"../hostedContents/1/$value\\\"%s></span>\\n</div>\"\n  },\n  \"hostedContents\": [\n    {\n      \"@microsoft.graph.temporaryId\": \"1\",\n      \"contentBytes\": \"%s\",\n      \"contentType\": \"%s\"\n    }\n  ]\n}", size, encodedReport, contentType)
 			headers, endpoint := getHeadersAndEndpoint(botToken, o)
 
 			err := sendRequest(methodPOST, endpoint, headers, body)
@@ -83,6 +102,109 @@ func SendMessage(encodedReport string, o *utils.ShareOptions, botToken string) e
 	return err
 }
 
+// driveItem is a file or folder of a channel's SharePoint site.
+type driveItem struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	ETag            string `json:"eTag"`
+	WebURL          string `json:"webUrl"`
+	ParentReference struct {
+		DriveID string `json:"driveId"`
+	} `json:"parentReference"`
+}
+
+// NOTE: These characters aren't allowed in SharePoint file names.
+var filenameReplacer = strings.NewReplacer(`"`, "-", "*", "-", ":", "-", "<", "-", ">", "-", "?", "-", "/", "-", `\`, "-", "|", "-")
+
+// SendFile uploads a file to the channel files folder & posts a message referencing it, since Graph API doesn't host files other than images in messages.
+func SendFile(filename string, data []byte, o *utils.ShareOptions, botToken string) error {
+	headers := map[string]string{
+		"Authorization": fmt.Sprintf("Bearer %v", botToken),
+	}
+
+	folder := driveItem{}
+	err := retry.Do(
+		func() error {
+			endpoint := fmt.Sprintf("/teams/%v/channels/%v/filesFolder", o.WorkspaceID, o.ChannelID)
+
+			return doRequest(methodGET, endpoint, headers, nil, &folder)
+		},
+		retry.Attempts(retryAttempts),
+	)
+	if err != nil {
+		return err
+	}
+
+	file := driveItem{}
+	err = retry.Do(
+		func() error {
+			endpoint := fmt.Sprintf("/drives/%v/items/%v:/%v:/content", folder.ParentReference.DriveID, folder.ID, filenameReplacer.Replace(filename))
+
+			return doRequest(methodPUT, endpoint, headers, bytes.NewReader(data), &file)
+		},
+		retry.Attempts(retryAttempts),
+	)
+	if err != nil {
+		return err
+	}
+
+	// NOTE: Attachment ID must be the GUID part of eTag, which looks like `"{GUID},1"'.
+	attachmentID := file.ETag
+	if i, j := strings.Index(attachmentID, "{"), strings.Index(attachmentID, "}"); i != -1 && j > i {
+		attachmentID = attachmentID[i+1 : j]
+	}
+
+	message := map[string]interface{}{
+		"body": map[string]string{
+			"contentType": "html",
+			"content":     fmt.Sprintf("Report generated <attachment id=\"%v\"></attachment>", attachmentID),
+		},
+		"attachments": []map[string]string{
+			{
+				"id":          attachmentID,
+				"contentType": "reference",
+				"contentUrl":  file.WebURL,
+				"name":        file.Name,
+			},
+		},
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return retry.Do(
+		func() error {
+			headers, endpoint := getHeadersAndEndpoint(botToken, o)
+
+			return sendRequest(methodPOST, endpoint, headers, string(body))
+		},
+		retry.Attempts(retryAttempts),
+	)
+}
+
+// SendTextMessage posts a plain text message, e.g. a notice about a report.
+func SendTextMessage(text string, o *utils.ShareOptions, botToken string) error {
+	message := map[string]interface{}{
+		"body": map[string]string{
+			"content": text,
+		},
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return retry.Do(
+		func() error {
+			headers, endpoint := getHeadersAndEndpoint(botToken, o)
+
+			return sendRequest(methodPOST, endpoint, headers, string(body))
+		},
+		retry.Attempts(retryAttempts),
+	)
+}
+
 func SendFailedMessage(o *utils.ShareOptions, botToken string) error {
 	err := retry.Do(
 		func() error {
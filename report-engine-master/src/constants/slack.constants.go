@@ -23,6 +23,36 @@ func FormatMessageTitleWithFilter(reportName, filterDescription, pageName string
 	return fmt.Sprintf("Report: %v; Filter: %v; Page: %v", reportName, filterDescription, pageName)
 }
 
+// FormatVisualTitle formats title of a message w/ a single visual.
+func FormatVisualTitle(reportName, pageName, visualName string) string {
+	return fmt.Sprintf("Report: %v; Page: %v; Visual: %v", reportName, pageName, visualName)
+}
+
+// FormatVisualTitleWithFilter formats title of a message w/ a single visual.
+func FormatVisualTitleWithFilter(reportName, filterDescription, pageName, visualName string) string {
+	return fmt.Sprintf("Report: %v; Filter: %v; Page: %v; Visual: %v", reportName, filterDescription, pageName, visualName)
+}
+
+// FormatDocumentTitle formats title of a message w/ all pages in a single file.
+func FormatDocumentTitle(reportName string) string {
+	return fmt.Sprintf("Report: %v", reportName)
+}
+
+// FormatDocumentTitleWithFilter formats title of a message w/ all pages in a single file.
+func FormatDocumentTitleWithFilter(reportName, filterDescription string) string {
+	return fmt.Sprintf("Report: %v; Filter: %v", reportName, filterDescription)
+}
+
+// FormatDataExportNotAllowed formats a notice posted instead of data of a report which doesn't allow export.
+func FormatDataExportNotAllowed(reportName string) string {
+	return fmt.Sprintf("Data of report %v can't be attached, since the report doesn't allow exporting it.", reportName)
+}
+
+// FormatReportURL formats report URL.
+func FormatReportURL(reportURL string) string {
+	return fmt.Sprintf("<%v|%v>", reportURL, LabelViewReport)
+}
+
 // FormatPageURL formats page URL.
 func FormatPageURL(reportURL, pageID string) string {
 	pageURL := fmt.Sprintf("%v/%v", reportURL, pageID)
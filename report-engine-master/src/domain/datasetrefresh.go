@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// DatasetRefreshCompleted is a status of a refresh which loaded dataset data successfully.
+const DatasetRefreshCompleted = "Completed"
+
+// DatasetRefresh is an entry of dataset refresh history.
+type DatasetRefresh struct {
+	Status    string    `json:"status"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+}
+
+// DatasetRefreshesContainer holds DatasetRefresh set, the most recent first.
+type DatasetRefreshesContainer struct {
+	Value []*DatasetRefresh `json:"value"`
+}
+
+// DeserializeDatasetRefreshesContainer unmarshals DatasetRefreshesContainer from io.Reader.
+func DeserializeDatasetRefreshesContainer(b io.Reader) (*DatasetRefreshesContainer, error) {
+	out := DatasetRefreshesContainer{}
+	d := json.NewDecoder(b)
+	if err := d.Decode(&out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
@@ -20,6 +20,8 @@ var (
 	ErrEmptyBotToken = errors.New("bot Access Token is empty")
 	// ErrUnknownReportType will throw if they try to get something different from the known report types (such as report or dashboard)
 	ErrUnknownReportType = errors.New("unknown report type")
+	// ErrInvalidReportID is returned if an ID of a dashboard or a tile lacks a part of it
+	ErrInvalidReportID = errors.New("invalid report id")
 	// ErrInvalidType is returned when an unsupported concrete type is encountered during a type assertion.
 	ErrInvalidType = errors.New("invalid type")
 	// ErrNotUpdated is returned due to an unsuccessful update operation.
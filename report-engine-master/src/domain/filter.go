@@ -2,12 +2,18 @@ package domain
 
 import (
 	"context"
+	"fmt"
+	"strings"
 )
 
 type filterKind string
 
-// FilterKindIn corresponds to the basic "in" filter.
-const FilterKindIn filterKind = "in"
+const (
+	// FilterKindIn corresponds to the basic "in" filter.
+	FilterKindIn filterKind = "in"
+	// FilterKindTyped corresponds to a list of TypedFilter applied together.
+	FilterKindTyped filterKind = "typed"
+)
 
 // Filter is a report filter entity.
 type Filter struct {
@@ -28,3 +34,117 @@ type FilterRepository interface {
 	Store(ctx context.Context, f *Filter) error
 	Update(ctx context.Context, f *Filter, oldName string) error
 }
+
+// TypedFilterKind is a kind of TypedFilter; each of them maps to a filter schema of powerbi-models.
+type TypedFilterKind string
+
+const (
+	// TypedFilterBasic keeps rows w/ a value of a column in (or not in) Values.
+	TypedFilterBasic TypedFilterKind = "basic"
+	// TypedFilterAdvanced keeps rows matching one or two Conditions.
+	TypedFilterAdvanced TypedFilterKind = "advanced"
+	// TypedFilterRelativeDate keeps rows w/ a date in the last, this or next TimeUnitsCount of TimeUnit relative to today.
+	TypedFilterRelativeDate TypedFilterKind = "relativeDate"
+	// TypedFilterRelativeTime is TypedFilterRelativeDate for minutes & hours relative to now.
+	TypedFilterRelativeTime TypedFilterKind = "relativeTime"
+	// TypedFilterTopN keeps ItemCount top (or bottom) items of a column ordered by OrderBy.
+	TypedFilterTopN TypedFilterKind = "topN"
+	// TypedFilterTuple keeps rows w/ values of Targets matching one of Tuples.
+	TypedFilterTuple TypedFilterKind = "tuple"
+)
+
+// FilterTarget is a column (or a measure) of a table a filter is applied to.
+type FilterTarget struct {
+	Table   string `json:"table"`
+	Column  string `json:"column,omitempty"`
+	Measure string `json:"measure,omitempty"`
+}
+
+func (t *FilterTarget) String() string {
+	if t.Measure != "" {
+		return fmt.Sprintf("%v.%v", t.Table, t.Measure)
+	}
+
+	return fmt.Sprintf("%v.%v", t.Table, t.Column)
+}
+
+// FilterCondition is a condition of TypedFilterAdvanced.
+type FilterCondition struct {
+	Operator string `json:"operator"`
+	Value    string `json:"value,omitempty"`
+}
+
+// TypedFilter is a report filter of Kind; fields which don't belong to Kind are ignored.
+type TypedFilter struct {
+	Kind   TypedFilterKind `json:"kind"`
+	Target *FilterTarget   `json:"target,omitempty"`
+	// Operator is "In" or "NotIn" for TypedFilterBasic, "InLast", "InThis" or "InNext" for relative ones & "Top" or "Bottom" for TypedFilterTopN.
+	Operator        string             `json:"operator,omitempty"`
+	Values          []string           `json:"values,omitempty"`
+	LogicalOperator string             `json:"logicalOperator,omitempty"`
+	Conditions      []*FilterCondition `json:"conditions,omitempty"`
+	TimeUnitsCount  int                `json:"timeUnitsCount,omitempty"`
+	// TimeUnit is e.g. "Days" or "CalendarMonths" for TypedFilterRelativeDate & "Minutes" or "Hours" for TypedFilterRelativeTime.
+	TimeUnit     string        `json:"timeUnit,omitempty"`
+	IncludeToday bool          `json:"includeToday,omitempty"`
+	ItemCount    int           `json:"itemCount,omitempty"`
+	OrderBy      *FilterTarget `json:"orderBy,omitempty"`
+	// Targets are columns of TypedFilterTuple; each of Tuples holds a value for each of them.
+	Targets []*FilterTarget `json:"targets,omitempty"`
+	Tuples  [][]string      `json:"tuples,omitempty"`
+}
+
+func (f *TypedFilter) String() string {
+	switch f.Kind {
+	case TypedFilterBasic:
+		if f.Operator == "NotIn" {
+			return fmt.Sprintf("%v is not %v", f.Target, strings.Join(f.Values, ", "))
+		}
+
+		return fmt.Sprintf("%v is %v", f.Target, strings.Join(f.Values, ", "))
+
+	case TypedFilterAdvanced:
+		cs := []string(nil)
+		for _, c := range f.Conditions {
+			cs = append(cs, fmt.Sprintf("%v %v", c.Operator, c.Value))
+		}
+
+		return fmt.Sprintf("%v %v", f.Target, strings.Join(cs, fmt.Sprintf(" %v ", f.LogicalOperator)))
+
+	case TypedFilterRelativeDate, TypedFilterRelativeTime:
+		s := fmt.Sprintf("%v %v %v %v", f.Target, f.Operator, f.TimeUnitsCount, f.TimeUnit)
+		if f.IncludeToday {
+			s += " incl. today"
+		}
+
+		return s
+
+	case TypedFilterTopN:
+		return fmt.Sprintf("%v %v %v by %v", f.Operator, f.ItemCount, f.Target, f.OrderBy)
+
+	case TypedFilterTuple:
+		ts := []string(nil)
+		for _, t := range f.Targets {
+			ts = append(ts, t.String())
+		}
+
+		vs := []string(nil)
+		for _, t := range f.Tuples {
+			vs = append(vs, fmt.Sprintf("(%v)", strings.Join(t, ", ")))
+		}
+
+		return fmt.Sprintf("(%v) is %v", strings.Join(ts, ", "), strings.Join(vs, ", "))
+	}
+
+	return string(f.Kind)
+}
+
+// DescribeFilters joins descriptions of fs, e.g. for a title of a report posted.
+func DescribeFilters(fs []*TypedFilter) string {
+	ss := []string(nil)
+	for _, f := range fs {
+		ss = append(ss, f.String())
+	}
+
+	return strings.Join(ss, "; ")
+}
@@ -26,6 +26,18 @@ type PostReportTask struct {
 	IsActive     bool
 	ChannelName  string
 	RetryAttempt int
+	// VisualName is a title of a single visual to post instead of whole pages; it's empty for whole pages.
+	VisualName string
+	// DataFormat is a format data of visuals is attached in along w/ pages; it's empty if data isn't attached.
+	DataFormat string
+	// BookmarkName is a name of a report bookmark applied before each page is rendered; it's empty if no bookmark is applied.
+	BookmarkName string
+	// Filters are applied together to each page posted.
+	Filters []*TypedFilter
+	// Layout is a layout pages are rendered in; it's empty for the master one.
+	Layout string
+	// OutputFormat is a format pages are posted in; it's empty for an image of each page.
+	OutputFormat string
 }
 
 // PostReportTaskRepository is a repository of PostReportTask entities.
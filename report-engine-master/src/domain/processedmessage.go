@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ProcessedMessageOutcome tells how handling of a message ended.
+type ProcessedMessageOutcome string
+
+const (
+	// ProcessedMessageInProgress denotes a message being handled right now.
+	ProcessedMessageInProgress ProcessedMessageOutcome = "inProgress"
+	// ProcessedMessageSucceeded denotes a message handled successfully.
+	ProcessedMessageSucceeded ProcessedMessageOutcome = "succeeded"
+	// ProcessedMessageFailed denotes a message which couldn't be handled.
+	ProcessedMessageFailed ProcessedMessageOutcome = "failed"
+)
+
+// ProcessedMessage is a record of message handling, kept until ExpiresAt to detect duplicate deliveries.
+type ProcessedMessage struct {
+	ID        string
+	Outcome   ProcessedMessageOutcome
+	ExpiresAt time.Time
+}
+
+// ProcessedMessageRepository is a dedupe store of ProcessedMessage entities.
+type ProcessedMessageRepository interface {
+	// Claim marks a message as ProcessedMessageInProgress for ttl; if there's an unexpired record already, it's returned along w/ ErrConflict.
+	Claim(ctx context.Context, id string, ttl time.Duration) (*ProcessedMessage, error)
+	// Complete records an outcome of a claimed message, keeping it for ttl.
+	Complete(ctx context.Context, id string, o ProcessedMessageOutcome, ttl time.Duration) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
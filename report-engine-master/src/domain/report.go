@@ -2,21 +2,130 @@ package domain
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"strings"
 )
 
 // ReportType is enum for report types like a report or a dashboard
 type ReportType int
 
 const (
-	report ReportType = iota
+	// ReportTypeReport is a report of pages
+	ReportTypeReport ReportType = iota
+	// ReportTypeDashboard is a dashboard, rendered as a single page
+	ReportTypeDashboard
+	// ReportTypeTile is a single tile of a dashboard, rendered as a single page
+	ReportTypeTile
+	// ReportTypePaginated is a paginated (RDL) report, exported to a file rather than rendered
+	ReportTypePaginated
 )
 
+// NOTE: Dashboards, tiles & paginated reports are shared & scheduled the same way as reports, so their IDs are prefixed w/ a kind to tell them from report ones.
+const (
+	dashboardIDPrefix = "dashboard:"
+	tileIDPrefix      = "tile:"
+	tileIDSeparator   = ":"
+	paginatedIDPrefix = "paginated:"
+)
+
+// paginatedReportKind is Report.Kind of a paginated report.
+const paginatedReportKind = "PaginatedReport"
+
+func (t ReportType) String() string {
+	switch t {
+	case ReportTypeDashboard:
+		return "Dashboard"
+
+	case ReportTypeTile:
+		return "Tile"
+
+	case ReportTypePaginated:
+		return "Paginated report"
+	}
+
+	return "Report"
+}
+
 // IReport describes all report kinds
 type IReport interface {
 	GetName() string
 	GetID() string
 	GetWebURL() string
+	GetType() ReportType
+}
+
+// ReportRef identifies a report of any kind by an ID returned by IReport.GetID
+type ReportRef struct {
+	Type ReportType
+	// ID is an ID of a report, a paginated report, a dashboard or a tile as Power BI knows it.
+	ID string
+	// DashboardID is set for ReportTypeTile only.
+	DashboardID string
+}
+
+// ParseReportID parses an ID returned by IReport.GetID; an ID w/o a kind prefix is a report one
+func ParseReportID(id string) (*ReportRef, error) {
+	switch {
+	case strings.HasPrefix(id, dashboardIDPrefix):
+		ref := ReportRef{
+			Type: ReportTypeDashboard,
+			ID:   strings.TrimPrefix(id, dashboardIDPrefix),
+		}
+		if ref.ID == "" {
+			return nil, ErrInvalidReportID
+		}
+
+		return &ref, nil
+
+	case strings.HasPrefix(id, paginatedIDPrefix):
+		ref := ReportRef{
+			Type: ReportTypePaginated,
+			ID:   strings.TrimPrefix(id, paginatedIDPrefix),
+		}
+		if ref.ID == "" {
+			return nil, ErrInvalidReportID
+		}
+
+		return &ref, nil
+
+	case strings.HasPrefix(id, tileIDPrefix):
+		ids := strings.SplitN(strings.TrimPrefix(id, tileIDPrefix), tileIDSeparator, 2)
+		if len(ids) != 2 || ids[0] == "" || ids[1] == "" {
+			return nil, ErrInvalidReportID
+		}
+
+		ref := ReportRef{
+			Type:        ReportTypeTile,
+			ID:          ids[1],
+			DashboardID: ids[0],
+		}
+
+		return &ref, nil
+	}
+
+	ref := ReportRef{
+		Type: ReportTypeReport,
+		ID:   id,
+	}
+
+	return &ref, nil
+}
+
+// Dashboard represents a single dashboard
+type Dashboard struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	WebURL      string `json:"webUrl"`
+}
+
+// Tile represents a single tile of a dashboard
+type Tile struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	DatasetID string `json:"datasetId"`
+	// Dashboard is the one a tile is pinned to; Power BI doesn't return it along w/ a tile.
+	Dashboard *Dashboard `json:"-"`
 }
 
 // Report represents a single report
@@ -24,6 +133,10 @@ type Report struct {
 	ID     string `json:"id"`
 	Name   string `json:"name"`
 	WebURL string `json:"webUrl"`
+	// Kind is either "PowerBIReport" or "PaginatedReport".
+	Kind string `json:"reportType"`
+	// DatasetID identifies a dataset the report is built on.
+	DatasetID string `json:"datasetId"`
 }
 
 // Groups contains multiple groups (workspaces).
@@ -45,8 +158,12 @@ func NewGroupedReports() map[*Group]*ReportsContainer {
 	return make(map[*Group]*ReportsContainer)
 }
 
-// GetID method of IReport interface returns Report ID
+// GetID method of IReport interface returns Report ID; an ID of a paginated report is prefixed w/ a kind
 func (r *Report) GetID() string {
+	if r.Kind == paginatedReportKind {
+		return paginatedIDPrefix + r.ID
+	}
+
 	return r.ID
 }
 
@@ -60,6 +177,61 @@ func (r *Report) GetWebURL() string {
 	return r.WebURL
 }
 
+// GetType method of IReport interface returns ReportTypeReport or ReportTypePaginated
+func (r *Report) GetType() ReportType {
+	if r.Kind == paginatedReportKind {
+		return ReportTypePaginated
+	}
+
+	return ReportTypeReport
+}
+
+// GetID method of IReport interface returns Dashboard ID prefixed w/ a kind
+func (d *Dashboard) GetID() string {
+	return dashboardIDPrefix + d.ID
+}
+
+// GetName method of IReport interface returns Dashboard Name
+func (d *Dashboard) GetName() string {
+	return d.DisplayName
+}
+
+// GetWebURL returns dashboard URL.
+func (d *Dashboard) GetWebURL() string {
+	return d.WebURL
+}
+
+// GetType method of IReport interface returns ReportTypeDashboard
+func (d *Dashboard) GetType() ReportType {
+	return ReportTypeDashboard
+}
+
+// GetID method of IReport interface returns Tile ID prefixed w/ a kind & an ID of its dashboard
+func (t *Tile) GetID() string {
+	return tileIDPrefix + t.Dashboard.ID + tileIDSeparator + t.ID
+}
+
+// GetName method of IReport interface returns Tile Title along w/ a name of its dashboard
+// NOTE: Tiles of images & text boxes have no title, so they're named by a kind.
+func (t *Tile) GetName() string {
+	title := t.Title
+	if title == "" {
+		title = ReportTypeTile.String()
+	}
+
+	return fmt.Sprintf("%v / %v", t.Dashboard.DisplayName, title)
+}
+
+// GetWebURL returns URL of a dashboard of a tile, since a tile has no URL of its own.
+func (t *Tile) GetWebURL() string {
+	return t.Dashboard.WebURL
+}
+
+// GetType method of IReport interface returns ReportTypeTile
+func (t *Tile) GetType() ReportType {
+	return ReportTypeTile
+}
+
 // DeserializeGroups unmarhals json
 func DeserializeGroups(b io.ReadCloser) (*Groups, error) {
 	out := Groups{}
@@ -73,7 +245,19 @@ func DeserializeGroups(b io.ReadCloser) (*Groups, error) {
 
 // DeserializeReports unmarhals json
 func DeserializeReports(b io.ReadCloser) (*ReportsContainer, error) {
-	out := newReportsContainer(report)
+	out := newReportsContainer(ReportTypeReport)
+	d := json.NewDecoder(b)
+
+	if err := d.Decode(out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// DeserializeDashboards unmarhals json
+func DeserializeDashboards(b io.ReadCloser) (*ReportsContainer, error) {
+	out := newReportsContainer(ReportTypeDashboard)
 	d := json.NewDecoder(b)
 
 	if err := d.Decode(out); err != nil {
@@ -83,6 +267,48 @@ func DeserializeReports(b io.ReadCloser) (*ReportsContainer, error) {
 	return out, nil
 }
 
+// DeserializeTiles unmarhals json; tiles get dashboard d set.
+func DeserializeTiles(b io.ReadCloser, d *Dashboard) (*ReportsContainer, error) {
+	out := newReportsContainer(ReportTypeTile)
+	dec := json.NewDecoder(b)
+
+	if err := dec.Decode(out); err != nil {
+		return nil, err
+	}
+
+	for _, r := range out.Value {
+		r.(*Tile).Dashboard = d
+	}
+
+	return out, nil
+}
+
+// DeserializeDashboard unmarhals json to Dashboard type
+func DeserializeDashboard(b io.ReadCloser) (*Dashboard, error) {
+	out := Dashboard{}
+	d := json.NewDecoder(b)
+
+	if err := d.Decode(&out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// DeserializeTile unmarhals json to Tile type; the tile gets dashboard d set.
+func DeserializeTile(b io.ReadCloser, d *Dashboard) (*Tile, error) {
+	out := Tile{
+		Dashboard: d,
+	}
+	dec := json.NewDecoder(b)
+
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
 // DeserializeReport unmarhals json to Report type
 func DeserializeReport(b io.ReadCloser) (*Report, error) {
 	out := Report{}
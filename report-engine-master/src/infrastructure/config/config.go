@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -94,12 +95,17 @@ type BaseConfig struct {
 	MessageQueue         *MessageQueueConfig
 	MessageHandler       *MessageHandlerConfig
 	AWS                  *AWSConfig
-	MaxAttempts          int
+	RetryStrategy        *RetryStrategyConfig
+	Dedupe               *DedupeConfig
+	MessageSecrets       *MessageSecretsConfig
 }
 
 // ReportEngineConfig controls cmd/reportengine behavior.
 type ReportEngineConfig struct {
 	*BaseConfig
+	ImageCache *ImageCacheConfig
+	Image      *ImageConfig
+	Export     *ExportConfig
 }
 
 // SlackConfig controls interaction w/ Slack.
@@ -214,8 +220,46 @@ type BrowserConfig struct {
 	DefaultViewportWidth  int64         `envconfig:"BROWSER_DEFAULTVIEWPORTWIDTH"`
 	ViewportMargin        int64         `envconfig:"BROWSER_VIEWPORTMARGIN"`
 	DisplayDensity        float64       `envconfig:"BROWSER_DISPLAYDENSITY"`
-	ResourcesDirectory    string        `envconfig:"BROWSER_RESOURCESDIRECTORY"`
-	ScreenshotDelay       time.Duration `envconfig:"BROWSER_SCREENSHOTDELAY"`
+	// MobileViewportWidth & MobileViewportHeight are the size of a phone held upright, a mobile layout of a page is rendered at; they're swapped for a landscape one.
+	MobileViewportWidth  int64         `envconfig:"BROWSER_MOBILEVIEWPORTWIDTH"`
+	MobileViewportHeight int64         `envconfig:"BROWSER_MOBILEVIEWPORTHEIGHT"`
+	ResourcesDirectory   string        `envconfig:"BROWSER_RESOURCESDIRECTORY"`
+	ScreenshotDelay      time.Duration `envconfig:"BROWSER_SCREENSHOTDELAY"`
+	// PoolSize is the number of tabs kept w/ the report template loaded, so a report is rendered w/o loading it again.
+	PoolSize int `envconfig:"BROWSER_POOLSIZE"`
+	// MaxTabs limits the number of open tabs; a report waits for a free tab beyond it.
+	MaxTabs int `envconfig:"BROWSER_MAXTABS"`
+	// TabMaxRenders is the number of reports rendered in a tab before it's replaced w/ a new one.
+	TabMaxRenders       int           `envconfig:"BROWSER_TABMAXRENDERS"`
+	HealthCheckInterval time.Duration `envconfig:"BROWSER_HEALTHCHECKINTERVAL"`
+	// Readiness controls how a page is considered fully drawn after its render event.
+	Readiness ReadinessStrategy `envconfig:"BROWSER_READINESS"`
+	// ReadinessTimeout limits waiting for ReadinessSignals; a page is captured as is beyond it.
+	ReadinessTimeout time.Duration `envconfig:"BROWSER_READINESSTIMEOUT"`
+	// NetworkIdleTime is how long a tab must have no pending requests to be considered idle.
+	NetworkIdleTime time.Duration `envconfig:"BROWSER_NETWORKIDLETIME"`
+	// DOMStableTime is how long a page must have no DOM mutations to be considered stable.
+	DOMStableTime time.Duration `envconfig:"BROWSER_DOMSTABLETIME"`
+}
+
+// ReadinessStrategy controls how a page is considered fully drawn.
+type ReadinessStrategy string
+
+const (
+	// ReadinessSignals waits for network idle & DOM stability.
+	ReadinessSignals ReadinessStrategy = "signals"
+	// ReadinessDelay waits for BrowserConfig.ScreenshotDelay.
+	ReadinessDelay ReadinessStrategy = "delay"
+)
+
+func parseReadinessStrategy(s string) (ReadinessStrategy, error) {
+	switch ReadinessStrategy(s) {
+	case ReadinessSignals, ReadinessDelay:
+		return ReadinessStrategy(s), nil
+
+	default:
+		return "", fmt.Errorf("unknown readiness strategy: %v", s)
+	}
 }
 
 func newBrowserConfig(p Provider) (*BrowserConfig, error) {
@@ -236,6 +280,48 @@ func newBrowserConfig(p Provider) (*BrowserConfig, error) {
 		return nil, err
 	}
 
+	healthCheckInterval, err := time.ParseDuration(p.Get(prefix+"_HEALTHCHECKINTERVAL", "1m"))
+	if err != nil {
+		return nil, err
+	}
+
+	if healthCheckInterval <= 0 {
+		return nil, fmt.Errorf("health check interval must be positive")
+	}
+
+	poolSize := getInt(p, prefix+"_POOLSIZE", 2)
+	if poolSize < 0 {
+		return nil, fmt.Errorf("pool size must not be negative")
+	}
+
+	maxTabs := getInt(p, prefix+"_MAXTABS", 8)
+	if maxTabs <= 0 || maxTabs < poolSize {
+		return nil, fmt.Errorf("max tabs must be positive & not less than pool size")
+	}
+
+	tabMaxRenders := getInt(p, prefix+"_TABMAXRENDERS", 50)
+	if tabMaxRenders <= 0 {
+		return nil, fmt.Errorf("max renders per tab must be positive")
+	}
+
+	readiness, err := parseReadinessStrategy(p.Get(prefix+"_READINESS", string(ReadinessSignals)))
+	if err != nil {
+		return nil, err
+	}
+
+	readinessTimeout := getDuration(p, prefix+"_READINESSTIMEOUT", 10*time.Second)
+	networkIdleTime := getDuration(p, prefix+"_NETWORKIDLETIME", 500*time.Millisecond)
+	domStableTime := getDuration(p, prefix+"_DOMSTABLETIME", 500*time.Millisecond)
+	if readinessTimeout <= 0 || networkIdleTime <= 0 || domStableTime <= 0 {
+		return nil, fmt.Errorf("readiness timeout, network idle time & DOM stable time must be positive")
+	}
+
+	mobileViewportWidth := getInt64(p, prefix+"_MOBILEVIEWPORTWIDTH", 414)
+	mobileViewportHeight := getInt64(p, prefix+"_MOBILEVIEWPORTHEIGHT", 896)
+	if mobileViewportWidth <= 0 || mobileViewportHeight <= 0 {
+		return nil, fmt.Errorf("mobile viewport size must be positive")
+	}
+
 	return &BrowserConfig{
 		Headless:              getBool(p, prefix+"_HEADLESS", true),
 		RedirectLog:           getBool(p, prefix+"_REDIRECTLOG", false),
@@ -245,8 +331,18 @@ func newBrowserConfig(p Provider) (*BrowserConfig, error) {
 		DefaultViewportWidth:  getInt64(p, prefix+"_DEFAULTVIEWPORTWIDTH", 1280),
 		ViewportMargin:        getInt64(p, prefix+"_VIEWPORTMARGIN", 64),
 		DisplayDensity:        getFloat64(p, prefix+"_DISPLAYDENSITY", 1.0),
+		MobileViewportWidth:   mobileViewportWidth,
+		MobileViewportHeight:  mobileViewportHeight,
 		ResourcesDirectory:    p.Get(prefix+"_RESOURCESDIRECTORY", "resources"),
 		ScreenshotDelay:       screenshotDelay,
+		PoolSize:              poolSize,
+		MaxTabs:               maxTabs,
+		TabMaxRenders:         tabMaxRenders,
+		HealthCheckInterval:   healthCheckInterval,
+		Readiness:             readiness,
+		ReadinessTimeout:      readinessTimeout,
+		NetworkIdleTime:       networkIdleTime,
+		DOMStableTime:         domStableTime,
 	}, nil
 }
 
@@ -296,11 +392,13 @@ const (
 	MQInProcess MessageQueueImplementation = "inProcess"
 	// MQSQS enables SQS implementation.
 	MQSQS MessageQueueImplementation = "sqs"
+	// MQMySQL enables MySQL implementation.
+	MQMySQL MessageQueueImplementation = "mysql"
 )
 
 func parseImplementation(s string) (MessageQueueImplementation, error) {
 	switch MessageQueueImplementation(s) {
-	case MQInProcess, MQSQS:
+	case MQInProcess, MQSQS, MQMySQL:
 		return MessageQueueImplementation(s), nil
 
 	default:
@@ -310,10 +408,15 @@ func parseImplementation(s string) (MessageQueueImplementation, error) {
 
 // MessageQueueConfig controls MQ behavior.
 type MessageQueueConfig struct {
-	Implementation  MessageQueueImplementation `envconfig:"MQ_IMPLEMENTATION"`
-	URL             string                     `envconfig:"MQ_URL"`
-	BatchSize       uint                       `envconfig:"MQ_BATCHSIZE"`
-	PollingInterval time.Duration              `envconfig:"MQ_POLLINGINTERVAL"`
+	Implementation    MessageQueueImplementation `envconfig:"MQ_IMPLEMENTATION"`
+	URL               string                     `envconfig:"MQ_URL"`
+	ScheduledURL      string                     `envconfig:"MQ_SCHEDULEDURL"`
+	DeadLetterURL     string                     `envconfig:"MQ_DEADLETTERURL"`
+	DelayURL          string                     `envconfig:"MQ_DELAYURL"`
+	BatchSize         uint                       `envconfig:"MQ_BATCHSIZE"`
+	PollingInterval   time.Duration              `envconfig:"MQ_POLLINGINTERVAL"`
+	VisibilityTimeout time.Duration              `envconfig:"MQ_VISIBILITYTIMEOUT"`
+	InteractiveRatio  uint                       `envconfig:"MQ_INTERACTIVERATIO"`
 }
 
 func newMessageQueueConfig(p Provider) (*MessageQueueConfig, error) {
@@ -328,17 +431,62 @@ func newMessageQueueConfig(p Provider) (*MessageQueueConfig, error) {
 		return nil, err
 	}
 
+	t := getDuration(p, prefix+"_VISIBILITYTIMEOUT", 30*time.Minute)
+	if t <= 0 {
+		return nil, fmt.Errorf("visibility timeout must be positive")
+	}
+
+	// NOTE: For MySQL implementation, URLs are queue names within a single table.
+	u := p.Get(prefix+"_URL", "")
+	su := p.Get(prefix+"_SCHEDULEDURL", "")
+	d := p.Get(prefix+"_DEADLETTERURL", "")
+	// NOTE: Delayed messages wait in a standard SQS queue, since FIFO ones don't support per-message delays.
+	dl := p.Get(prefix+"_DELAYURL", "")
+	if i == MQMySQL {
+		if u == "" {
+			u = "default"
+		}
+
+		if su == "" {
+			su = "scheduled"
+		}
+
+		if d == "" {
+			d = "deadLetter"
+		}
+	}
+
+	// NOTE: A batch of 0 messages would never receive anything.
+	b := getUint(p, prefix+"_BATCHSIZE", 8)
+	if b == 0 {
+		return nil, fmt.Errorf("batch size must be positive")
+	}
+
 	return &MessageQueueConfig{
-		Implementation:  i,
-		URL:             p.Get(prefix+"_URL", ""),
-		BatchSize:       getUint(p, prefix+"BATCHSIZE", 8),
-		PollingInterval: getDuration(p, prefix+"POLLINGINTERVAL", 20*time.Second),
+		Implementation:    i,
+		URL:               u,
+		ScheduledURL:      su,
+		DeadLetterURL:     d,
+		DelayURL:          dl,
+		BatchSize:         b,
+		PollingInterval:   getDuration(p, prefix+"_POLLINGINTERVAL", 20*time.Second),
+		VisibilityTimeout: t,
+		InteractiveRatio:  getUint(p, prefix+"_INTERACTIVERATIO", 4),
 	}, nil
 }
 
 // MessageHandlerConfig controls message handler behavior.
 type MessageHandlerConfig struct {
 	ConcurrencyLevel uint `envconfig:"MESSAGEHANDLER_CONCURRENCYLEVEL"`
+	// KindConcurrencyLevels overrides ConcurrencyLevel for specific message kinds.
+	KindConcurrencyLevels map[string]uint `envconfig:"MESSAGEHANDLER_KINDCONCURRENCYLEVELS"`
+	// MaxInFlight limits the number of received messages not handled yet, across all message kinds.
+	MaxInFlight         uint          `envconfig:"MESSAGEHANDLER_MAXINFLIGHT"`
+	MaxReceiveCount     int           `envconfig:"MESSAGEHANDLER_MAXRECEIVECOUNT"`
+	VisibilityExtension time.Duration `envconfig:"MESSAGEHANDLER_VISIBILITYEXTENSION"`
+	RetryDelay          time.Duration `envconfig:"MESSAGEHANDLER_RETRYDELAY"`
+	// BusyDelay postpones a message of a kind whose workers & backlog are full, so it doesn't hold messages of other kinds.
+	BusyDelay time.Duration `envconfig:"MESSAGEHANDLER_BUSYDELAY"`
 }
 
 func newMessageHandlerConfig(p Provider) (*MessageHandlerConfig, error) {
@@ -349,8 +497,311 @@ func newMessageHandlerConfig(p Provider) (*MessageHandlerConfig, error) {
 		return nil, fmt.Errorf("concurrency level must be set")
 	}
 
+	kl := map[string]uint(nil)
+	err := json.Unmarshal([]byte(p.Get(prefix+"_KINDCONCURRENCYLEVELS", `{}`)), &kl)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid message kind concurrency levels")
+	}
+
+	for k, n := range kl {
+		if n == 0 {
+			return nil, fmt.Errorf("concurrency level of %v must be positive", k)
+		}
+	}
+
+	f := getUint(p, prefix+"_MAXINFLIGHT", l)
+	if f == 0 {
+		return nil, fmt.Errorf("max in-flight messages must be positive")
+	}
+
+	c := getInt(p, prefix+"_MAXRECEIVECOUNT", 5)
+	if c <= 0 {
+		return nil, fmt.Errorf("max receive count must be positive")
+	}
+
+	e := getDuration(p, prefix+"_VISIBILITYEXTENSION", 5*time.Minute)
+	if e <= 0 {
+		return nil, fmt.Errorf("visibility extension must be positive")
+	}
+
 	return &MessageHandlerConfig{
-		ConcurrencyLevel: l,
+		ConcurrencyLevel:      l,
+		KindConcurrencyLevels: kl,
+		MaxInFlight:           f,
+		MaxReceiveCount:       c,
+		VisibilityExtension:   e,
+		RetryDelay:            getDuration(p, prefix+"_RETRYDELAY", 30*time.Second),
+		BusyDelay:             getDuration(p, prefix+"_BUSYDELAY", 5*time.Second),
+	}, nil
+}
+
+// RetryStrategyConfig controls retries of failed report rendering.
+type RetryStrategyConfig struct {
+	MaxAttempts  int           `envconfig:"RETRYSTRATEGY_MAXATTEMPTS"`
+	InitialDelay time.Duration `envconfig:"RETRYSTRATEGY_INITIALDELAY"`
+	MaxDelay     time.Duration `envconfig:"RETRYSTRATEGY_MAXDELAY"`
+}
+
+func newRetryStrategyConfig(p Provider) (*RetryStrategyConfig, error) {
+	const prefix = "RETRYSTRATEGY"
+
+	a := getInt(p, prefix+"_MAXATTEMPTS", 3)
+	if a < 0 {
+		return nil, fmt.Errorf("the number of attempts must be greater than zero")
+	}
+
+	i := getDuration(p, prefix+"_INITIALDELAY", 10*time.Second)
+	if i <= 0 {
+		return nil, fmt.Errorf("initial retry delay must be positive")
+	}
+
+	m := getDuration(p, prefix+"_MAXDELAY", 10*time.Minute)
+	if m < i {
+		return nil, fmt.Errorf("max retry delay must be at least initial one")
+	}
+
+	return &RetryStrategyConfig{
+		MaxAttempts:  a,
+		InitialDelay: i,
+		MaxDelay:     m,
+	}, nil
+}
+
+// DedupeConfig controls detection of duplicate message deliveries.
+type DedupeConfig struct {
+	// ClaimTTL is how long a message being handled blocks its duplicates; it should exceed report rendering time.
+	ClaimTTL time.Duration `envconfig:"DEDUPE_CLAIMTTL"`
+	// TTL is how long a handled message is remembered.
+	TTL             time.Duration `envconfig:"DEDUPE_TTL"`
+	CleanupInterval time.Duration `envconfig:"DEDUPE_CLEANUPINTERVAL"`
+}
+
+func newDedupeConfig(p Provider) (*DedupeConfig, error) {
+	const prefix = "DEDUPE"
+
+	c := getDuration(p, prefix+"_CLAIMTTL", 15*time.Minute)
+	if c <= 0 {
+		return nil, fmt.Errorf("claim TTL must be positive")
+	}
+
+	t := getDuration(p, prefix+"_TTL", 24*time.Hour)
+	if t <= 0 {
+		return nil, fmt.Errorf("TTL must be positive")
+	}
+
+	i := getDuration(p, prefix+"_CLEANUPINTERVAL", time.Hour)
+	if i <= 0 {
+		return nil, fmt.Errorf("cleanup interval must be positive")
+	}
+
+	return &DedupeConfig{
+		ClaimTTL:        c,
+		TTL:             t,
+		CleanupInterval: i,
+	}, nil
+}
+
+// MessageSecretsConfig controls encryption of secrets carried in queue messages.
+type MessageSecretsConfig struct {
+	// Keys are AES keys by ID; keys retired from sealing are kept to open messages sealed before rotation.
+	Keys         map[string][]byte `envconfig:"MESSAGESECRETS_KEYS"`
+	CurrentKeyID string            `envconfig:"MESSAGESECRETS_CURRENTKEYID"`
+	// PlaintextUntil is the end of migration window, until then unsealed secrets of legacy messages are accepted.
+	PlaintextUntil time.Time `envconfig:"MESSAGESECRETS_PLAINTEXTUNTIL"`
+}
+
+func newMessageSecretsConfig(p Provider) (*MessageSecretsConfig, error) {
+	const prefix = "MESSAGESECRETS"
+
+	eks := map[string]string(nil)
+	err := json.Unmarshal([]byte(p.Get(prefix+"_KEYS", `{}`)), &eks)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid keys")
+	}
+
+	ks := map[string][]byte{}
+	for id, ek := range eks {
+		k, err := base64.StdEncoding.DecodeString(ek)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key %v", id)
+		}
+
+		ks[id] = k
+	}
+
+	id := p.Get(prefix+"_CURRENTKEYID", "")
+	_, ok := ks[id]
+	if len(ks) > 0 && !ok {
+		return nil, fmt.Errorf("current key must be one of keys")
+	}
+
+	u := time.Time{}
+	s := p.Get(prefix+"_PLAINTEXTUNTIL", "")
+	if s != "" {
+		u, err = time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid plaintext migration window")
+		}
+	}
+
+	return &MessageSecretsConfig{
+		Keys:           ks,
+		CurrentKeyID:   id,
+		PlaintextUntil: u,
+	}, nil
+}
+
+// ImageCacheConfig controls caching of rendered page images.
+type ImageCacheConfig struct {
+	Enable    bool   `envconfig:"IMAGECACHE_ENABLE"`
+	Directory string `envconfig:"IMAGECACHE_DIRECTORY"`
+	// MaxSizeMB limits the size of cached images; the least recently used ones are deleted beyond it.
+	MaxSizeMB int `envconfig:"IMAGECACHE_MAXSIZEMB"`
+	// RefreshTimeout limits looking up when a dataset was refreshed; a report is rendered w/o the cache beyond it.
+	RefreshTimeout time.Duration `envconfig:"IMAGECACHE_REFRESHTIMEOUT"`
+}
+
+func newImageCacheConfig(p Provider) (*ImageCacheConfig, error) {
+	const prefix = "IMAGECACHE"
+
+	s := getInt(p, prefix+"_MAXSIZEMB", 1024)
+	if s <= 0 {
+		return nil, fmt.Errorf("max size must be positive")
+	}
+
+	t := getDuration(p, prefix+"_REFRESHTIMEOUT", 10*time.Second)
+	if t <= 0 {
+		return nil, fmt.Errorf("refresh timeout must be positive")
+	}
+
+	return &ImageCacheConfig{
+		Enable:         getBool(p, prefix+"_ENABLE", false),
+		Directory:      p.Get(prefix+"_DIRECTORY", "imagecache"),
+		MaxSizeMB:      s,
+		RefreshTimeout: t,
+	}, nil
+}
+
+// ExportConfig controls export of paginated reports via Power BI ExportTo File API.
+type ExportConfig struct {
+	// PollInterval is how often export status is checked unless Power BI asks to wait longer.
+	PollInterval time.Duration `envconfig:"EXPORT_POLLINTERVAL"`
+	// Timeout limits export of a single report, including its download.
+	Timeout time.Duration `envconfig:"EXPORT_TIMEOUT"`
+}
+
+func newExportConfig(p Provider) (*ExportConfig, error) {
+	const prefix = "EXPORT"
+
+	i := getDuration(p, prefix+"_POLLINTERVAL", 5*time.Second)
+	if i <= 0 {
+		return nil, fmt.Errorf("poll interval must be positive")
+	}
+
+	t := getDuration(p, prefix+"_TIMEOUT", 10*time.Minute)
+	if t <= 0 {
+		return nil, fmt.Errorf("timeout must be positive")
+	}
+
+	return &ExportConfig{
+		PollInterval: i,
+		Timeout:      t,
+	}, nil
+}
+
+// ImageFormat is a format images are posted in.
+type ImageFormat string
+
+const (
+	// ImagePNG keeps images as rendered, i.e. lossless.
+	ImagePNG ImageFormat = "png"
+	// ImageJPEG converts images to lossy JPEG.
+	ImageJPEG ImageFormat = "jpeg"
+	// ImageWebP converts images to lossy WebP.
+	ImageWebP ImageFormat = "webp"
+)
+
+func parseImageFormat(s string) (ImageFormat, error) {
+	switch ImageFormat(s) {
+	case ImagePNG, ImageJPEG, ImageWebP:
+		return ImageFormat(s), nil
+
+	default:
+		return "", fmt.Errorf("unknown image format: %v", s)
+	}
+}
+
+// ImageProfileConfig controls post-processing of images posted to a destination, e.g. Slack.
+type ImageProfileConfig struct {
+	Format ImageFormat
+	// Quality is used for lossy formats, 1 to 100.
+	Quality int
+	// MaxWidth & MaxHeight limit image dimensions; 0 means no limit. Images are downscaled to fit them keeping aspect ratio.
+	MaxWidth  int
+	MaxHeight int
+	// MaxSizeKB limits image size; 0 means no limit. Quality is lowered first, then an image is downscaled till it fits.
+	MaxSizeKB int
+}
+
+func newImageProfileConfig(p Provider, prefix string, f ImageFormat, maxSizeKB int) (*ImageProfileConfig, error) {
+	v, err := parseImageFormat(p.Get(prefix+"_FORMAT", string(f)))
+	if err != nil {
+		return nil, err
+	}
+
+	q := getInt(p, prefix+"_QUALITY", 85)
+	if q < 1 || q > 100 {
+		return nil, fmt.Errorf("quality must be within 1 to 100")
+	}
+
+	w := getInt(p, prefix+"_MAXWIDTH", 0)
+	h := getInt(p, prefix+"_MAXHEIGHT", 0)
+	if w < 0 || h < 0 {
+		return nil, fmt.Errorf("max dimensions must not be negative")
+	}
+
+	s := getInt(p, prefix+"_MAXSIZEKB", maxSizeKB)
+	if s < 0 {
+		return nil, fmt.Errorf("max size must not be negative")
+	}
+
+	return &ImageProfileConfig{
+		Format:    v,
+		Quality:   q,
+		MaxWidth:  w,
+		MaxHeight: h,
+		MaxSizeKB: s,
+	}, nil
+}
+
+// ImageConfig controls post-processing of rendered page images; cached images & PDF documents are kept as rendered.
+type ImageConfig struct {
+	// OptimizePNG recompresses PNG images w/ the best compression; it's lossless, but takes time.
+	OptimizePNG bool `envconfig:"IMAGE_OPTIMIZEPNG"`
+	// Profiles are keyed by client ID.
+	Profiles map[string]*ImageProfileConfig
+}
+
+func newImageConfig(p Provider) (*ImageConfig, error) {
+	const prefix = "IMAGE"
+
+	s, err := newImageProfileConfig(p, prefix+"_SLACK", ImagePNG, 0)
+	if err != nil {
+		return nil, fmt.Errorf("slack: %w", err)
+	}
+
+	// NOTE: Graph API limits a message to 4 MB, while an image is base64 encoded in it.
+	t, err := newImageProfileConfig(p, prefix+"_TEAMS", ImagePNG, 2900)
+	if err != nil {
+		return nil, fmt.Errorf("teams: %w", err)
+	}
+
+	return &ImageConfig{
+		OptimizePNG: getBool(p, prefix+"_OPTIMIZEPNG", false),
+		Profiles: map[string]*ImageProfileConfig{
+			"slack": s,
+			"teams": t,
+		},
 	}, nil
 }
 
@@ -499,11 +950,27 @@ func NewBaseConfig(p Provider) (*BaseConfig, error) {
 
 	c.AWS = a
 
-	c.MaxAttempts = getInt(p, "RETRYSTRATEGY_MAXATTEMPTS", 3)
-	if c.MaxAttempts < 0 {
-		return nil, fmt.Errorf("the number of attempts must be greater than zero")
+	r, err := newRetryStrategyConfig(p)
+	if err != nil {
+		return nil, err
+	}
+
+	c.RetryStrategy = r
+
+	dd, err := newDedupeConfig(p)
+	if err != nil {
+		return nil, err
 	}
 
+	c.Dedupe = dd
+
+	ms, err := newMessageSecretsConfig(p)
+	if err != nil {
+		return nil, err
+	}
+
+	c.MessageSecrets = ms
+
 	return &c, nil
 }
 
@@ -514,8 +981,26 @@ func NewReportEngineConfig(p Provider) (*ReportEngineConfig, error) {
 		return nil, err
 	}
 
+	ic, err := newImageCacheConfig(p)
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := newImageConfig(p)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := newExportConfig(p)
+	if err != nil {
+		return nil, err
+	}
+
 	c := ReportEngineConfig{
 		BaseConfig: base,
+		ImageCache: ic,
+		Image:      i,
+		Export:     e,
 	}
 
 	return &c, nil
@@ -0,0 +1,296 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+
+)
+
+type distributeReportWorker struct {
+	reportUsecase     usecases.ReportUsecase
+	userUsecase       usecases.UserUsecase
+	workspaceUsecase  usecases.WorkspaceUsecase
+	processedMessages domain.ProcessedMessageRepository
+	dedupeConfig      *config.DedupeConfig
+	keyRing           *messagequeue.KeyRing
+	secretsConfig     *config.MessageSecretsConfig
+	logger            *zap.Logger
+}
+
+// NewDistributeReportWorker creates a Worker rendering a report once & posting it to many targets.
+// Delivery to each target is deduplicated separately by DeliveryTargetMessage.UniqueID, so a redelivered message is posted only to targets which didn't get it yet.
+func NewDistributeReportWorker(
+	r usecases.ReportUsecase,
+	u usecases.UserUsecase,
+	w usecases.WorkspaceUsecase,
+	pm domain.ProcessedMessageRepository,
+	c *config.DedupeConfig,
+	k *messagequeue.KeyRing,
+	sc *config.MessageSecretsConfig,
+	l *zap.Logger,
+) Worker {
+	return &distributeReportWorker{
+		reportUsecase:     r,
+		userUsecase:       u,
+		workspaceUsecase:  w,
+		processedMessages: pm,
+		dedupeConfig:      c,
+		keyRing:           k,
+		secretsConfig:     sc,
+		logger:            l,
+	}
+}
+
+func (w *distributeReportWorker) SupportedMessages() []messagequeue.MessageKind {
+	return []messagequeue.MessageKind{
+		messagequeue.MessageDistributeReport,
+	}
+}
+
+func (w *distributeReportWorker) Handle(ctx context.Context, e *messagequeue.Envelope) error {
+	l := utils.WithContext(ctx, w.logger)
+
+	p, err := e.Unpack(func(j json.RawMessage) (interface{}, error) {
+		p := messagequeue.DistributeReportMessage{}
+		err := json.Unmarshal(j, &p)
+		if err != nil {
+			return nil, err
+		}
+
+		return &p, nil
+	})
+	if err != nil {
+		l.Error("couldn't unpack envelope body", zap.Error(err))
+
+		return err
+	}
+
+	m := p.(*messagequeue.DistributeReportMessage)
+	l = l.With(zap.String("uniqueID", m.UniqueID), zap.Int("retryAttempt", m.RetryAttempt))
+
+	t, err := m.OpenTokens(w.keyRing, time.Now().Before(w.secretsConfig.PlaintextUntil))
+	if err != nil {
+		l.Error("couldn't open message tokens", zap.Error(err))
+
+		return err
+	}
+
+	claimed := []*messagequeue.DeliveryTargetMessage(nil)
+	ids := []string(nil)
+	inProgress := false
+	inProgressUntil := time.Time{}
+	for _, dt := range m.Targets {
+		id := fmt.Sprintf("%v/%v", dt.UniqueID, m.RetryAttempt)
+		pm, err := w.processedMessages.Claim(ctx, id, w.dedupeConfig.ClaimTTL)
+		if err == domain.ErrConflict {
+			if pm.Outcome == domain.ProcessedMessageInProgress {
+				inProgress = true
+				if pm.ExpiresAt.After(inProgressUntil) {
+					inProgressUntil = pm.ExpiresAt
+				}
+			} else {
+				l.Info("skipped duplicate delivery", zap.String("channelID", dt.ChannelID), zap.String("outcome", string(pm.Outcome)))
+			}
+
+			continue
+		}
+
+		if err != nil {
+			l.Error("couldn't claim delivery", zap.Error(err))
+
+			w.complete(ctx, ids, make([]error, len(ids)), err)
+
+			return err
+		}
+
+		claimed = append(claimed, dt)
+		ids = append(ids, id)
+	}
+
+	if len(claimed) == 0 {
+		if inProgress {
+			l.Warn("duplicate message is being handled already")
+
+			return newDuplicateInProgressError(inProgressUntil)
+		}
+
+		return nil
+	}
+
+	// NOTE: A retry is pushed w/ claimed targets only, so targets which got the report already don't get it again.
+	m.Targets = claimed
+	errs := w.distribute(ctx, m, t)
+	err = w.complete(ctx, ids, errs, nil)
+	if err != nil {
+		l.Error("couldn't distribute report", zap.Error(err))
+
+		return err
+	}
+
+	if inProgress {
+		return newDuplicateInProgressError(inProgressUntil)
+	}
+
+	return nil
+}
+
+// complete records outcomes of claimed deliveries; forcedErr fails each of them. The first delivery error is returned, unless a later one may be fixed by redelivery.
+// NOTE: A failed delivery is recorded as already expired, so it's handled again on redelivery.
+func (w *distributeReportWorker) complete(ctx context.Context, ids []string, errs []error, forcedErr error) error {
+	l := utils.WithContext(ctx, w.logger)
+
+	firstErr := forcedErr
+	nonRetryable := (*messagequeue.NonRetryableError)(nil)
+	for i, id := range ids {
+		err := errs[i]
+		if forcedErr != nil {
+			err = forcedErr
+		}
+
+		o, ttl := domain.ProcessedMessageSucceeded, w.dedupeConfig.TTL
+		if err != nil {
+			o, ttl = domain.ProcessedMessageFailed, 0
+			if firstErr == nil || errors.As(firstErr, &nonRetryable) && !errors.As(err, &nonRetryable) {
+				firstErr = err
+			}
+		}
+
+		err = w.processedMessages.Complete(ctx, id, o, ttl)
+		if err != nil {
+			l.Error("couldn't record delivery outcome", zap.Error(err), zap.String("id", id))
+		}
+	}
+
+	return firstErr
+}
+
+func (w *distributeReportWorker) distribute(ctx context.Context, m *messagequeue.DistributeReportMessage, t *messagequeue.Tokens) []error {
+	l := utils.WithContext(ctx, w.logger)
+
+	errs := make([]error, len(m.Targets))
+	pos := []*utils.PageOptions(nil)
+	for _, pr := range m.Pages {
+		po := utils.PageOptions{
+			Name: pr.Name,
+			ID:   pr.ID,
+		}
+		pos = append(pos, &po)
+	}
+
+	o := &utils.ShareOptions{
+		ClientID:                m.ClientID,
+		ReportID:                m.ReportID,
+		ReportName:              m.ReportName,
+		Pages:                   pos,
+		WorkspaceID:             m.WorkspaceID,
+		UserID:                  m.UserID,
+		IsScheduled:             m.IsScheduled,
+		AccessToken:             t.PowerBIToken,
+		RetryAttempt:            m.RetryAttempt,
+		OutputFormat:            m.OutputFormat,
+		VisualName:              m.VisualName,
+		BypassCache:             m.BypassCache,
+		DataFormat:              m.DataFormat,
+		BookmarkName:            m.BookmarkName,
+		Filters:                 newTypedFilters(m.Filters),
+		ReportParameters:        newReportParameters(m.ReportParameters),
+		Layout:                  m.Layout,
+		DistributeReportMessage: m,
+	}
+	if m.Filter != nil {
+		o.Filter = &utils.FilterOptions{
+			Table:                   m.Filter.Table,
+			Column:                  m.Filter.Column,
+			Value:                   m.Filter.Value,
+			LogicalOperator:         m.Filter.LogicalOperator,
+			ConditionOperator:       m.Filter.ConditionOperator,
+			SecondValue:             m.Filter.SecondValue,
+			SecondConditionOperator: m.Filter.SecondConditionOperator,
+		}
+	}
+
+	owner := (*domain.User)(nil)
+	if m.ClientID == slackClient {
+		u, err := w.userUsecase.GetByID(ctx, &domain.SlackUserID{
+			WorkspaceID: m.WorkspaceID,
+			ID:          m.UserID,
+		})
+		if err != nil {
+			l.Error("couldn't get user", zap.Error(err))
+
+			for i := range errs {
+				errs[i] = err
+			}
+
+			return errs
+		}
+
+		owner = &u
+		o = utils.WithAccessToken(*o, u.AccessToken)
+	}
+
+	ds := []*usecases.ReportDelivery(nil)
+	is := []int(nil)
+	for i, dt := range m.Targets {
+		d, err := w.newDelivery(ctx, o, dt, t)
+		if err != nil {
+			l.Error("couldn't prepare delivery", zap.Error(err), zap.String("channelID", dt.ChannelID))
+			errs[i] = err
+
+			continue
+		}
+
+		ds = append(ds, d)
+		is = append(is, i)
+	}
+
+	if len(ds) == 0 {
+		return errs
+	}
+
+	for j, err := range w.reportUsecase.DistributeReport(ctx, owner, o, ds) {
+		errs[is[j]] = err
+	}
+
+	return errs
+}
+
+func (w *distributeReportWorker) newDelivery(ctx context.Context, o *utils.ShareOptions, dt *messagequeue.DeliveryTargetMessage, t *messagequeue.Tokens) (*usecases.ReportDelivery, error) {
+	do := *o
+	do.ClientID = dt.ClientID
+	do.ChannelID = dt.ChannelID
+	do.UserID = dt.UserID
+	do.WorkspaceID = dt.WorkspaceID
+
+	d := usecases.ReportDelivery{
+		Token:   t.BotAccessToken,
+		Options: &do,
+	}
+	if dt.ClientID != slackClient {
+		return &d, nil
+	}
+
+	u, err := w.userUsecase.GetByID(ctx, &domain.SlackUserID{
+		WorkspaceID: dt.WorkspaceID,
+		ID:          dt.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := w.workspaceUsecase.Get(ctx, dt.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	d.User = &u
+	d.Token = s.BotAccessToken
+
+	return &d, nil
+}
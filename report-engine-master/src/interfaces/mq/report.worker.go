@@ -3,39 +3,95 @@ package mq
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
-	
+
 )
 
 const (
 	slackClient = "slack"
 )
 
+var errDuplicateInProgress = fmt.Errorf("duplicate message is being handled already")
+
+// NOTE: A duplicate is handled again only once the claim of the message being handled expires, so it's postponed till then rather than received over & over.
+func newDuplicateInProgressError(expiresAt time.Time) error {
+	d := time.Until(expiresAt)
+	if d < 0 {
+		d = 0
+	}
+
+	return &messagequeue.DelayedError{
+		Err:   errDuplicateInProgress,
+		Delay: d,
+	}
+}
+
 type reportWorker struct {
-	reportUsecase    usecases.ReportUsecase
-	userUsecase      usecases.UserUsecase
-	workspaceUsecase usecases.WorkspaceUsecase
-	logger           *zap.Logger
+	reportUsecase     usecases.ReportUsecase
+	userUsecase       usecases.UserUsecase
+	workspaceUsecase  usecases.WorkspaceUsecase
+	processedMessages domain.ProcessedMessageRepository
+	dedupeConfig      *config.DedupeConfig
+	keyRing           *messagequeue.KeyRing
+	secretsConfig     *config.MessageSecretsConfig
+	logger            *zap.Logger
 }
 
 // NewReportWorker creates a Worker capable of report handling.
+// Duplicate deliveries of a message are detected by RenderReportMessage.UniqueID & recorded to pm.
+// Sealed tokens of a message are opened w/ k.
 func NewReportWorker(
 	r usecases.ReportUsecase,
 	u usecases.UserUsecase,
 	w usecases.WorkspaceUsecase,
+	pm domain.ProcessedMessageRepository,
+	c *config.DedupeConfig,
+	k *messagequeue.KeyRing,
+	sc *config.MessageSecretsConfig,
 	l *zap.Logger,
 ) Worker {
 	return &reportWorker{
-		reportUsecase:    r,
-		userUsecase:      u,
-		workspaceUsecase: w,
-		logger:           l,
+		reportUsecase:     r,
+		userUsecase:       u,
+		workspaceUsecase:  w,
+		processedMessages: pm,
+		dedupeConfig:      c,
+		keyRing:           k,
+		secretsConfig:     sc,
+		logger:            l,
 	}
 }
 
+// StartProcessedMessagesCleanup periodically deletes expired records of processed messages until ctx is done.
+func StartProcessedMessagesCleanup(ctx context.Context, pm domain.ProcessedMessageRepository, c *config.DedupeConfig, l *zap.Logger) {
+	utils.SafeRoutine(func() {
+		t := time.NewTicker(c.CleanupInterval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-t.C:
+				n, err := pm.DeleteExpired(ctx)
+				if err != nil {
+					l.Error("couldn't delete expired processed messages", zap.Error(err))
+
+					continue
+				}
+
+				l.Debug("deleted expired processed messages", zap.Int64("count", n))
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
 func (w *reportWorker) SupportedMessages() []messagequeue.MessageKind {
 	return []messagequeue.MessageKind{
 		messagequeue.MessagePostReport,
@@ -60,15 +116,72 @@ func (w *reportWorker) Handle(ctx context.Context, e *messagequeue.Envelope) err
 		return err
 	}
 
-	err = w.shareReport(ctx, p.(*messagequeue.PostReportMessage))
+	m := p.(*messagequeue.PostReportMessage)
+
+	// NOTE: Message is kept sealed, so it's pushed back as is on retry.
+	t, err := m.OpenTokens(w.keyRing, time.Now().Before(w.secretsConfig.PlaintextUntil))
+	if err != nil {
+		l.Error("couldn't open message tokens", zap.Error(err))
+
+		return err
+	}
+
+	if m.Token != nil && *m.Token != (messagequeue.Tokens{}) {
+		l.Warn("accepted plaintext message tokens", zap.String("uniqueID", m.UniqueID))
+	}
+
+	if m.UniqueID == "" {
+		err = w.shareReport(ctx, m, t)
+		if err != nil {
+			l.Error("couldn't share report", zap.Error(err))
+		}
+
+		return err
+	}
+
+	// NOTE: A retry is pushed w/ the same UniqueID, so each attempt is deduplicated separately.
+	id := fmt.Sprintf("%v/%v", m.UniqueID, m.RetryAttempt)
+	l = l.With(zap.String("uniqueID", m.UniqueID), zap.Int("retryAttempt", m.RetryAttempt))
+
+	pm, err := w.processedMessages.Claim(ctx, id, w.dedupeConfig.ClaimTTL)
+	if err == domain.ErrConflict {
+		if pm.Outcome == domain.ProcessedMessageInProgress {
+			l.Warn("duplicate message is being handled already")
+
+			return newDuplicateInProgressError(pm.ExpiresAt)
+		}
+
+		l.Info("skipped duplicate message", zap.String("outcome", string(pm.Outcome)))
+
+		return nil
+	}
+
+	if err != nil {
+		l.Error("couldn't claim message", zap.Error(err))
+
+		return err
+	}
+
+	err = w.shareReport(ctx, m, t)
 	if err != nil {
 		l.Error("couldn't share report", zap.Error(err))
 	}
 
+	// NOTE: A failed message is recorded as already expired, so its redelivery is handled again.
+	o, ttl := domain.ProcessedMessageSucceeded, w.dedupeConfig.TTL
+	if err != nil {
+		o, ttl = domain.ProcessedMessageFailed, 0
+	}
+
+	err2 := w.processedMessages.Complete(ctx, id, o, ttl)
+	if err2 != nil {
+		l.Error("couldn't record message outcome", zap.Error(err2))
+	}
+
 	return err
 }
 
-func (w *reportWorker) shareReport(ctx context.Context, r *messagequeue.PostReportMessage) error {
+func (w *reportWorker) shareReport(ctx context.Context, r *messagequeue.PostReportMessage, t *messagequeue.Tokens) error {
 	pis := []string(nil)
 	for _, p := range r.Pages {
 		pis = append(pis, p.ID)
@@ -103,8 +216,16 @@ func (w *reportWorker) shareReport(ctx context.Context, r *messagequeue.PostRepo
 		UserID:            r.UserID,
 		IsScheduled:       r.IsScheduled,
 		SkipPosting:       r.SkipPosting,
-		AccessToken:       r.Token.PowerBIToken,
+		AccessToken:       t.PowerBIToken,
 		RetryAttempt:      r.RetryAttempt,
+		OutputFormat:      r.OutputFormat,
+		VisualName:        r.VisualName,
+		BypassCache:       r.BypassCache,
+		DataFormat:        r.DataFormat,
+		BookmarkName:      r.BookmarkName,
+		Filters:           newTypedFilters(r.Filters),
+		ReportParameters:  newReportParameters(r.ReportParameters),
+		Layout:            r.Layout,
 		PostReportMessage: r,
 	}
 	var accessToken string
@@ -145,7 +266,7 @@ func (w *reportWorker) shareReport(ctx context.Context, r *messagequeue.PostRepo
 
 		accessToken = s.BotAccessToken
 	} else {
-		accessToken = r.Token.BotAccessToken
+		accessToken = t.BotAccessToken
 		usrPtr = nil
 	}
 
@@ -156,3 +277,63 @@ func (w *reportWorker) shareReport(ctx context.Context, r *messagequeue.PostRepo
 
 	return err
 }
+
+// newReportParameters maps parameters of a message to utils.ReportParameterOptions.
+func newReportParameters(pms []*messagequeue.ReportParameterMessage) []*utils.ReportParameterOptions {
+	ps := []*utils.ReportParameterOptions(nil)
+	for _, pm := range pms {
+		p := utils.ReportParameterOptions{
+			Name:  pm.Name,
+			Value: pm.Value,
+		}
+		ps = append(ps, &p)
+	}
+
+	return ps
+}
+
+// newTypedFilters maps filters of a message to domain.TypedFilter.
+func newTypedFilters(fms []*messagequeue.TypedFilterMessage) []*domain.TypedFilter {
+	fs := []*domain.TypedFilter(nil)
+	for _, fm := range fms {
+		f := domain.TypedFilter{
+			Kind:            domain.TypedFilterKind(fm.Kind),
+			Target:          newFilterTarget(fm.Target),
+			Operator:        fm.Operator,
+			Values:          fm.Values,
+			LogicalOperator: fm.LogicalOperator,
+			TimeUnitsCount:  fm.TimeUnitsCount,
+			TimeUnit:        fm.TimeUnit,
+			IncludeToday:    fm.IncludeToday,
+			ItemCount:       fm.ItemCount,
+			OrderBy:         newFilterTarget(fm.OrderBy),
+			Tuples:          fm.Tuples,
+		}
+		for _, c := range fm.Conditions {
+			f.Conditions = append(f.Conditions, &domain.FilterCondition{
+				Operator: c.Operator,
+				Value:    c.Value,
+			})
+		}
+
+		for _, t := range fm.Targets {
+			f.Targets = append(f.Targets, newFilterTarget(t))
+		}
+
+		fs = append(fs, &f)
+	}
+
+	return fs
+}
+
+func newFilterTarget(t *messagequeue.FilterTargetMessage) *domain.FilterTarget {
+	if t == nil {
+		return nil
+	}
+
+	return &domain.FilterTarget{
+		Table:   t.Table,
+		Column:  t.Column,
+		Measure: t.Measure,
+	}
+}
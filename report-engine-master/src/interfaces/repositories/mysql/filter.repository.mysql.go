@@ -161,6 +161,17 @@ func (r *filterRepository) fetch(ctx context.Context, isFastRetry bool, query st
 
 			filter.Definition = &definition
 
+		case domain.FilterKindTyped:
+			definition := []*domain.TypedFilter(nil)
+			err := json.Unmarshal(definitionJSON, &definition)
+			if err != nil {
+				l.Error("couldn't unmarshal definition", zap.Error(err))
+
+				return nil, err
+			}
+
+			filter.Definition = definition
+
 		default:
 			l.Error("unsupported filter kind", zap.String("kind", string(filter.Kind)), zap.Int64("filterID", filter.ID))
 		}
@@ -31,6 +31,11 @@ func (r *postReportTaskRepository) Add(ctx context.Context, t *domain.PostReport
 		return err
 	}
 
+	filtersJSON, err := filtersToJSON(t.Filters)
+	if err != nil {
+		return err
+	}
+
 	var dayOfWeek, dayOfMonth interface{}
 	if t.IsEveryDay || t.IsEveryHour {
 		dayOfWeek = nil
@@ -43,7 +48,7 @@ func (r *postReportTaskRepository) Add(ctx context.Context, t *domain.PostReport
 		dayOfMonth = t.DayOfMonth
 	}
 
-	query := `INSERT INTO postReportTasks SET id=?, workspaceID=?, userID=?, reportID=?, pageIDs=?, channelID=?, taskTime=?, dayOfWeek=?, dayOfMonth=?, isEveryDay=?, tz=?, completedAt=?, isActive=?, isEveryHour=?`
+	query := `INSERT INTO postReportTasks SET id=?, workspaceID=?, userID=?, reportID=?, pageIDs=?, channelID=?, taskTime=?, dayOfWeek=?, dayOfMonth=?, isEveryDay=?, tz=?, completedAt=?, isActive=?, isEveryHour=?, visualName=?, dataFormat=?, bookmarkName=?, filters=?, layout=?, outputFormat=?`
 	res, err := r.execute(
 		ctx,
 		true,
@@ -62,6 +67,12 @@ func (r *postReportTaskRepository) Add(ctx context.Context, t *domain.PostReport
 		sql.NullTime{},
 		t.IsActive,
 		t.IsEveryHour,
+		sql.NullString{String: t.VisualName, Valid: t.VisualName != ""},
+		sql.NullString{String: t.DataFormat, Valid: t.DataFormat != ""},
+		sql.NullString{String: t.BookmarkName, Valid: t.BookmarkName != ""},
+		sql.NullString{String: filtersJSON, Valid: len(t.Filters) != 0},
+		sql.NullString{String: t.Layout, Valid: t.Layout != ""},
+		sql.NullString{String: t.OutputFormat, Valid: t.OutputFormat != ""},
 	)
 	mysqlErr, ok := err.(*mysql.MySQLError)
 	if ok && mysqlErr.Number == errorCodeDuplicateEntry {
@@ -83,7 +94,7 @@ func (r *postReportTaskRepository) Add(ctx context.Context, t *domain.PostReport
 }
 
 func (r *postReportTaskRepository) GetScheduledReports(ctx context.Context, u domain.SlackUserID, reportID string) ([]*domain.PostReportTask, error) {
-	query := `SELECT id, workspaceID, userID, reportID, pageIDs, channelID, taskTime, IFNULL(dayOfWeek, 0), IFNULL(dayOfMonth, 0), isEveryDay, tz, completedAt, isActive, isEveryHour
+	query := `SELECT id, workspaceID, userID, reportID, pageIDs, channelID, taskTime, IFNULL(dayOfWeek, 0), IFNULL(dayOfMonth, 0), isEveryDay, tz, completedAt, isActive, isEveryHour, IFNULL(visualName, ''), IFNULL(dataFormat, ''), IFNULL(bookmarkName, ''), IFNULL(filters, 'null'), IFNULL(layout, ''), IFNULL(outputFormat, '')
 			  FROM postReportTasks
               WHERE workspaceID=? and userID=? and reportID=?`
 	reports, err := r.fetch(ctx, true, query, u.WorkspaceID, u.ID, reportID)
@@ -105,7 +116,7 @@ func (r *postReportTaskRepository) GetPowerBIReportIDsByUser(ctx context.Context
 }
 
 func (r *postReportTaskRepository) GetActualScheduledReports(ctx context.Context) ([]*domain.PostReportTask, error) {
-	query := `SELECT id, workspaceID, userID, reportID, pageIDs, channelID, taskTime, IFNULL(dayOfWeek, 0), IFNULL(dayOfMonth, 0), isEveryDay, tz, completedAt, isActive, isEveryHour
+	query := `SELECT id, workspaceID, userID, reportID, pageIDs, channelID, taskTime, IFNULL(dayOfWeek, 0), IFNULL(dayOfMonth, 0), isEveryDay, tz, completedAt, isActive, isEveryHour, IFNULL(visualName, ''), IFNULL(dataFormat, ''), IFNULL(bookmarkName, ''), IFNULL(filters, 'null'), IFNULL(layout, ''), IFNULL(outputFormat, '')
  			  FROM postReportTasks
 			  WHERE ADDTIME(UTC_TIME(), '-0:30') < TIME(taskTime) AND UTC_TIME() > TIME(taskTime)
     			AND (isEveryHour = true OR isEveryDay = true OR DAYOFWEEK(UTC_TIMESTAMP()) = dayOfWeek OR DAYOFMONTH(UTC_TIMESTAMP()) = dayOfMonth
@@ -138,7 +149,7 @@ func (r *postReportTaskRepository) DeleteBySlackInfo(ctx context.Context, u *dom
 }
 
 func (r *postReportTaskRepository) UpdateCompletionStatus(ctx context.Context, id int64) (bool, error) {
-	query := `SELECT id, workspaceID, userID, reportID, pageIDs, channelID, taskTime, IFNULL(dayOfWeek, 0), IFNULL(dayOfMonth, 0), isEveryDay, tz, completedAt, isActive, isEveryHour
+	query := `SELECT id, workspaceID, userID, reportID, pageIDs, channelID, taskTime, IFNULL(dayOfWeek, 0), IFNULL(dayOfMonth, 0), isEveryDay, tz, completedAt, isActive, isEveryHour, IFNULL(visualName, ''), IFNULL(dataFormat, ''), IFNULL(bookmarkName, ''), IFNULL(filters, 'null'), IFNULL(layout, ''), IFNULL(outputFormat, '')
  			  FROM postReportTasks WHERE id=?`
 	result, err := r.fetch(ctx, true, query, id)
 	if err != nil {
@@ -211,8 +222,20 @@ func (r *postReportTaskRepository) CheckIfReportScheduledAlready(ctx context.Con
 		  AND IFNULL(dayOfMonth, 0) = ?
 		  AND isEveryDay = ?
 		  AND isEveryHour = ?
+		  AND IFNULL(visualName, '') = ?
+		  AND IFNULL(dataFormat, '') = ?
+		  AND IFNULL(bookmarkName, '') = ?
+		  AND IFNULL(filters, CAST('null' AS JSON)) = CAST(? AS JSON)
+		  AND IFNULL(layout, '') = ?
+		  AND IFNULL(outputFormat, '') = ?
 	)`
 
+	// NOTE: Filters are compared as JSON values, so formatting they're stored w/ doesn't matter.
+	filtersJSON, err := filtersToJSON(t.Filters)
+	if err != nil {
+		return false, err
+	}
+
 	isExist, err := queryRowContextWithRetry(
 		ctx,
 		true,
@@ -228,6 +251,12 @@ func (r *postReportTaskRepository) CheckIfReportScheduledAlready(ctx context.Con
 		t.DayOfMonth,
 		t.IsEveryDay,
 		t.IsEveryHour,
+		t.VisualName,
+		t.DataFormat,
+		t.BookmarkName,
+		filtersJSON,
+		t.Layout,
+		t.OutputFormat,
 	)
 	if err != nil {
 		r.logger.Error("couldn't execute query", zap.Error(err))
@@ -287,6 +316,7 @@ func (r *postReportTaskRepository) fetch(ctx context.Context, isFastRetry bool,
 		task := domain.PostReportTask{}
 		completedAtNull := sql.NullTime{}
 		pageIDsJSON := sql.RawBytes{}
+		filtersJSON := sql.RawBytes{}
 		err := rows.Scan(
 			&task.ID,
 			&task.WorkspaceID,
@@ -302,6 +332,12 @@ func (r *postReportTaskRepository) fetch(ctx context.Context, isFastRetry bool,
 			&completedAtNull,
 			&task.IsActive,
 			&task.IsEveryHour,
+			&task.VisualName,
+			&task.DataFormat,
+			&task.BookmarkName,
+			&filtersJSON,
+			&task.Layout,
+			&task.OutputFormat,
 		)
 		if err != nil {
 			l.Error("couldn't scan row", zap.Error(err))
@@ -325,12 +361,33 @@ func (r *postReportTaskRepository) fetch(ctx context.Context, isFastRetry bool,
 
 		task.PageIDs = pageIDs
 
+		err = json.Unmarshal(filtersJSON, &task.Filters)
+		if err != nil {
+			l.Error("couldn't unmarshal filters", zap.Error(err))
+
+			return nil, err
+		}
+
 		result = append(result, &task)
 	}
 
 	return result, nil
 }
 
+// filtersToJSON marshals fs; no filters are marshaled as null.
+func filtersToJSON(fs []*domain.TypedFilter) (string, error) {
+	if len(fs) == 0 {
+		return "null", nil
+	}
+
+	j, err := json.Marshal(fs)
+	if err != nil {
+		return "", err
+	}
+
+	return string(j), nil
+}
+
 func (r *postReportTaskRepository) fetchReportIDs(ctx context.Context, isFastRetry bool, query string, args ...interface{}) ([]string, error) {
 	l := utils.
 		WithContext(ctx, r.logger).
@@ -0,0 +1,153 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"go.uber.org/zap"
+
+
+)
+
+type processedMessageRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewMySQLProcessedMessageRepository creates a domain.ProcessedMessageRepository.
+func NewMySQLProcessedMessageRepository(db *sql.DB, l *zap.Logger) domain.ProcessedMessageRepository {
+	return &processedMessageRepository{
+		db:     db,
+		logger: l,
+	}
+}
+
+// NOTE: An expired record is taken over first, otherwise a new one is inserted; a concurrent claim fails w/ duplicate entry.
+func (r *processedMessageRepository) Claim(ctx context.Context, id string, ttl time.Duration) (*domain.ProcessedMessage, error) {
+	query := `UPDATE processedMessages
+			  SET outcome=?, expiresAt=TIMESTAMPADD(MICROSECOND, ?, UTC_TIMESTAMP(6)), updatedAt=UTC_TIMESTAMP(6)
+			  WHERE id=? AND expiresAt <= UTC_TIMESTAMP(6)`
+	res, err := r.execute(ctx, query, domain.ProcessedMessageInProgress, ttl.Microseconds(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if rows == 1 {
+		return nil, nil
+	}
+
+	query = `INSERT INTO processedMessages
+			 SET id=?, outcome=?, expiresAt=TIMESTAMPADD(MICROSECOND, ?, UTC_TIMESTAMP(6)), createdAt=UTC_TIMESTAMP(6), updatedAt=UTC_TIMESTAMP(6)`
+	_, err = r.execute(ctx, query, id, domain.ProcessedMessageInProgress, ttl.Microseconds())
+	mysqlErr, ok := err.(*mysql.MySQLError)
+	if ok && mysqlErr.Number == errorCodeDuplicateEntry {
+		m, err := r.get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		return m, domain.ErrConflict
+	}
+
+	return nil, err
+}
+
+func (r *processedMessageRepository) Complete(ctx context.Context, id string, o domain.ProcessedMessageOutcome, ttl time.Duration) error {
+	query := `UPDATE processedMessages
+			  SET outcome=?, expiresAt=TIMESTAMPADD(MICROSECOND, ?, UTC_TIMESTAMP(6)), updatedAt=UTC_TIMESTAMP(6)
+			  WHERE id=?`
+	res, err := r.execute(ctx, query, o, ttl.Microseconds(), id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return domain.ErrNotUpdated
+	}
+
+	return nil
+}
+
+func (r *processedMessageRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	query := `DELETE FROM processedMessages WHERE expiresAt <= UTC_TIMESTAMP(6)`
+	res, err := r.execute(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func (r *processedMessageRepository) get(ctx context.Context, id string) (*domain.ProcessedMessage, error) {
+	l := utils.WithContext(ctx, r.logger)
+
+	query := `SELECT id, outcome, expiresAt FROM processedMessages WHERE id=?`
+	rows, err := queryContextWithRetry(ctx, true, r.logger, r.db, query, id)
+	if err != nil {
+		l.Error("couldn't execute query", zap.Error(err), zap.String("query", query))
+
+		return nil, err
+	}
+
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			l.Error("couldn't close rows", zap.Error(err))
+		}
+	}()
+
+	if !rows.Next() {
+		return nil, domain.ErrNotFound
+	}
+
+	m := domain.ProcessedMessage{}
+	err = rows.Scan(&m.ID, &m.Outcome, &m.ExpiresAt)
+	if err != nil {
+		l.Error("couldn't scan row", zap.Error(err))
+
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+func (r *processedMessageRepository) execute(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	l := utils.
+		WithContext(ctx, r.logger).
+		With(zap.String("query", query))
+
+	s, err := prepareContextWithRetry(ctx, true, r.logger, r.db, query)
+	if err != nil {
+		l.Error("couldn't create prepared statement", zap.Error(err))
+
+		return nil, err
+	}
+
+	defer func() {
+		err := s.Close()
+		if err != nil {
+			l.Error("couldn't close prepared statement", zap.Error(err))
+		}
+	}()
+
+	e, err := execContextWithRetry(ctx, true, r.logger, s, args...)
+	if err != nil {
+		l.Error("couldn't execute prepared statement", zap.Error(err))
+
+		return nil, err
+	}
+
+	return e, nil
+}
@@ -15,7 +15,7 @@ import (
 	"github.com/segmentio/ksuid"
 	"go.uber.org/zap"
 
-	
+
 )
 
 type zapCDPAdapter struct {
@@ -123,6 +123,45 @@ func waitLoaded() chromedp.Action {
 }
 
 func timestamp(ctx context.Context) string {
+	t, r := activityTime(ctx)
+
+	return fmt.Sprintf("%v %v", t.Format(time.RFC3339), strconv.FormatInt(int64(r), 10))
+}
+
+// pageFilename names an image of a page (or a visual of it) rendered at timestamp.
+func pageFilename(o *utils.ShareOptions, pageName, visualName, timestamp string) string {
+	name := pageName
+	if visualName != "" {
+		name = fmt.Sprintf("%v - %v", pageName, visualName)
+	}
+
+	if f := o.FilterString(); f != "" {
+		return fmt.Sprintf("%v (%v): %v %v.png", o.ReportName, f, name, timestamp)
+	}
+
+	return fmt.Sprintf("%v: %v %v.png", o.ReportName, name, timestamp)
+}
+
+// documentFilename names a document of a report rendered at timestamp.
+func documentFilename(o *utils.ShareOptions, extension, timestamp string) string {
+	if f := o.FilterString(); f != "" {
+		return fmt.Sprintf("%v (%v) %v.%v", o.ReportName, f, timestamp, extension)
+	}
+
+	return fmt.Sprintf("%v %v.%v", o.ReportName, timestamp, extension)
+}
+
+// layoutOf returns a layout pages of o are rendered in; a single visual is embedded alone, so it's rendered as designed.
+func layoutOf(o *utils.ShareOptions) messagequeue.Layout {
+	if o.Layout == "" || o.VisualName != "" {
+		return messagequeue.LayoutMaster
+	}
+
+	return o.Layout
+}
+
+// activityTime returns activity start time along w/ a random part of its ID, so they're the same for each file of a report.
+func activityTime(ctx context.Context) (time.Time, uint32) {
 	t := time.Time{}
 	r := uint32(0)
 
@@ -140,7 +179,7 @@ func timestamp(ctx context.Context) string {
 		r = uint32(rand.Int31())
 	}
 
-	return fmt.Sprintf("%v %v", t.Format(time.RFC3339), strconv.FormatInt(int64(r), 10))
+	return t, r
 }
 
 func tryEvaluate(res interface{}, exc interface{}, js string, os ...chromedp.EvaluateOption) chromedp.Action {
@@ -0,0 +1,189 @@
+package reportengine
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+)
+
+const pageCacheExtension = ".png"
+
+// PageCache keeps rendered page images by key.
+type PageCache interface {
+	Get(key string) ([]byte, bool, error)
+	Put(key string, data []byte) error
+}
+
+// DiskPageCache is a PageCache keeping each image in a file of its own; the least recently used ones are deleted once the size limit is exceeded.
+type DiskPageCache struct {
+	directory string
+	maxSize   int64
+	size      int64
+	mu        sync.Mutex
+	logger    *zap.Logger
+}
+
+// NewDiskPageCache creates a DiskPageCache; images cached before, e.g. prior to restart, are kept.
+func NewDiskPageCache(c *config.ImageCacheConfig, l *zap.Logger) (*DiskPageCache, error) {
+	err := os.MkdirAll(c.Directory, 0o700)
+	if err != nil {
+		return nil, err
+	}
+
+	_, size, err := listCachedImages(c.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DiskPageCache{
+		directory: c.Directory,
+		maxSize:   int64(c.MaxSizeMB) << 20,
+		size:      size,
+		logger:    l,
+	}, nil
+}
+
+// Get returns an image cached by key; false is returned if there's none.
+func (c *DiskPageCache) Get(key string) ([]byte, bool, error) {
+	p := c.path(key)
+	d, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	// NOTE: Modification time tracks the last use, so images used recently are deleted last.
+	now := time.Now()
+	err = os.Chtimes(p, now, now)
+	if err != nil {
+		c.logger.Warn("couldn't touch cached image", zap.Error(err), zap.String("key", key))
+	}
+
+	return d, true, nil
+}
+
+// Put caches an image by key; an image bigger than the size limit isn't cached.
+func (c *DiskPageCache) Put(key string, data []byte) error {
+	if int64(len(data)) > c.maxSize {
+		return nil
+	}
+
+	// NOTE: An image is written to a temporary file first, so a partial one is never read.
+	f, err := os.CreateTemp(c.directory, "*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	err2 := f.Close()
+	if err == nil {
+		err = err2
+	}
+
+	if err != nil {
+		_ = os.Remove(f.Name())
+
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p := c.path(key)
+	replaced := int64(0)
+	fi, err := os.Stat(p)
+	if err == nil {
+		replaced = fi.Size()
+	}
+
+	err = os.Rename(f.Name(), p)
+	if err != nil {
+		_ = os.Remove(f.Name())
+
+		return err
+	}
+
+	c.size += int64(len(data)) - replaced
+	if c.size > c.maxSize {
+		c.evict()
+	}
+
+	return nil
+}
+
+func (c *DiskPageCache) path(key string) string {
+	return filepath.Join(c.directory, key+pageCacheExtension)
+}
+
+// evict deletes the least recently used images till cached ones fit the size limit.
+func (c *DiskPageCache) evict() {
+	images, size, err := listCachedImages(c.directory)
+	if err != nil {
+		c.logger.Error("couldn't list cached images", zap.Error(err))
+
+		return
+	}
+
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].ModTime().Before(images[j].ModTime())
+	})
+
+	evicted := 0
+	for _, f := range images {
+		if size <= c.maxSize {
+			break
+		}
+
+		err := os.Remove(filepath.Join(c.directory, f.Name()))
+		if err != nil && !os.IsNotExist(err) {
+			c.logger.Error("couldn't delete cached image", zap.Error(err), zap.String("name", f.Name()))
+
+			continue
+		}
+
+		size -= f.Size()
+		evicted++
+	}
+
+	c.size = size
+	c.logger.Debug("evicted cached images", zap.Int("totalEvicted", evicted), zap.Int64("size", size))
+}
+
+// listCachedImages returns images of a directory along w/ their total size.
+func listCachedImages(directory string) ([]os.FileInfo, int64, error) {
+	es, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	images := []os.FileInfo(nil)
+	size := int64(0)
+	for _, e := range es {
+		if !strings.HasSuffix(e.Name(), pageCacheExtension) {
+			continue
+		}
+
+		f, err := e.Info()
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		images = append(images, f)
+		size += f.Size()
+	}
+
+	return images, size, nil
+}
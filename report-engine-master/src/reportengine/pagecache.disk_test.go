@@ -0,0 +1,141 @@
+package reportengine
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+)
+
+func TestDiskPageCacheEviction(t *testing.T) {
+	const kb = 1 << 10
+
+	type op struct {
+		get  bool
+		key  string
+		size int
+	}
+	tests := []struct {
+		name     string
+		ops      []op
+		wantKeys []string
+		wantSize int64
+	}{
+		{
+			name: "fits the limit",
+			ops: []op{
+				{key: "a", size: 400 * kb},
+				{key: "b", size: 400 * kb},
+			},
+			wantKeys: []string{"a", "b"},
+			wantSize: 800 * kb,
+		},
+		{
+			name: "least recently put evicted",
+			ops: []op{
+				{key: "a", size: 400 * kb},
+				{key: "b", size: 400 * kb},
+				{key: "c", size: 400 * kb},
+			},
+			wantKeys: []string{"b", "c"},
+			wantSize: 800 * kb,
+		},
+		{
+			name: "least recently got evicted",
+			ops: []op{
+				{key: "a", size: 400 * kb},
+				{key: "b", size: 400 * kb},
+				{get: true, key: "a"},
+				{key: "c", size: 400 * kb},
+			},
+			wantKeys: []string{"a", "c"},
+			wantSize: 800 * kb,
+		},
+		{
+			name: "replaced image counted once",
+			ops: []op{
+				{key: "a", size: 400 * kb},
+				{key: "b", size: 400 * kb},
+				{key: "a", size: 500 * kb},
+			},
+			wantKeys: []string{"a", "b"},
+			wantSize: 900 * kb,
+		},
+		{
+			name: "image over the limit not cached",
+			ops: []op{
+				{key: "a", size: 400 * kb},
+				{key: "b", size: 1025 * kb},
+			},
+			wantKeys: []string{"a"},
+			wantSize: 400 * kb,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := config.ImageCacheConfig{
+				Directory: t.TempDir(),
+				MaxSizeMB: 1,
+			}
+			cache, err := NewDiskPageCache(&c, zap.NewNop())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// NOTE: Images are aged by a second per op, so their use order doesn't depend on file system time resolution.
+			startedAt := time.Now().Add(-time.Hour)
+			for i, o := range tt.ops {
+				if o.get {
+					_, ok, err := cache.Get(o.key)
+					if err != nil || !ok {
+						t.Fatalf("Get(%v) = %v, %v", o.key, ok, err)
+					}
+				} else {
+					err := cache.Put(o.key, bytes.Repeat([]byte{byte(i)}, o.size))
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				usedAt := startedAt.Add(time.Duration(i) * time.Second)
+				err := os.Chtimes(cache.path(o.key), usedAt, usedAt)
+				if err != nil && !os.IsNotExist(err) {
+					t.Fatal(err)
+				}
+			}
+
+			images, size, err := listCachedImages(c.Directory)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			keys := []string(nil)
+			for _, f := range images {
+				keys = append(keys, strings.TrimSuffix(f.Name(), pageCacheExtension))
+			}
+
+			sort.Strings(keys)
+			if strings.Join(keys, ",") != strings.Join(tt.wantKeys, ",") {
+				t.Errorf("cached %v, want %v", keys, tt.wantKeys)
+			}
+
+			if size != tt.wantSize || cache.size != tt.wantSize {
+				t.Errorf("size = %v (tracked %v), want %v", size, cache.size, tt.wantSize)
+			}
+
+			reopened, err := NewDiskPageCache(&c, zap.NewNop())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if reopened.size != tt.wantSize {
+				t.Errorf("reopened size = %v, want %v", reopened.size, tt.wantSize)
+			}
+		})
+	}
+}
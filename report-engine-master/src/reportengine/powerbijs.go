@@ -2,37 +2,206 @@ package reportengine
 
 import (
 	"fmt"
+	"strings"
 
 
 )
 
 // NOTE: See `IReportLoadConfiguration' definition here `https://github.com/microsoft/powerbi-models/blob/master/src/models.ts'.
+// Type, EmbedURL, DashboardID & PageView are set for a dashboard or a tile only, see `IDashboardLoadConfiguration' & `ITileLoadConfiguration' definitions.
+// LayoutType isn't a part of it, the report template merges it into `settings' of its own instead.
 type reportLoadConfiguration struct {
 	AccessToken string        `json:"accessToken"`
 	ID          string        `json:"id"`
 	Filters     []interface{} `json:"filters,omitempty"`
+	Type        embedType     `json:"type,omitempty"`
+	EmbedURL    string        `json:"embedUrl,omitempty"`
+	DashboardID string        `json:"dashboardId,omitempty"`
+	PageView    pageView      `json:"pageView,omitempty"`
+	LayoutType  layoutType    `json:"layoutType,omitempty"`
 }
 
-func newReportLoadConfiguration(o *utils.ShareOptions) *reportLoadConfiguration {
+// NOTE: See `EmbedType' definition of `powerbi-client'.
+type embedType string
+
+const (
+	embedTypeDashboard embedType = "dashboard"
+	embedTypeTile      embedType = "tile"
+)
+
+// NOTE: See `PageView' definition.
+type pageView string
+
+const (
+	pageViewFitToWidth pageView = "fitToWidth"
+)
+
+// NOTE: See `LayoutType' definition; its members are passed by name.
+type layoutType string
+
+const (
+	layoutTypeMaster          layoutType = "Master"
+	layoutTypeMobilePortrait  layoutType = "MobilePortrait"
+	layoutTypeMobileLandscape layoutType = "MobileLandscape"
+)
+
+var layoutTypes = map[messagequeue.Layout]layoutType{
+	messagequeue.LayoutMaster:          layoutTypeMaster,
+	messagequeue.LayoutMobilePortrait:  layoutTypeMobilePortrait,
+	messagequeue.LayoutMobileLandscape: layoutTypeMobileLandscape,
+}
+
+const (
+	dashboardEmbedURL = "https://app.powerbi.com/dashboardEmbed?dashboardId=%v"
+	tileEmbedURL      = "https://app.powerbi.com/embed?dashboardId=%v&tileId=%v"
+)
+
+// NOTE: Filters & bookmarks can't be applied to a dashboard or a tile, so they're dropped for it.
+func newReportLoadConfiguration(o *utils.ShareOptions, ref *domain.ReportRef) *reportLoadConfiguration {
 	conf := reportLoadConfiguration{
 		AccessToken: o.AccessToken,
-		ID:          o.ReportID,
+		ID:          ref.ID,
+	}
+
+	switch ref.Type {
+	case domain.ReportTypeDashboard:
+		conf.Type = embedTypeDashboard
+		conf.EmbedURL = fmt.Sprintf(dashboardEmbedURL, ref.ID)
+		conf.PageView = pageViewFitToWidth
+
+		return &conf
+
+	case domain.ReportTypeTile:
+		conf.Type = embedTypeTile
+		conf.EmbedURL = fmt.Sprintf(tileEmbedURL, ref.DashboardID, ref.ID)
+		conf.DashboardID = ref.DashboardID
+
+		return &conf
 	}
 
 	if o.Filter != nil {
-		conf.Filters = []interface{}{
-			newAdvancedFilter(o.Filter),
-		}
+		conf.Filters = append(conf.Filters, newAdvancedFilter(o.Filter))
+	}
+
+	// NOTE: The master layout is the default one, so it isn't set to keep a config as is for the rest of reports.
+	if l := layoutOf(o); l != messagequeue.LayoutMaster {
+		conf.LayoutType = layoutTypes[l]
+	}
+
+	for _, f := range o.Filters {
+		conf.Filters = append(conf.Filters, newTypedFilter(f))
 	}
 
 	return &conf
 }
 
+// newTypedFilter maps f to a filter of a schema of its kind.
+func newTypedFilter(f *domain.TypedFilter) interface{} {
+	switch f.Kind {
+	case domain.TypedFilterBasic:
+		vs := []interface{}(nil)
+		for _, v := range f.Values {
+			vs = append(vs, v)
+		}
+
+		return &basicFilter{
+			filter:   newFilter(filterSchemaBasic, newColumnTarget(f.Target), filterTypeBasic),
+			Operator: basicFilterOperator(f.Operator),
+			Values:   vs,
+		}
+
+	case domain.TypedFilterAdvanced:
+		cs := []*condition(nil)
+		for _, c := range f.Conditions {
+			// NOTE: Conditions like `IsBlank' have no value, so it's omitted rather than sent empty.
+			cc := condition{
+				Operator: conditionOperator(c.Operator),
+			}
+			if c.Value != "" {
+				cc.Value = c.Value
+			}
+			cs = append(cs, &cc)
+		}
+
+		return &advancedFilter{
+			filter:          newFilter(filterSchemaAdvanced, newColumnTarget(f.Target), filterTypeAdvanced),
+			LogicalOperator: logicalOperator(f.LogicalOperator),
+			Conditions:      cs,
+		}
+
+	case domain.TypedFilterRelativeDate:
+		return &relativeDateFilter{
+			filter:         newFilter(filterSchemaRelativeDate, newColumnTarget(f.Target), filterTypeRelativeDate),
+			Operator:       relativeDateOperators[f.Operator],
+			TimeUnitsCount: f.TimeUnitsCount,
+			TimeUnitType:   relativeDateTimeUnits[f.TimeUnit],
+			IncludeToday:   f.IncludeToday,
+		}
+
+	case domain.TypedFilterRelativeTime:
+		return &relativeTimeFilter{
+			filter:         newFilter(filterSchemaRelativeTime, newColumnTarget(f.Target), filterTypeRelativeTime),
+			Operator:       relativeDateOperators[f.Operator],
+			TimeUnitsCount: f.TimeUnitsCount,
+			TimeUnitType:   relativeDateTimeUnits[f.TimeUnit],
+		}
+
+	case domain.TypedFilterTopN:
+		// NOTE: Items are ordered by either a column or a measure.
+		orderBy := interface{}(newColumnTarget(f.OrderBy))
+		if f.OrderBy.Measure != "" {
+			orderBy = &measureTarget{
+				baseTarget: &baseTarget{
+					Table: f.OrderBy.Table,
+				},
+				Measure: f.OrderBy.Measure,
+			}
+		}
+
+		return &topNFilter{
+			filter:    newFilter(filterSchemaTopN, newColumnTarget(f.Target), filterTypeTopN),
+			Operator:  topNFilterOperator(f.Operator),
+			ItemCount: f.ItemCount,
+			OrderBy:   orderBy,
+		}
+
+	case domain.TypedFilterTuple:
+		ts := []*columnTarget(nil)
+		for _, t := range f.Targets {
+			ts = append(ts, newColumnTarget(t))
+		}
+
+		vs := [][]*tupleElementValue(nil)
+		for _, t := range f.Tuples {
+			v := []*tupleElementValue(nil)
+			for _, e := range t {
+				v = append(v, &tupleElementValue{
+					Value: e,
+				})
+			}
+			vs = append(vs, v)
+		}
+
+		return &tupleFilter{
+			filter:   newFilter(filterSchemaTuple, ts, filterTypeTuple),
+			Operator: tupleFilterOperatorIn,
+			Values:   vs,
+		}
+	}
+
+	return nil
+}
+
+// NOTE: See `FilterType' definition.
 type filterType int
 
 const (
-	filterTypeAdvanced filterType = 0
-	filterTypeBasic    filterType = 1
+	filterTypeAdvanced     filterType = 0
+	filterTypeBasic        filterType = 1
+	filterTypeRelativeDate filterType = 4
+	filterTypeTopN         filterType = 5
+	filterTypeTuple        filterType = 6
+	filterTypeRelativeTime filterType = 7
 )
 
 // NOTE: See `IBaseTarget' definition.
@@ -46,11 +215,30 @@ type columnTarget struct {
 	Column string `json:"column"`
 }
 
+func newColumnTarget(t *domain.FilterTarget) *columnTarget {
+	return &columnTarget{
+		baseTarget: &baseTarget{
+			Table: t.Table,
+		},
+		Column: t.Column,
+	}
+}
+
+// NOTE: See `IMeasureTarget' definition.
+type measureTarget struct {
+	*baseTarget
+	Measure string `json:"measure"`
+}
+
 type filterSchema string
 
 const (
-	filterSchemaBasic    filterSchema = "http://powerbi.com/product/schema#basic"
-	filterSchemaAdvanced filterSchema = "http://powerbi.com/product/schema#advanced"
+	filterSchemaBasic        filterSchema = "http://powerbi.com/product/schema#basic"
+	filterSchemaAdvanced     filterSchema = "http://powerbi.com/product/schema#advanced"
+	filterSchemaRelativeDate filterSchema = "http://powerbi.com/product/schema#relativeDate"
+	filterSchemaRelativeTime filterSchema = "http://powerbi.com/product/schema#relativeTime"
+	filterSchemaTopN         filterSchema = "http://powerbi.com/product/schema#topN"
+	filterSchemaTuple        filterSchema = "http://powerbi.com/product/schema#tuple"
 )
 
 // NOTE: See `IFilter' definition.
@@ -60,6 +248,14 @@ type filter struct {
 	FilterType filterType   `json:"filterType"`
 }
 
+func newFilter(s filterSchema, target interface{}, t filterType) *filter {
+	return &filter{
+		Schema:     s,
+		Target:     target,
+		FilterType: t,
+	}
+}
+
 type basicFilterOperator string
 
 const (
@@ -136,6 +332,73 @@ func newAdvancedFilter(f *utils.FilterOptions) *advancedFilter {
 	}
 }
 
+// NOTE: See `RelativeDateOperators' definition.
+type relativeDateOperator int
+
+var relativeDateOperators = map[string]relativeDateOperator{
+	"InLast": 0,
+	"InThis": 1,
+	"InNext": 2,
+}
+
+// NOTE: See `RelativeDateFilterTimeUnit' definition; minutes & hours are for relative time filters only.
+type relativeDateTimeUnit int
+
+var relativeDateTimeUnits = map[string]relativeDateTimeUnit{
+	"Days":           0,
+	"Weeks":          1,
+	"CalendarWeeks":  2,
+	"Months":         3,
+	"CalendarMonths": 4,
+	"Years":          5,
+	"CalendarYears":  6,
+	"Minutes":        7,
+	"Hours":          8,
+}
+
+// NOTE: See `IRelativeDateFilter' definition.
+type relativeDateFilter struct {
+	*filter
+	Operator       relativeDateOperator `json:"operator"`
+	TimeUnitsCount int                  `json:"timeUnitsCount"`
+	TimeUnitType   relativeDateTimeUnit `json:"timeUnitType"`
+	IncludeToday   bool                 `json:"includeToday"`
+}
+
+// NOTE: See `IRelativeTimeFilter' definition.
+type relativeTimeFilter struct {
+	*filter
+	Operator       relativeDateOperator `json:"operator"`
+	TimeUnitsCount int                  `json:"timeUnitsCount"`
+	TimeUnitType   relativeDateTimeUnit `json:"timeUnitType"`
+}
+
+type topNFilterOperator string
+
+// NOTE: See `ITopNFilter' definition.
+type topNFilter struct {
+	*filter
+	Operator  topNFilterOperator `json:"operator"`
+	ItemCount int                `json:"itemCount"`
+	OrderBy   interface{}        `json:"orderBy"`
+}
+
+type tupleFilterOperator string
+
+const tupleFilterOperatorIn tupleFilterOperator = "In"
+
+// NOTE: See `ITupleElementValue' definition.
+type tupleElementValue struct {
+	Value interface{} `json:"value"`
+}
+
+// NOTE: See `ITupleFilter' definition; its target is a list of columns, each tuple holds a value for each of them.
+type tupleFilter struct {
+	*filter
+	Operator tupleFilterOperator    `json:"operator"`
+	Values   [][]*tupleElementValue `json:"values"`
+}
+
 // NOTE: See `ICustomPageSize' definition.
 type customPageSize struct {
 	Height int64 `json:"height,omitempty"`
@@ -179,6 +442,34 @@ func (p *pbiError) Error() string {
 	return s
 }
 
+// NOTE: Power BI puts HTTP status of a failed request to error code, while expired token has a dedicated one.
+func (p *pbiError) isAuthError() bool {
+	switch p.ErrorCode {
+	case "401", "403", "TokenExpired":
+		return true
+	}
+
+	return p.Message == "TokenExpired"
+}
+
+// NOTE: Export errors aren't documented; a report (or tenant) which doesn't allow export fails w/ HTTP status or a message saying so.
+func (p *pbiError) isExportNotAllowed() bool {
+	if p.ErrorCode == "401" || p.ErrorCode == "403" {
+		return true
+	}
+
+	m := strings.ToLower(p.Message + " " + p.DetailedMessage)
+
+	return strings.Contains(m, "export") && (strings.Contains(m, "not allowed") || strings.Contains(m, "disabled") || strings.Contains(m, "permission"))
+}
+
+// exportedVisual is a result of `reportRenderer.exportData'; either Data or Error is set.
+type exportedVisual struct {
+	Title string    `json:"title"`
+	Data  string    `json:"data"`
+	Error *pbiError `json:"error"`
+}
+
 // NOTE: See `ICustomEvent' definition.
 type customEvent struct {
 	Detail *pbiError `json:"detail"`
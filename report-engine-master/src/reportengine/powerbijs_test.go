@@ -0,0 +1,185 @@
+package reportengine
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+)
+
+func TestNewTypedFilter(t *testing.T) {
+	region := &domain.FilterTarget{Table: "Sales", Column: "Region"}
+	date := &domain.FilterTarget{Table: "Sales", Column: "Date"}
+
+	tests := []struct {
+		name   string
+		filter domain.TypedFilter
+		want   string
+	}{
+		{
+			name: "basic",
+			filter: domain.TypedFilter{
+				Kind:     domain.TypedFilterBasic,
+				Target:   region,
+				Operator: "NotIn",
+				Values:   []string{"West", "East"},
+			},
+			want: `{
+				"$schema": "http://powerbi.com/product/schema#basic",
+				"target": {"table": "Sales", "column": "Region"},
+				"filterType": 1,
+				"operator": "NotIn",
+				"values": ["West", "East"]
+			}`,
+		},
+		{
+			name: "advanced",
+			filter: domain.TypedFilter{
+				Kind:            domain.TypedFilterAdvanced,
+				Target:          region,
+				LogicalOperator: "Or",
+				Conditions: []*domain.FilterCondition{
+					{Operator: "Contains", Value: "West"},
+					{Operator: "IsBlank"},
+				},
+			},
+			want: `{
+				"$schema": "http://powerbi.com/product/schema#advanced",
+				"target": {"table": "Sales", "column": "Region"},
+				"filterType": 0,
+				"logicalOperator": "Or",
+				"conditions": [
+					{"operator": "Contains", "value": "West"},
+					{"operator": "IsBlank"}
+				]
+			}`,
+		},
+		{
+			name: "relative date",
+			filter: domain.TypedFilter{
+				Kind:           domain.TypedFilterRelativeDate,
+				Target:         date,
+				Operator:       "InLast",
+				TimeUnitsCount: 3,
+				TimeUnit:       "CalendarMonths",
+				IncludeToday:   true,
+			},
+			want: `{
+				"$schema": "http://powerbi.com/product/schema#relativeDate",
+				"target": {"table": "Sales", "column": "Date"},
+				"filterType": 4,
+				"operator": 0,
+				"timeUnitsCount": 3,
+				"timeUnitType": 4,
+				"includeToday": true
+			}`,
+		},
+		{
+			name: "relative time",
+			filter: domain.TypedFilter{
+				Kind:           domain.TypedFilterRelativeTime,
+				Target:         date,
+				Operator:       "InNext",
+				TimeUnitsCount: 2,
+				TimeUnit:       "Hours",
+			},
+			want: `{
+				"$schema": "http://powerbi.com/product/schema#relativeTime",
+				"target": {"table": "Sales", "column": "Date"},
+				"filterType": 7,
+				"operator": 2,
+				"timeUnitsCount": 2,
+				"timeUnitType": 8
+			}`,
+		},
+		{
+			name: "top n by a column",
+			filter: domain.TypedFilter{
+				Kind:      domain.TypedFilterTopN,
+				Target:    region,
+				Operator:  "Top",
+				ItemCount: 5,
+				OrderBy:   &domain.FilterTarget{Table: "Sales", Column: "Amount"},
+			},
+			want: `{
+				"$schema": "http://powerbi.com/product/schema#topN",
+				"target": {"table": "Sales", "column": "Region"},
+				"filterType": 5,
+				"operator": "Top",
+				"itemCount": 5,
+				"orderBy": {"table": "Sales", "column": "Amount"}
+			}`,
+		},
+		{
+			name: "top n by a measure",
+			filter: domain.TypedFilter{
+				Kind:      domain.TypedFilterTopN,
+				Target:    region,
+				Operator:  "Bottom",
+				ItemCount: 3,
+				OrderBy:   &domain.FilterTarget{Table: "Sales", Measure: "Total Sales"},
+			},
+			want: `{
+				"$schema": "http://powerbi.com/product/schema#topN",
+				"target": {"table": "Sales", "column": "Region"},
+				"filterType": 5,
+				"operator": "Bottom",
+				"itemCount": 3,
+				"orderBy": {"table": "Sales", "measure": "Total Sales"}
+			}`,
+		},
+		{
+			name: "tuple",
+			filter: domain.TypedFilter{
+				Kind:    domain.TypedFilterTuple,
+				Targets: []*domain.FilterTarget{region, date},
+				Tuples: [][]string{
+					{"West", "2026-10-01"},
+					{"East", "2026-10-02"},
+				},
+			},
+			want: `{
+				"$schema": "http://powerbi.com/product/schema#tuple",
+				"target": [
+					{"table": "Sales", "column": "Region"},
+					{"table": "Sales", "column": "Date"}
+				],
+				"filterType": 6,
+				"operator": "In",
+				"values": [
+					[{"value": "West"}, {"value": "2026-10-01"}],
+					[{"value": "East"}, {"value": "2026-10-02"}]
+				]
+			}`,
+		},
+		{
+			name: "unknown kind",
+			filter: domain.TypedFilter{
+				Kind:   "hierarchy",
+				Target: region,
+			},
+			want: `null`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(newTypedFilter(&tt.filter))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, want := interface{}(nil), interface{}(nil)
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("newTypedFilter() = %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package reportengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+
+)
+
+// readinessSignal is what completed waiting for a page to be fully drawn.
+type readinessSignal string
+
+const (
+	// signalRendered means a page was fully drawn already once its render event fired.
+	signalRendered readinessSignal = "rendered"
+	// signalNetworkIdle means DOM was stable already, so the wait ended once requests were done.
+	signalNetworkIdle readinessSignal = "networkIdle"
+	// signalDOMStable means there were no pending requests already, so the wait ended once DOM stopped changing.
+	signalDOMStable readinessSignal = "domStable"
+	signalTimeout   readinessSignal = "timeout"
+	signalDelay     readinessSignal = "delay"
+)
+
+const (
+	readinessPollInterval = 100 * time.Millisecond
+	readinessWorldName    = "readiness"
+)
+
+// NOTE: Observers are set in an isolated world of each frame, so cross-origin frames of a report & its visuals are observed too.
+const (
+	observeMutationsJS = `(() => {
+  globalThis.lastMutationAt = Date.now();
+  new MutationObserver(() => {
+    globalThis.lastMutationAt = Date.now();
+  }).observe(document, { subtree: true, childList: true, attributes: true, characterData: true });
+})();`
+	sinceLastMutationJS = "Date.now() - globalThis.lastMutationAt;"
+)
+
+// readinessTracker tracks signals of a tab being fully drawn: pending requests & DOM mutations of each frame.
+type readinessTracker struct {
+	config        *config.BrowserConfig
+	cancel        context.CancelFunc
+	mu            sync.Mutex
+	pending       map[network.RequestID]struct{}
+	lastRequestAt time.Time
+	// contexts are execution contexts of frames observed, by frame ID.
+	contexts map[cdp.FrameID]runtime.ExecutionContextID
+}
+
+// startReadinessTracker starts tracking a tab of ctx; it must be started before rendering, so requests made by visuals are tracked.
+func startReadinessTracker(ctx context.Context, c *config.BrowserConfig) (*readinessTracker, error) {
+	err := network.Enable().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	listenCtx, cancelListen := context.WithCancel(ctx)
+	t := readinessTracker{
+		config:        c,
+		cancel:        cancelListen,
+		pending:       map[network.RequestID]struct{}{},
+		lastRequestAt: time.Now(),
+		contexts:      map[cdp.FrameID]runtime.ExecutionContextID{},
+	}
+
+	chromedp.ListenTarget(listenCtx, func(ev interface{}) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		switch ev := ev.(type) {
+		case *network.EventRequestWillBeSent:
+			// NOTE: Event streams stay open, so they'd never let a tab get idle.
+			if ev.Type == network.ResourceTypeEventSource {
+				return
+			}
+
+			t.pending[ev.RequestID] = struct{}{}
+
+		case *network.EventLoadingFinished:
+			delete(t.pending, ev.RequestID)
+
+		case *network.EventLoadingFailed:
+			delete(t.pending, ev.RequestID)
+
+		default:
+			return
+		}
+
+		t.lastRequestAt = time.Now()
+	})
+
+	err = t.observeFrames(ctx)
+	if err != nil {
+		cancelListen()
+
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (t *readinessTracker) stop() {
+	t.cancel()
+}
+
+// wait waits till a tab has no pending requests for config.BrowserConfig.NetworkIdleTime & no DOM mutations for config.BrowserConfig.DOMStableTime, but no longer than config.BrowserConfig.ReadinessTimeout.
+// The signal which was the last to settle is returned.
+func (t *readinessTracker) wait(ctx context.Context) (readinessSignal, error) {
+	deadline := time.Now().Add(t.config.ReadinessTimeout)
+	signal := signalRendered
+	for {
+		idle := t.networkIdle()
+		stable, err := t.domStable(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		if idle && stable {
+			return signal, nil
+		}
+
+		if !idle {
+			signal = signalNetworkIdle
+		}
+
+		if !stable {
+			signal = signalDOMStable
+		}
+
+		if time.Now().After(deadline) {
+			return signalTimeout, nil
+		}
+
+		select {
+		case <-time.After(readinessPollInterval):
+
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+func (t *readinessTracker) networkIdle() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.pending) == 0 && time.Since(t.lastRequestAt) >= t.config.NetworkIdleTime
+}
+
+// domStable checks each frame observed had no DOM mutations for config.BrowserConfig.DOMStableTime; frames added since are observed from now on.
+func (t *readinessTracker) domStable(ctx context.Context) (bool, error) {
+	err := t.observeFrames(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	stable := true
+	for id, c := range t.contexts {
+		r, exc, err := runtime.Evaluate(sinceLastMutationJS).WithContextID(c).WithReturnByValue(true).Do(ctx)
+		if err != nil || exc != nil {
+			// NOTE: A frame navigated elsewhere loses its context, so it's observed anew.
+			delete(t.contexts, id)
+			stable = false
+
+			continue
+		}
+
+		since := 0.0
+		err = json.Unmarshal(r.Value, &since)
+		if err != nil {
+			return false, err
+		}
+
+		if time.Duration(since)*time.Millisecond < t.config.DOMStableTime {
+			stable = false
+		}
+	}
+
+	return stable, nil
+}
+
+// observeFrames sets a DOM mutation observer in each frame which isn't observed yet.
+func (t *readinessTracker) observeFrames(ctx context.Context) error {
+	tree, err := page.GetFrameTree().Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	frames := []*page.FrameTree{tree}
+	for len(frames) > 0 {
+		f := frames[0]
+		frames = append(frames[1:], f.ChildFrames...)
+
+		_, ok := t.contexts[f.Frame.ID]
+		if ok {
+			continue
+		}
+
+		c, err := page.CreateIsolatedWorld(f.Frame.ID).WithWorldName(readinessWorldName).Do(ctx)
+		if err != nil {
+			return fmt.Errorf("couldn't create isolated world: %w", err)
+		}
+
+		_, exc, err := runtime.Evaluate(observeMutationsJS).WithContextID(c).Do(ctx)
+		if err != nil {
+			return err
+		}
+
+		// NOTE: A frame w/o a document yet is observed on the next check.
+		if exc != nil {
+			continue
+		}
+
+		t.contexts[f.Frame.ID] = c
+	}
+
+	return nil
+}
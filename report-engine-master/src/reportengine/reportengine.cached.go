@@ -0,0 +1,198 @@
+package reportengine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+
+)
+
+// DatasetRefreshResolver returns the time data of a report was last refreshed at; zero time means it's unknown, so the report isn't cached.
+type DatasetRefreshResolver func(ctx context.Context, o *utils.ShareOptions) (time.Time, error)
+
+// CachedReportEngine is a ReportEngine taking pages rendered from the same data & w/ the same filter from PageCache; the rest of pages are rendered by another ReportEngine.
+type CachedReportEngine struct {
+	engine         ReportEngine
+	cache          PageCache
+	resolveRefresh DatasetRefreshResolver
+	logger         *zap.Logger
+}
+
+// NewCachedReportEngine creates a CachedReportEngine rendering pages missing in c w/ e.
+func NewCachedReportEngine(e ReportEngine, c PageCache, r DatasetRefreshResolver, l *zap.Logger) *CachedReportEngine {
+	return &CachedReportEngine{
+		engine:         e,
+		cache:          c,
+		resolveRefresh: r,
+		logger:         l,
+	}
+}
+
+// NewContext creates a context.Context of the underlying ReportEngine.
+func (e *CachedReportEngine) NewContext(o *utils.ShareOptions) (context.Context, context.CancelFunc, error) {
+	return e.engine.NewContext(o)
+}
+
+// RenderReport takes pages from PageCache unless utils.ShareOptions.BypassCache is set; pages rendered are cached in either case.
+// NOTE: A document of messagequeue.OutputFormatPDF is printed from rendered pages & data of visuals is exported from them, so they aren't taken from PageCache for either.
+func (e *CachedReportEngine) RenderReport(ctx context.Context, o *utils.ShareOptions) (*RenderedReport, error) {
+	l := utils.WithContext(ctx, e.logger)
+
+	refreshedAt, err := e.resolveRefresh(ctx, o)
+	if err != nil {
+		l.Warn("couldn't get dataset refresh time", zap.Error(err))
+	}
+
+	// NOTE: Row-level security may show users different data of the same report, so pages of an unknown user aren't cached.
+	if refreshedAt.IsZero() || o.UserID == "" {
+		return e.engine.RenderReport(ctx, o)
+	}
+
+	keys := map[string]string{}
+	cached := map[string][]byte{}
+	missing := []*utils.PageOptions(nil)
+	for _, p := range o.Pages {
+		k, err := pageCacheKey(o, p.ID, refreshedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		keys[p.ID] = k
+		if o.BypassCache || o.OutputFormat == messagequeue.OutputFormatPDF || o.DataFormat != "" {
+			missing = append(missing, p)
+
+			continue
+		}
+
+		d, ok, err := e.cache.Get(k)
+		if err != nil {
+			l.Warn("couldn't get cached image", zap.Error(err), zap.String("pageID", p.ID))
+		}
+
+		if !ok {
+			missing = append(missing, p)
+
+			continue
+		}
+
+		cached[p.ID] = d
+	}
+
+	renderedReport := &RenderedReport{
+		ID:   o.ReportID,
+		Name: o.ReportName,
+	}
+	if len(missing) > 0 {
+		mo := *o
+		mo.Pages = missing
+		renderedReport, err = e.engine.RenderReport(ctx, &mo)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range renderedReport.Pages {
+			err := e.cache.Put(keys[p.ID], p.ImageData)
+			if err != nil {
+				l.Warn("couldn't cache image", zap.Error(err), zap.String("pageID", p.ID))
+			}
+		}
+	}
+
+	if len(cached) == 0 {
+		return renderedReport, nil
+	}
+
+	// NOTE: Pages keep the order they're chosen in, no matter which of them are cached.
+	renderedPages := map[string]*RenderedPage{}
+	for _, p := range renderedReport.Pages {
+		renderedPages[p.ID] = p
+	}
+
+	timestamp := timestamp(ctx)
+	pages := []*RenderedPage(nil)
+	for _, p := range o.Pages {
+		renderedPage, ok := renderedPages[p.ID]
+		if !ok {
+			renderedPage = &RenderedPage{
+				ID:         p.ID,
+				Name:       p.Name,
+				VisualName: o.VisualName,
+				Filename:   pageFilename(o, p.Name, o.VisualName, timestamp),
+				ImageData:  cached[p.ID],
+			}
+		}
+
+		pages = append(pages, renderedPage)
+	}
+
+	renderedReport.Pages = pages
+	l.Info("took pages from cache", zap.Int("totalCached", len(cached)), zap.Int("totalRendered", len(missing)))
+
+	return renderedReport, nil
+}
+
+// pageCacheKey identifies an image of a page (or a visual of it) rendered for the same user w/ the same filters & bookmark from data refreshed at refreshedAt.
+// NOTE: Bookmark name & typed filters are omitted if unset, so they don't change keys of pages w/o them.
+func pageCacheKey(o *utils.ShareOptions, pageID string, refreshedAt time.Time) (string, error) {
+	k := struct {
+		WorkspaceID  string                `json:"workspaceID"`
+		UserID       string                `json:"userID"`
+		ReportID     string                `json:"reportID"`
+		PageID       string                `json:"pageID"`
+		VisualName   string                `json:"visualName"`
+		BookmarkName string                `json:"bookmarkName,omitempty"`
+		Layout       messagequeue.Layout   `json:"layout,omitempty"`
+		Filter       *utils.FilterOptions  `json:"filter"`
+		Filters      []*domain.TypedFilter `json:"filters,omitempty"`
+		RefreshedAt  time.Time             `json:"refreshedAt"`
+	}{
+		WorkspaceID:  o.WorkspaceID,
+		UserID:       o.UserID,
+		ReportID:     o.ReportID,
+		PageID:       pageID,
+		VisualName:   o.VisualName,
+		BookmarkName: o.BookmarkName,
+		Layout:       cachedLayout(o),
+		Filter:       normalizeFilter(o.Filter),
+		Filters:      o.Filters,
+		RefreshedAt:  refreshedAt.UTC(),
+	}
+	j, err := json.Marshal(k)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.Sum256(j)
+
+	return hex.EncodeToString(h[:]), nil
+}
+
+// cachedLayout returns a layout of o for a cache key; it's empty for the master one, so pages cached w/o a layout are still taken.
+func cachedLayout(o *utils.ShareOptions) messagequeue.Layout {
+	l := layoutOf(o)
+	if l == messagequeue.LayoutMaster {
+		return ""
+	}
+
+	return l
+}
+
+// normalizeFilter drops parts of f which aren't applied to a report, i.e. the second condition w/o a logical operator.
+// NOTE: Values & operators are passed to Power BI as is, so they aren't trimmed or case-folded.
+func normalizeFilter(f *utils.FilterOptions) *utils.FilterOptions {
+	if f == nil {
+		return nil
+	}
+
+	n := *f
+	if n.LogicalOperator == "" {
+		n.SecondValue = ""
+		n.SecondConditionOperator = ""
+	}
+
+	return &n
+}
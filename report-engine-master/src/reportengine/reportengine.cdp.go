@@ -1,15 +1,21 @@
 package reportengine
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"html/template"
 	"net/url"
 	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/cdproto/runtime"
 	"github.com/chromedp/chromedp"
 	"go.uber.org/zap"
@@ -25,6 +31,8 @@ type CDPEngine struct {
 	allocatorCtx      context.Context
 	browserCtxOptions []chromedp.ContextOption
 	browserCtx        context.Context
+	browserMu         sync.Mutex
+	pool              *tabPool
 }
 
 // NewCDPReportEngine creates a Chrome-based ReportEngine.
@@ -45,54 +53,104 @@ func NewCDPReportEngine(c *config.BrowserConfig, l *zap.Logger) *CDPEngine {
 		browserCtxOptions = append(browserCtxOptions, chromedp.WithBrowserOption(browserOptions...))
 	}
 
-	return &CDPEngine{
+	e := CDPEngine{
 		config:            c,
 		logger:            l,
 		allocatorOptions:  allocatorOptions,
 		browserCtxOptions: browserCtxOptions,
 	}
+	e.pool = newTabPool(c, l, e.openTab)
+
+	return &e
 }
 
-// Start preconfigures a CDPEngine.
+// Start preconfigures a CDPEngine & opens pooled tabs.
 func (e *CDPEngine) Start(ctx context.Context) error {
 	e.allocatorCtx, _ = chromedp.NewExecAllocator(ctx, e.allocatorOptions...)
 
-	return e.startBrowser()
+	err := e.startBrowser()
+	if err != nil {
+		return err
+	}
+
+	e.pool.start(ctx)
+
+	return nil
 }
 
 // Stop releases resources held by CDPEngine.
 func (e *CDPEngine) Stop() error {
-	return chromedp.Cancel(e.browserCtx)
+	e.pool.drain()
+
+	return chromedp.Cancel(e.BrowserContext())
 }
 
-// NewContext creates a context.Context suitable to pass to other methods.
-func (e *CDPEngine) NewContext() (context.Context, context.CancelFunc, error) {
-	// NOTE: To ensure stable behavior, we attempt to revive browser process if it had died in the meantime.
-	err := e.browserCtx.Err()
-	if err == context.Canceled {
-		err2 := e.startBrowser()
-		if err2 != nil {
-			return nil, nil, err2
-		}
-	} else if err != nil {
+// BrowserContext returns a context.Context bound to the Chrome instance, so it can be shared w/ other components.
+func (e *CDPEngine) BrowserContext() context.Context {
+	e.browserMu.Lock()
+	defer e.browserMu.Unlock()
+
+	return e.browserCtx
+}
+
+// TabPoolStats returns counters of pooled tabs, e.g. how many renders wait for a tab & for how long.
+func (e *CDPEngine) TabPoolStats() TabPoolStats {
+	return e.pool.Stats()
+}
+
+// NewContext creates a context.Context suitable to pass to other methods; it holds a pooled tab till cancelled.
+// NOTE: Both waiting for a tab & rendering in it are limited by config.BrowserConfig.TabTimeout.
+func (e *CDPEngine) NewContext(_ *utils.ShareOptions) (context.Context, context.CancelFunc, error) {
+	browserCtx, err := e.reviveBrowser()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deadline := time.Now().Add(e.config.TabTimeout)
+	acquireCtx, cancelAcquire := context.WithDeadline(browserCtx, deadline)
+	defer cancelAcquire()
+
+	t, err := e.pool.acquire(acquireCtx)
+	if err != nil {
 		return nil, nil, err
 	}
 
-	timeoutCtx, cancelTimeout := context.WithTimeout(e.browserCtx, e.config.TabTimeout)
-	tabCtx, _ := chromedp.NewContext(timeoutCtx)
+	timeoutCtx, cancelTimeout := context.WithDeadline(t.ctx, deadline)
+	release := sync.Once{}
+	cancel := func() {
+		release.Do(func() {
+			cancelTimeout()
+			e.pool.release(t)
+		})
+	}
 
-	return tabCtx, cancelTimeout, nil
+	return withPooledTab(timeoutCtx, t), cancel, nil
 }
 
-// RenderReport renders a report into set of images for each page chosen.
+// RenderReport renders a report into set of images for each page chosen; for messagequeue.OutputFormatPDF, they're also printed into a single document.
 func (e *CDPEngine) RenderReport(ctx context.Context, o *utils.ShareOptions) (*RenderedReport, error) {
 	template, err := e.newRenderReportTemplate(resourceReportTemplate2)
 	if err != nil {
 		return nil, err
 	}
 
+	ref, err := domain.ParseReportID(o.ReportID)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: A pooled tab has the template loaded already; the rest of tabs load it first.
+	tab := pooledTabFromContext(ctx)
+	setTabState(tab, tabRendering)
+	if tab == nil {
+		err := chromedp.Run(ctx, e.newInitializeTask(ctx, template))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	screenshots := []*pageScreenshot(nil)
-	takeScreenshots := e.newScreenshotPagesTask(ctx, &screenshots, template, o)
+	takeScreenshots := e.newScreenshotPagesTask(ctx, &screenshots, o, ref)
 	err = chromedp.Run(ctx, takeScreenshots)
 	if err != nil {
 		return nil, err
@@ -101,27 +159,61 @@ func (e *CDPEngine) RenderReport(ctx context.Context, o *utils.ShareOptions) (*R
 	timestamp := timestamp(ctx)
 	pages := []*RenderedPage(nil)
 	for _, pageScreenshot := range screenshots {
-		filename := ""
-		if o.Filter != nil {
-			filename = fmt.Sprintf("%v (%v): %v %v.png", o.ReportName, o.Filter.String(), pageScreenshot.pageName, timestamp)
-		} else {
-			filename = fmt.Sprintf("%v: %v %v.png", o.ReportName, pageScreenshot.pageName, timestamp)
-		}
-
 		renderedPage := RenderedPage{
-			ID:        pageScreenshot.pageID,
-			Name:      pageScreenshot.pageName,
-			Filename:  filename,
-			ImageData: pageScreenshot.rawData,
+			ID:         pageScreenshot.pageID,
+			Name:       pageScreenshot.pageName,
+			VisualName: pageScreenshot.visualName,
+			Filename:   pageFilename(o, pageScreenshot.pageName, pageScreenshot.visualName, timestamp),
+			ImageData:  pageScreenshot.rawData,
 		}
 		pages = append(pages, &renderedPage)
 	}
 
-	return &RenderedReport{
+	renderedReport := RenderedReport{
 		ID:    o.ReportID,
 		Name:  o.ReportName,
 		Pages: pages,
-	}, nil
+	}
+	if o.DataFormat != "" {
+		renderedReport.Data, err = newRenderedData(o, screenshots, timestamp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if o.OutputFormat != messagequeue.OutputFormatPDF {
+		setTabState(tab, tabRendered)
+
+		return &renderedReport, nil
+	}
+
+	document := RenderedDocument{
+		Filename:    documentFilename(o, string(messagequeue.OutputFormatPDF), timestamp),
+		ContentType: "application/pdf",
+	}
+	printDocument, err := e.newPrintDocumentTask(ctx, &document.Data, screenshots, o)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: Document is printed in a tab of its own, so a pooled one keeps the report template.
+	printCtx, cancelPrint := chromedp.NewContext(ctx)
+	defer cancelPrint()
+
+	err = chromedp.Run(printCtx, printDocument)
+	if err != nil {
+		return nil, err
+	}
+
+	renderedReport.Document = &document
+	setTabState(tab, tabRendered)
+
+	return &renderedReport, nil
+}
+
+func setTabState(t *pooledTab, s int32) {
+	if t != nil {
+		atomic.StoreInt32(&t.state, s)
+	}
 }
 
 func (e *CDPEngine) startBrowser() error {
@@ -130,6 +222,51 @@ func (e *CDPEngine) startBrowser() error {
 	return chromedp.Run(e.browserCtx)
 }
 
+// reviveBrowser returns a context.Context of the Chrome instance, which is started again if it had died in the meantime.
+func (e *CDPEngine) reviveBrowser() (context.Context, error) {
+	e.browserMu.Lock()
+	defer e.browserMu.Unlock()
+
+	// NOTE: To ensure stable behavior, we attempt to revive browser process if it had died in the meantime.
+	err := e.browserCtx.Err()
+	if err == context.Canceled {
+		err2 := e.startBrowser()
+		if err2 != nil {
+			return nil, err2
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return e.browserCtx, nil
+}
+
+// openTab opens a tab w/ the report template loaded & initialized.
+func (e *CDPEngine) openTab() (*pooledTab, error) {
+	template, err := e.newRenderReportTemplate(resourceReportTemplate2)
+	if err != nil {
+		return nil, err
+	}
+
+	browserCtx, err := e.reviveBrowser()
+	if err != nil {
+		return nil, err
+	}
+
+	tabCtx, cancelTab := chromedp.NewContext(browserCtx)
+	err = chromedp.Run(tabCtx, e.newInitializeTask(tabCtx, template))
+	if err != nil {
+		cancelTab()
+
+		return nil, err
+	}
+
+	return &pooledTab{
+		ctx:    tabCtx,
+		cancel: cancelTab,
+	}, nil
+}
+
 func (e *CDPEngine) newRenderReportTemplate(r resource) (url.URL, error) {
 	relativeResourcePath := filepath.Join(e.config.ResourcesDirectory, string(r))
 
@@ -149,8 +286,8 @@ func (e *CDPEngine) newRenderReportTemplate(r resource) (url.URL, error) {
 	}, nil
 }
 
-// TODO: Collect resource usage metrics, see `https://chromedevtools.github.io/devtools-protocol/tot/Performance/'.
-func (e *CDPEngine) newScreenshotPagesTask(ctx context.Context, ss *[]*pageScreenshot, p url.URL, o *utils.ShareOptions) chromedp.Action {
+// newInitializeTask loads the report template at p & initializes it.
+func (e *CDPEngine) newInitializeTask(ctx context.Context, p url.URL) chromedp.Action {
 	logger := utils.WithContext(ctx, e.logger)
 
 	navigate := chromedp.ActionFunc(func(ctx context.Context) error {
@@ -182,10 +319,36 @@ func (e *CDPEngine) newScreenshotPagesTask(ctx context.Context, ss *[]*pageScree
 		return nil
 	})
 
+	return chromedp.Tasks{
+		navigate,
+		waitPage,
+		initialize,
+	}
+}
+
+// TODO: Collect resource usage metrics, see `https://chromedevtools.github.io/devtools-protocol/tot/Performance/'.
+// A dashboard or a tile of ref is captured as a single page once it's loaded.
+func (e *CDPEngine) newScreenshotPagesTask(ctx context.Context, ss *[]*pageScreenshot, o *utils.ShareOptions, ref *domain.ReportRef) chromedp.Action {
+	logger := utils.WithContext(ctx, e.logger)
+
+	// NOTE: A tab may have rendered another report, so the previous one is dropped along w/ its config.
+	reset := chromedp.ActionFunc(func(ctx context.Context) error {
+		res := []byte(nil)
+		resetJS := "window.reportRenderer.reset();"
+		err := chromedp.Evaluate(resetJS, &res).Do(ctx)
+		if err != nil {
+			logger.Error("couldn't reset", zap.Error(err))
+
+			return err
+		}
+
+		return nil
+	})
+
 	configure := chromedp.ActionFunc(func(ctx context.Context) error {
 		startedAt := time.Now().UTC()
 
-		loadReport := newReportLoadConfiguration(o)
+		loadReport := newReportLoadConfiguration(o, ref)
 		configJSON, err := json.Marshal(loadReport)
 		if err != nil {
 			logger.Error("couldn't marshal configuration", zap.Error(err))
@@ -244,9 +407,40 @@ func (e *CDPEngine) newScreenshotPagesTask(ctx context.Context, ss *[]*pageScree
 	takeScreenshots := chromedp.ActionFunc(func(ctx context.Context) error {
 		startedAt := time.Now().UTC()
 
+		readiness := (*readinessTracker)(nil)
+		if e.config.Readiness == config.ReadinessSignals {
+			t, err := startReadinessTracker(ctx, e.config)
+			if err != nil {
+				logger.Warn("couldn't track readiness", zap.Error(err))
+			} else {
+				readiness = t
+				defer readiness.stop()
+			}
+		}
+
 		for _, reportPage := range o.Pages {
 			logger := logger.With(zap.String("pageID", reportPage.ID))
 
+			// NOTE: A dashboard or a tile has neither pages to set nor a render event, so it's captured as soon as it's ready.
+			if ref.Type != domain.ReportTypeReport {
+				screenshot, err := e.captureEmbed(ctx, readiness, reportPage)
+				if err != nil {
+					return err
+				}
+
+				*ss = append(*ss, screenshot)
+
+				continue
+			}
+
+			// NOTE: A bookmark may navigate elsewhere, so it's applied before each page is set rather than once per report.
+			if o.BookmarkName != "" {
+				err := e.applyBookmark(ctx, o.BookmarkName)
+				if err != nil {
+					return err
+				}
+			}
+
 			pageIDJSON, err := json.Marshal(reportPage.ID)
 			if err != nil {
 				logger.Error("couldn't marshal page id", zap.Error(err))
@@ -265,8 +459,36 @@ func (e *CDPEngine) newScreenshotPagesTask(ctx context.Context, ss *[]*pageScree
 
 			logger.Debug("navigated to page")
 
-			pageSize := customPageSize{}
+			// NOTE: A page w/o a mobile layout is rendered in the master one, so viewport is sized for a layout actually set.
+			layout := layoutTypeMaster
+			if layoutOf(o) != messagequeue.LayoutMaster {
+				layout, err = e.setLayout(ctx, layoutTypes[layoutOf(o)])
+				if err != nil {
+					return err
+				}
+			}
+
+			// NOTE: Visuals are exported from the page, so data of a single visual is exported before it's embedded alone.
+			data, dataNotAllowed := []*visualData(nil), false
+			if o.DataFormat != "" && o.VisualName != "" {
+				data, dataNotAllowed, err = e.exportData(ctx, reportPage.Name, o.VisualName)
+				if err != nil {
+					return err
+				}
+			}
+
 			getPageSizeJS := "window.reportRenderer.getPageSize();"
+			if o.VisualName != "" {
+				err := e.setVisual(ctx, o.VisualName)
+				if err != nil {
+					return err
+				}
+
+				// NOTE: A visual is embedded alone, so viewport is fit to its size rather than to the page one.
+				getPageSizeJS = "window.reportRenderer.getVisualSize();"
+			}
+
+			pageSize := customPageSize{}
 			err = chromedp.Evaluate(getPageSizeJS, &pageSize, chromedp.EvalAsValue).Do(ctx)
 			if err != nil {
 				logger.Error("couldn't get page size", zap.Error(err))
@@ -288,6 +510,17 @@ func (e *CDPEngine) newScreenshotPagesTask(ctx context.Context, ss *[]*pageScree
 
 			width = width + e.config.ViewportMargin
 
+			// NOTE: A mobile layout has no size of its own, so it's rendered at the size of a phone instead.
+			switch layout {
+			case layoutTypeMobilePortrait:
+				width = e.config.MobileViewportWidth + e.config.ViewportMargin
+				height = e.config.MobileViewportHeight + e.config.ViewportMargin
+
+			case layoutTypeMobileLandscape:
+				width = e.config.MobileViewportHeight + e.config.ViewportMargin
+				height = e.config.MobileViewportWidth + e.config.ViewportMargin
+			}
+
 			err = emulation.SetDeviceMetricsOverride(width, height, e.config.DisplayDensity, false).Do(ctx)
 			if err != nil {
 				logger.Error("couldn't set page size", zap.Error(err))
@@ -297,7 +530,8 @@ func (e *CDPEngine) newScreenshotPagesTask(ctx context.Context, ss *[]*pageScree
 
 			logger.Debug("set viewport size",
 				zap.Int64("width", pageSize.Width),
-				zap.Int64("height", pageSize.Height))
+				zap.Int64("height", pageSize.Height),
+				zap.String("layout", string(layout)))
 
 			exc := []byte(nil)
 			startedAt := time.Now().UTC()
@@ -327,12 +561,17 @@ func (e *CDPEngine) newScreenshotPagesTask(ctx context.Context, ss *[]*pageScree
 			completedIn := time.Now().UTC().Sub(startedAt)
 			logger.Debug("rendered page", zap.ByteString("res", res), zap.Duration("completedIn", completedIn))
 
-			// NOTE: We have to wait for Bing maps visual to fully load since it doesn't respect report rendering completion event.
-			time.Sleep(e.config.ScreenshotDelay)
+			err = e.waitReady(ctx, readiness)
+			if err != nil {
+				return err
+			}
 
 			screenshot := pageScreenshot{
-				pageID:   reportPage.ID,
-				pageName: reportPage.Name,
+				pageID:     reportPage.ID,
+				pageName:   reportPage.Name,
+				visualName: o.VisualName,
+				width:      width,
+				height:     height,
 			}
 			err = chromedp.CaptureScreenshot(&screenshot.rawData).Do(ctx)
 			if err != nil {
@@ -343,6 +582,16 @@ func (e *CDPEngine) newScreenshotPagesTask(ctx context.Context, ss *[]*pageScree
 
 			logger.Debug("captured screenshot")
 
+			if o.DataFormat != "" && o.VisualName == "" {
+				data, dataNotAllowed, err = e.exportData(ctx, reportPage.Name, "")
+				if err != nil {
+					return err
+				}
+			}
+
+			screenshot.data = data
+			screenshot.dataNotAllowed = dataNotAllowed
+
 			*ss = append(*ss, &screenshot)
 		}
 
@@ -353,11 +602,368 @@ func (e *CDPEngine) newScreenshotPagesTask(ctx context.Context, ss *[]*pageScree
 	})
 
 	return chromedp.Tasks{
-		navigate,
-		waitPage,
-		initialize,
+		reset,
 		configure,
 		loadReport,
 		takeScreenshots,
 	}
 }
+
+// captureEmbed captures a dashboard or a tile loaded as page p in a viewport of the default size.
+func (e *CDPEngine) captureEmbed(ctx context.Context, t *readinessTracker, p *utils.PageOptions) (*pageScreenshot, error) {
+	logger := utils.WithContext(ctx, e.logger).With(zap.String("pageID", p.ID))
+
+	width := e.config.DefaultViewportWidth + e.config.ViewportMargin
+	height := e.config.DefaultViewportHeight + e.config.ViewportMargin
+	err := emulation.SetDeviceMetricsOverride(width, height, e.config.DisplayDensity, false).Do(ctx)
+	if err != nil {
+		logger.Error("couldn't set page size", zap.Error(err))
+
+		return nil, err
+	}
+
+	err = e.waitReady(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	screenshot := pageScreenshot{
+		pageID:   p.ID,
+		pageName: p.Name,
+		width:    width,
+		height:   height,
+	}
+	err = chromedp.CaptureScreenshot(&screenshot.rawData).Do(ctx)
+	if err != nil {
+		logger.Error("couldn't capture screenshot", zap.Error(err))
+
+		return nil, err
+	}
+
+	logger.Debug("captured screenshot")
+
+	return &screenshot, nil
+}
+
+// exportData exports summarized data of each visual of an active page, or of a visual of it titled t only.
+// A visual which fails to export is skipped, unless a report doesn't allow export at all, which is returned as notAllowed.
+func (e *CDPEngine) exportData(ctx context.Context, pageName, t string) ([]*visualData, bool, error) {
+	logger := utils.WithContext(ctx, e.logger)
+
+	startedAt := time.Now().UTC()
+
+	titleJSON, err := json.Marshal(t)
+	if err != nil {
+		logger.Error("couldn't marshal visual title", zap.Error(err))
+
+		return nil, false, err
+	}
+
+	exported := []*exportedVisual(nil)
+	exportDataJS := fmt.Sprintf("window.reportRenderer.exportData(%v);", string(titleJSON))
+	err = chromedp.Evaluate(exportDataJS, &exported, evalAwait).Do(ctx)
+	if err != nil {
+		logger.Error("couldn't export data", zap.Error(err))
+
+		return nil, false, err
+	}
+
+	data := []*visualData(nil)
+	for _, v := range exported {
+		if v.Error != nil && v.Error.isExportNotAllowed() {
+			logger.Warn("data export isn't allowed", zap.Error(v.Error), zap.String("visualTitle", v.Title))
+
+			return nil, true, nil
+		}
+
+		if v.Error != nil {
+			logger.Warn("couldn't export visual data", zap.Error(v.Error), zap.String("visualTitle", v.Title))
+
+			continue
+		}
+
+		d := visualData{
+			pageName:    pageName,
+			visualTitle: v.Title,
+			csv:         v.Data,
+		}
+		data = append(data, &d)
+	}
+
+	completedIn := time.Now().UTC().Sub(startedAt)
+	logger.Info("exported data", zap.Duration("completedIn", completedIn), zap.Int("totalVisuals", len(data)))
+
+	return data, false, nil
+}
+
+// waitReady waits till a page is fully drawn after its render event; config.BrowserConfig.ScreenshotDelay is waited for instead if t is nil or fails.
+// NOTE: Render event isn't enough, since Bing maps visual doesn't respect it.
+func (e *CDPEngine) waitReady(ctx context.Context, t *readinessTracker) error {
+	logger := utils.WithContext(ctx, e.logger)
+
+	startedAt := time.Now().UTC()
+	signal := signalDelay
+	if t != nil {
+		s, err := t.wait(ctx)
+		if err != nil && ctx.Err() != nil {
+			return err
+		}
+
+		if err != nil {
+			logger.Warn("couldn't wait for readiness", zap.Error(err))
+		} else {
+			signal = s
+		}
+	}
+
+	if signal == signalDelay {
+		select {
+		case <-time.After(e.config.ScreenshotDelay):
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	completedIn := time.Now().UTC().Sub(startedAt)
+	logger.Info("page is ready", zap.String("signal", string(signal)), zap.Duration("completedIn", completedIn))
+
+	return nil
+}
+
+// applyBookmark applies a report bookmark of name n; a filter of utils.ShareOptions is kept over one of the bookmark.
+func (e *CDPEngine) applyBookmark(ctx context.Context, n string) error {
+	logger := utils.WithContext(ctx, e.logger).With(zap.String("bookmarkName", n))
+
+	nameJSON, err := json.Marshal(n)
+	if err != nil {
+		logger.Error("couldn't marshal bookmark name", zap.Error(err))
+
+		return err
+	}
+
+	res := []byte(nil)
+	exc := []byte(nil)
+	applyBookmarkJS := fmt.Sprintf("window.reportRenderer.applyBookmark(%v);", string(nameJSON))
+	err = tryEvaluate(&res, &exc, applyBookmarkJS, chromedp.EvalAsValue, evalAwait).Do(ctx)
+	if err != nil {
+		details, ok := err.(*runtime.ExceptionDetails)
+		if ok && details.Exception.Type == runtime.TypeObject && details.Exception.Subtype == "" {
+			bookmarkError := pbiError{}
+			err2 := json.Unmarshal(exc, &bookmarkError)
+			if err2 != nil {
+				logger.Error("couldn't unmarshal error", zap.Error(err2))
+
+				return err2
+			}
+
+			logger.Error("couldn't apply bookmark", zap.Error(&bookmarkError))
+
+			return &bookmarkError
+		}
+
+		logger.Error("couldn't apply bookmark", zap.Error(err))
+
+		return err
+	}
+
+	logger.Debug("applied bookmark")
+
+	return nil
+}
+
+// setLayout switches an active page to layout l, or to the master one if the page has no layout l; a layout set is returned.
+func (e *CDPEngine) setLayout(ctx context.Context, l layoutType) (layoutType, error) {
+	logger := utils.WithContext(ctx, e.logger).With(zap.String("layout", string(l)))
+
+	layoutJSON, err := json.Marshal(l)
+	if err != nil {
+		logger.Error("couldn't marshal layout", zap.Error(err))
+
+		return "", err
+	}
+
+	res := layoutType("")
+	exc := []byte(nil)
+	setLayoutJS := fmt.Sprintf("window.reportRenderer.setLayout(%v);", string(layoutJSON))
+	err = tryEvaluate(&res, &exc, setLayoutJS, chromedp.EvalAsValue, evalAwait).Do(ctx)
+	if err != nil {
+		details, ok := err.(*runtime.ExceptionDetails)
+		if ok && details.Exception.Type == runtime.TypeObject && details.Exception.Subtype == "" {
+			layoutError := pbiError{}
+			err2 := json.Unmarshal(exc, &layoutError)
+			if err2 != nil {
+				logger.Error("couldn't unmarshal error", zap.Error(err2))
+
+				return "", err2
+			}
+
+			logger.Error("couldn't set layout", zap.Error(&layoutError))
+
+			return "", &layoutError
+		}
+
+		logger.Error("couldn't set layout", zap.Error(err))
+
+		return "", err
+	}
+
+	if res != l {
+		logger.Info("page has no such layout, using master one", zap.String("activeLayout", string(res)))
+	}
+
+	logger.Debug("set layout")
+
+	return res, nil
+}
+
+// setVisual replaces an active page w/ a single visual of it titled t.
+func (e *CDPEngine) setVisual(ctx context.Context, t string) error {
+	logger := utils.WithContext(ctx, e.logger).With(zap.String("visualName", t))
+
+	titleJSON, err := json.Marshal(t)
+	if err != nil {
+		logger.Error("couldn't marshal visual title", zap.Error(err))
+
+		return err
+	}
+
+	res := []byte(nil)
+	exc := []byte(nil)
+	setVisualJS := fmt.Sprintf("window.reportRenderer.setVisual(%v);", string(titleJSON))
+	err = tryEvaluate(&res, &exc, setVisualJS, chromedp.EvalAsValue, evalAwait).Do(ctx)
+	if err != nil {
+		details, ok := err.(*runtime.ExceptionDetails)
+		if ok && details.Exception.Type == runtime.TypeObject && details.Exception.Subtype == "" {
+			loadingError := pbiError{}
+			err2 := json.Unmarshal(exc, &loadingError)
+			if err2 != nil {
+				logger.Error("couldn't unmarshal error", zap.Error(err2))
+
+				return err2
+			}
+
+			logger.Error("couldn't set visual", zap.Error(&loadingError))
+
+			return &loadingError
+		}
+
+		logger.Error("couldn't set visual", zap.Error(err))
+
+		return err
+	}
+
+	logger.Debug("set visual")
+
+	return nil
+}
+
+// newPrintDocumentTask prints a cover page followed by ss into a PDF, each page of its own size.
+// NOTE: Pages are printed from screenshots rather than from the report itself, as Chrome prints a whole document at once, while a report shows a single page at a time.
+func (e *CDPEngine) newPrintDocumentTask(ctx context.Context, res *[]byte, ss []*pageScreenshot, o *utils.ShareOptions) (chromedp.Action, error) {
+	logger := utils.WithContext(ctx, e.logger)
+
+	t, err := template.ParseFiles(filepath.Join(e.config.ResourcesDirectory, string(resourceDocumentTemplate)))
+	if err != nil {
+		return nil, err
+	}
+
+	generatedAt, _ := activityTime(ctx)
+	d := documentData{
+		ReportName: o.ReportName,
+		Timestamp:  generatedAt.Format("2006-01-02 15:04 MST"),
+		Cover: documentPage{
+			Width:  e.config.DefaultViewportWidth + e.config.ViewportMargin,
+			Height: e.config.DefaultViewportHeight + e.config.ViewportMargin,
+		},
+	}
+	d.Filter = o.FilterString()
+
+	for _, s := range ss {
+		p := documentPage{
+			Name:     s.pageName,
+			Width:    s.width,
+			Height:   s.height,
+			ImageURL: template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(s.rawData)),
+		}
+		d.Pages = append(d.Pages, &p)
+	}
+
+	if len(d.Pages) != 0 {
+		d.Cover.Width, d.Cover.Height = d.Pages[0].Width, d.Pages[0].Height
+	}
+
+	html := bytes.Buffer{}
+	err = t.Execute(&html, &d)
+	if err != nil {
+		return nil, err
+	}
+
+	navigate := withTimeout(chromedp.Navigate("about:blank"), e.config.MinActionTimeout)
+
+	setContent := chromedp.ActionFunc(func(ctx context.Context) error {
+		tree, err := page.GetFrameTree().Do(ctx)
+		if err != nil {
+			logger.Error("couldn't get frame tree", zap.Error(err))
+
+			return err
+		}
+
+		err = page.SetDocumentContent(tree.Frame.ID, html.String()).Do(ctx)
+		if err != nil {
+			logger.Error("couldn't set document content", zap.Error(err))
+
+			return err
+		}
+
+		res := []byte(nil)
+		decodeImagesJS := "Promise.all(Array.from(document.images).map((i) => i.decode()));"
+		err = chromedp.Evaluate(decodeImagesJS, &res, evalAwait).Do(ctx)
+		if err != nil {
+			logger.Error("couldn't decode images", zap.Error(err))
+
+			return err
+		}
+
+		return nil
+	})
+
+	printPDF := chromedp.ActionFunc(func(ctx context.Context) error {
+		startedAt := time.Now().UTC()
+
+		err := emulation.ClearDeviceMetricsOverride().Do(ctx)
+		if err != nil {
+			logger.Error("couldn't reset page size", zap.Error(err))
+
+			return err
+		}
+
+		// NOTE: Page sizes are set by named `@page' rules of the template, so CSS page size must be preferred.
+		data, _, err := page.PrintToPDF().
+			WithPrintBackground(true).
+			WithPreferCSSPageSize(true).
+			WithMarginTop(0).
+			WithMarginBottom(0).
+			WithMarginLeft(0).
+			WithMarginRight(0).
+			Do(ctx)
+		if err != nil {
+			logger.Error("couldn't print document", zap.Error(err))
+
+			return err
+		}
+
+		*res = data
+
+		completedIn := time.Now().UTC().Sub(startedAt)
+		logger.Info("printed document", zap.Duration("completedIn", completedIn), zap.Int("totalPages", len(ss)), zap.Int("size", len(data)))
+
+		return nil
+	})
+
+	return chromedp.Tasks{
+		navigate,
+		setContent,
+		printPDF,
+	}, nil
+}
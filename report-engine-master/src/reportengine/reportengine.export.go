@@ -0,0 +1,376 @@
+package reportengine
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+
+)
+
+// exportStatus is a status of an export job of Power BI ExportTo File API.
+type exportStatus string
+
+const (
+	exportNotStarted exportStatus = "NotStarted"
+	exportRunning    exportStatus = "Running"
+	exportSucceeded  exportStatus = "Succeeded"
+	exportFailed     exportStatus = "Failed"
+)
+
+// exportFormats maps messagequeue.OutputFormat to a format of ExportTo File API along w/ a content type of its file.
+var exportFormats = map[messagequeue.OutputFormat]struct {
+	format      string
+	contentType string
+}{
+	messagequeue.OutputFormatPNG:  {"PNG", "image/png"},
+	messagequeue.OutputFormatPDF:  {"PDF", "application/pdf"},
+	messagequeue.OutputFormatXLSX: {"XLSX", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+}
+
+// exportRequest is a body of POST /reports/{reportId}/ExportTo.
+type exportRequest struct {
+	Format                       string                        `json:"format"`
+	PaginatedReportConfiguration *paginatedReportConfiguration `json:"paginatedReportConfiguration,omitempty"`
+}
+
+type paginatedReportConfiguration struct {
+	ParameterValues []*parameterValue `json:"parameterValues,omitempty"`
+}
+
+type parameterValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// export is an export job as returned by ExportTo File API.
+type export struct {
+	ID                    string       `json:"id"`
+	Status                exportStatus `json:"status"`
+	PercentComplete       int          `json:"percentComplete"`
+	ResourceFileExtension string       `json:"resourceFileExtension"`
+}
+
+// apiError is an error body returned by Power BI REST API.
+type apiError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// ExportReportEngine is a ReportEngine exporting paginated reports via Power BI ExportTo File API, since they can't be embedded.
+type ExportReportEngine struct {
+	config *config.ExportConfig
+	apiURL string
+	client *http.Client
+	logger *zap.Logger
+}
+
+// NewExportReportEngine creates an ExportReportEngine calling Power BI REST API at apiURL, so a local stand-in can be used instead of it.
+func NewExportReportEngine(c *config.ExportConfig, apiURL string, client *http.Client, l *zap.Logger) *ExportReportEngine {
+	return &ExportReportEngine{
+		config: c,
+		apiURL: strings.TrimSuffix(apiURL, "/"),
+		client: client,
+		logger: l,
+	}
+}
+
+// NewContext creates a context.Context limited by config.ExportConfig.Timeout; unlike CDPEngine, it holds no tab.
+func (e *ExportReportEngine) NewContext(_ *utils.ShareOptions) (context.Context, context.CancelFunc, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.config.Timeout)
+
+	return ctx, cancel, nil
+}
+
+// RenderReport exports a paginated report to a document for messagequeue.OutputFormatPDF & messagequeue.OutputFormatXLSX, or to images of its pages otherwise.
+// NOTE: Export is limited by config.ExportConfig.Timeout, as well as by a deadline of ctx, if any.
+func (e *ExportReportEngine) RenderReport(ctx context.Context, o *utils.ShareOptions) (*RenderedReport, error) {
+	l := utils.WithContext(ctx, e.logger)
+
+	ref, err := domain.ParseReportID(o.ReportID)
+	if err != nil {
+		return nil, err
+	}
+
+	outputFormat := o.OutputFormat
+	if outputFormat == "" {
+		outputFormat = messagequeue.OutputFormatPNG
+	}
+
+	f, ok := exportFormats[outputFormat]
+	if !ok {
+		return nil, fmt.Errorf("unsupported output format: %v", outputFormat)
+	}
+
+	exportCtx, cancelExport := context.WithTimeout(ctx, e.config.Timeout)
+	defer cancelExport()
+
+	ex, interval, err := e.startExport(exportCtx, o, ref.ID, f.format)
+	if err != nil {
+		l.Error("couldn't start export", zap.Error(err))
+
+		return nil, err
+	}
+
+	l = l.With(zap.String("exportID", ex.ID))
+	l.Debug("started export", zap.String("format", f.format))
+
+	ex, err = e.waitExport(exportCtx, o, ref.ID, ex, interval)
+	if err != nil {
+		l.Error("couldn't export report", zap.Error(err))
+
+		return nil, err
+	}
+
+	data, err := e.downloadExport(exportCtx, o, ref.ID, ex.ID)
+	if err != nil {
+		l.Error("couldn't download export", zap.Error(err))
+
+		return nil, err
+	}
+
+	l.Debug("exported report", zap.Int("size", len(data)), zap.String("extension", ex.ResourceFileExtension))
+
+	timestamp := timestamp(ctx)
+	renderedReport := RenderedReport{
+		ID:   o.ReportID,
+		Name: o.ReportName,
+	}
+	if outputFormat != messagequeue.OutputFormatPNG {
+		renderedReport.Document = &RenderedDocument{
+			Filename:    documentFilename(o, string(outputFormat), timestamp),
+			ContentType: f.contentType,
+			Data:        data,
+		}
+
+		return &renderedReport, nil
+	}
+
+	renderedReport.Pages, err = exportedPages(o, ex, data, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &renderedReport, nil
+}
+
+// startExport posts an export job of a report; an interval to poll it at is returned along w/ it.
+func (e *ExportReportEngine) startExport(ctx context.Context, o *utils.ShareOptions, reportID, format string) (*export, time.Duration, error) {
+	body := exportRequest{
+		Format: format,
+	}
+	if len(o.ReportParameters) != 0 {
+		c := paginatedReportConfiguration{}
+		for _, p := range o.ReportParameters {
+			c.ParameterValues = append(c.ParameterValues, &parameterValue{
+				Name:  p.Name,
+				Value: p.Value,
+			})
+		}
+
+		body.PaginatedReportConfiguration = &c
+	}
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ex := export{}
+	h, err := e.do(ctx, o, http.MethodPost, fmt.Sprintf("/reports/%v/ExportTo", reportID), bytes.NewReader(b), &ex, http.StatusAccepted)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &ex, e.retryAfter(h), nil
+}
+
+// waitExport polls an export job every interval till it's done.
+// NOTE: Power BI responds w/ 202 while an export is running & w/ 200 once it's done.
+func (e *ExportReportEngine) waitExport(ctx context.Context, o *utils.ShareOptions, reportID string, ex *export, interval time.Duration) (*export, error) {
+	for {
+		switch ex.Status {
+		case exportSucceeded:
+			return ex, nil
+
+		case exportFailed:
+			return nil, &pbiError{
+				Message:   "export failed",
+				ErrorCode: string(exportFailed),
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case <-time.After(interval):
+		}
+
+		next := export{}
+		h, err := e.do(ctx, o, http.MethodGet, fmt.Sprintf("/reports/%v/exports/%v", reportID, ex.ID), nil, &next, http.StatusOK, http.StatusAccepted)
+		if err != nil {
+			return nil, err
+		}
+
+		ex = &next
+		interval = e.retryAfter(h)
+	}
+}
+
+// retryAfter returns an interval of Retry-After of h if set, config.ExportConfig.PollInterval otherwise.
+func (e *ExportReportEngine) retryAfter(h http.Header) time.Duration {
+	if s, err := strconv.Atoi(h.Get("Retry-After")); err == nil && s > 0 {
+		return time.Duration(s) * time.Second
+	}
+
+	return e.config.PollInterval
+}
+
+// downloadExport retrieves a file of a succeeded export job.
+func (e *ExportReportEngine) downloadExport(ctx context.Context, o *utils.ShareOptions, reportID, exportID string) ([]byte, error) {
+	b := bytes.Buffer{}
+	_, err := e.do(ctx, o, http.MethodGet, fmt.Sprintf("/reports/%v/exports/%v/file", reportID, exportID), nil, &b, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// do sends a request to Power BI REST API on behalf of a user sharing a report; a body of a response w/ one of statuses is decoded into res, or copied if it's a bytes.Buffer.
+// NOTE: A failed request is reported as pbiError, so RetryStrategy tells authorization errors from transient ones.
+func (e *ExportReportEngine) do(ctx context.Context, o *utils.ShareOptions, method, resource string, body io.Reader, res interface{}, statuses ...int) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, method, e.apiURL+resource, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set(constants.HTTPHeaderAuthorization, constants.BearerTokenType+o.AccessToken)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	ok := false
+	for _, s := range statuses {
+		ok = ok || resp.StatusCode == s
+	}
+
+	if !ok {
+		pe := pbiError{
+			ErrorCode: strconv.Itoa(resp.StatusCode),
+			Message:   resp.Status,
+		}
+		ae := apiError{}
+		if json.NewDecoder(resp.Body).Decode(&ae) == nil && ae.Error.Code != "" {
+			pe.DetailedMessage = fmt.Sprintf("%v: %v", ae.Error.Code, ae.Error.Message)
+		}
+
+		return nil, &pe
+	}
+
+	if b, ok := res.(*bytes.Buffer); ok {
+		_, err = io.Copy(b, resp.Body)
+	} else {
+		err = json.NewDecoder(resp.Body).Decode(res)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Header, nil
+}
+
+// exportedPages makes pages of a PNG export; Power BI returns a ZIP archive of PNG images if a report has several pages.
+func exportedPages(o *utils.ShareOptions, ex *export, data []byte, timestamp string) ([]*RenderedPage, error) {
+	pageID, pageName := o.ReportID, o.ReportName
+	if len(o.Pages) != 0 {
+		pageID, pageName = o.Pages[0].ID, o.Pages[0].Name
+	}
+
+	if !strings.EqualFold(ex.ResourceFileExtension, ".zip") {
+		p := RenderedPage{
+			ID:        pageID,
+			Name:      pageName,
+			Filename:  pageFilename(o, pageName, "", timestamp),
+			ImageData: data,
+		}
+
+		return []*RenderedPage{&p}, nil
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: Images are named after page numbers, so they're sorted to keep pages in order.
+	files := []*zip.File(nil)
+	for _, f := range r.File {
+		if strings.EqualFold(path.Ext(f.Name), ".png") {
+			files = append(files, f)
+		}
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return naturalLess(files[i].Name, files[j].Name)
+	})
+
+	pages := []*RenderedPage(nil)
+	for i, f := range files {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		name := fmt.Sprintf("%v %v", pageName, i+1)
+		p := RenderedPage{
+			ID:        fmt.Sprintf("%v/%v", pageID, i+1),
+			Name:      name,
+			Filename:  pageFilename(o, name, "", timestamp),
+			ImageData: b,
+		}
+		pages = append(pages, &p)
+	}
+
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("no images in export")
+	}
+
+	return pages, nil
+}
+
+// naturalLess compares names by length first, so "10.png" goes after "9.png".
+func naturalLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+
+	return a < b
+}
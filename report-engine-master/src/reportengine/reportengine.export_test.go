@@ -0,0 +1,380 @@
+package reportengine
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+)
+
+// newTestZip creates a ZIP archive of files named names, each holding its name.
+func newTestZip(t *testing.T, names ...string) []byte {
+	b := bytes.Buffer{}
+	w := zip.NewWriter(&b)
+	for _, n := range names {
+		f, err := w.Create(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = f.Write([]byte(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b.Bytes()
+}
+
+func TestExportReportEngineRenderReport(t *testing.T) {
+	tests := []struct {
+		name         string
+		outputFormat messagequeue.OutputFormat
+		parameters   []*utils.ReportParameterOptions
+		startStatus  int
+		statuses     []exportStatus
+		extension    string
+		file         []byte
+		wantFormat   string
+		wantPolls    int
+		wantDocument string
+		wantPages    []string
+		wantData     []string
+		wantErrCode  string
+		wantAuthErr  bool
+	}{
+		{
+			name:         "document",
+			outputFormat: messagequeue.OutputFormatPDF,
+			parameters: []*utils.ReportParameterOptions{
+				{Name: "Year", Value: "2026"},
+			},
+			statuses:     []exportStatus{exportRunning, exportSucceeded},
+			extension:    ".pdf",
+			file:         []byte("%PDF-1.7"),
+			wantFormat:   "PDF",
+			wantPolls:    2,
+			wantDocument: "application/pdf",
+			wantData:     []string{"%PDF-1.7"},
+		},
+		{
+			name:       "image of a single page",
+			statuses:   []exportStatus{exportNotStarted, exportRunning, exportSucceeded},
+			extension:  ".png",
+			file:       []byte("png"),
+			wantFormat: "PNG",
+			wantPolls:  3,
+			wantPages:  []string{"Sales"},
+			wantData:   []string{"png"},
+		},
+		{
+			name:         "archive of images of pages",
+			outputFormat: messagequeue.OutputFormatPNG,
+			statuses:     []exportStatus{exportSucceeded},
+			extension:    ".zip",
+			file:         newTestZip(t, "Sales/2.png", "Sales/10.png", "Sales/1.png", "Sales/README.txt"),
+			wantFormat:   "PNG",
+			wantPolls:    1,
+			wantPages:    []string{"Sales 1", "Sales 2", "Sales 3"},
+			wantData:     []string{"Sales/1.png", "Sales/2.png", "Sales/10.png"},
+		},
+		{
+			name:        "failed export",
+			statuses:    []exportStatus{exportRunning, exportFailed},
+			wantFormat:  "PNG",
+			wantPolls:   2,
+			wantErrCode: string(exportFailed),
+		},
+		{
+			name:        "expired token",
+			startStatus: http.StatusUnauthorized,
+			wantFormat:  "PNG",
+			wantErrCode: "401",
+			wantAuthErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := exportRequest{}
+			polls := 0
+			mux := http.NewServeMux()
+			mux.HandleFunc("/reports/r1/ExportTo", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.Header.Get(constants.HTTPHeaderAuthorization) != constants.BearerTokenType+"token" {
+					t.Errorf("unexpected %v %v w/ %q", r.Method, r.URL, r.Header.Get(constants.HTTPHeaderAuthorization))
+				}
+
+				err := json.NewDecoder(r.Body).Decode(&started)
+				if err != nil {
+					t.Error(err)
+				}
+
+				if tt.startStatus != 0 {
+					w.WriteHeader(tt.startStatus)
+					_, _ = w.Write([]byte(`{"error":{"code":"TokenExpired","message":"Access token has expired"}}`))
+
+					return
+				}
+
+				w.WriteHeader(http.StatusAccepted)
+				_ = json.NewEncoder(w).Encode(&export{ID: "e1", Status: exportNotStarted})
+			})
+			mux.HandleFunc("/reports/r1/exports/e1", func(w http.ResponseWriter, r *http.Request) {
+				s := tt.statuses[polls]
+				polls++
+
+				// NOTE: Power BI responds w/ 202 till an export is done.
+				if s == exportNotStarted || s == exportRunning {
+					w.WriteHeader(http.StatusAccepted)
+				}
+
+				_ = json.NewEncoder(w).Encode(&export{ID: "e1", Status: s, ResourceFileExtension: tt.extension})
+			})
+			mux.HandleFunc("/reports/r1/exports/e1/file", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(tt.file)
+			})
+
+			s := httptest.NewServer(mux)
+			defer s.Close()
+
+			c := config.ExportConfig{
+				PollInterval: time.Millisecond,
+				Timeout:      5 * time.Second,
+			}
+			e := NewExportReportEngine(&c, s.URL+"/", s.Client(), zap.NewNop())
+			o := utils.ShareOptions{
+				AccessToken:      "token",
+				ReportID:         "paginated:r1",
+				ReportName:       "Sales",
+				OutputFormat:     tt.outputFormat,
+				ReportParameters: tt.parameters,
+			}
+
+			ctx, cancel, err := e.NewContext(&o)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer cancel()
+
+			r, err := e.RenderReport(ctx, &o)
+
+			if started.Format != tt.wantFormat {
+				t.Errorf("format = %v, want %v", started.Format, tt.wantFormat)
+			}
+
+			if polls != tt.wantPolls {
+				t.Errorf("polled %v times, want %v", polls, tt.wantPolls)
+			}
+
+			if tt.wantErrCode != "" {
+				pe := (*pbiError)(nil)
+				if !errors.As(err, &pe) {
+					t.Fatalf("RenderReport() error = %v, want pbiError", err)
+				}
+
+				if pe.ErrorCode != tt.wantErrCode || pe.isAuthError() != tt.wantAuthErr {
+					t.Errorf("RenderReport() error = %v, isAuthError %v, want %v, %v", pe, pe.isAuthError(), tt.wantErrCode, tt.wantAuthErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(tt.parameters) != 0 {
+				want := []*parameterValue(nil)
+				for _, p := range tt.parameters {
+					want = append(want, &parameterValue{Name: p.Name, Value: p.Value})
+				}
+
+				if started.PaginatedReportConfiguration == nil || !reflect.DeepEqual(started.PaginatedReportConfiguration.ParameterValues, want) {
+					t.Errorf("paginatedReportConfiguration = %+v, want %+v", started.PaginatedReportConfiguration, want)
+				}
+			}
+
+			data := []string(nil)
+			if tt.wantDocument != "" {
+				if r.Document == nil || r.Document.ContentType != tt.wantDocument || len(r.Pages) != 0 {
+					t.Fatalf("RenderReport() = %+v, want a document of %v", r, tt.wantDocument)
+				}
+
+				data = append(data, string(r.Document.Data))
+			}
+
+			pages := []string(nil)
+			for _, p := range r.Pages {
+				pages = append(pages, p.Name)
+				data = append(data, string(p.ImageData))
+			}
+
+			if !reflect.DeepEqual(pages, tt.wantPages) {
+				t.Errorf("pages = %q, want %q", pages, tt.wantPages)
+			}
+
+			if !reflect.DeepEqual(data, tt.wantData) {
+				t.Errorf("data = %q, want %q", data, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestExportReportEngineRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		want       time.Duration
+	}{
+		{
+			name: "not set",
+			want: 5 * time.Second,
+		},
+		{
+			name:       "seconds",
+			retryAfter: "30",
+			want:       30 * time.Second,
+		},
+		{
+			name:       "zero",
+			retryAfter: "0",
+			want:       5 * time.Second,
+		},
+		{
+			name:       "HTTP date",
+			retryAfter: "Fri, 16 Oct 2026 09:00:00 GMT",
+			want:       5 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := config.ExportConfig{
+				PollInterval: 5 * time.Second,
+			}
+			e := NewExportReportEngine(&c, "", nil, zap.NewNop())
+
+			h := http.Header{}
+			if tt.retryAfter != "" {
+				h.Set("Retry-After", tt.retryAfter)
+			}
+
+			if got := e.retryAfter(h); got != tt.want {
+				t.Errorf("retryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportedPages(t *testing.T) {
+	tests := []struct {
+		name      string
+		pages     []*utils.PageOptions
+		extension string
+		data      []byte
+		wantIDs   []string
+		wantNames []string
+		wantData  []string
+		wantErr   bool
+	}{
+		{
+			name:      "single image named after a report",
+			extension: ".png",
+			data:      []byte("png"),
+			wantIDs:   []string{"paginated:r1"},
+			wantNames: []string{"Sales"},
+			wantData:  []string{"png"},
+		},
+		{
+			name:      "single image named after a page",
+			pages:     []*utils.PageOptions{{ID: "p1", Name: "Summary"}},
+			extension: ".png",
+			data:      []byte("png"),
+			wantIDs:   []string{"p1"},
+			wantNames: []string{"Summary"},
+			wantData:  []string{"png"},
+		},
+		{
+			name:      "archive sorted by page number",
+			extension: ".ZIP",
+			data:      newTestZip(t, "10.png", "9.PNG", "1.png", "notes.txt"),
+			wantIDs:   []string{"paginated:r1/1", "paginated:r1/2", "paginated:r1/3"},
+			wantNames: []string{"Sales 1", "Sales 2", "Sales 3"},
+			wantData:  []string{"1.png", "9.PNG", "10.png"},
+		},
+		{
+			name:      "archive w/o images",
+			extension: ".zip",
+			data:      newTestZip(t, "notes.txt"),
+			wantErr:   true,
+		},
+		{
+			name:      "not an archive",
+			extension: ".zip",
+			data:      []byte("png"),
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := utils.ShareOptions{
+				ReportID:   "paginated:r1",
+				ReportName: "Sales",
+				Pages:      tt.pages,
+			}
+			ex := export{
+				ResourceFileExtension: tt.extension,
+			}
+
+			pages, err := exportedPages(&o, &ex, tt.data, "2026-10-17 09.00")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("exportedPages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			ids, names, data := []string(nil), []string(nil), []string(nil)
+			for _, p := range pages {
+				ids = append(ids, p.ID)
+				names = append(names, p.Name)
+				data = append(data, string(p.ImageData))
+
+				if p.Filename != pageFilename(&o, p.Name, "", "2026-10-17 09.00") {
+					t.Errorf("Filename = %v", p.Filename)
+				}
+			}
+
+			if !reflect.DeepEqual(ids, tt.wantIDs) || !reflect.DeepEqual(names, tt.wantNames) || !reflect.DeepEqual(data, tt.wantData) {
+				t.Errorf("exportedPages() = %q, %q, %q, want %q, %q, %q", ids, names, data, tt.wantIDs, tt.wantNames, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"1.png", "2.png", true},
+		{"2.png", "10.png", true},
+		{"10.png", "9.png", false},
+		{"10.png", "11.png", true},
+		{"1.png", "1.png", false},
+	}
+	for _, tt := range tests {
+		if got := naturalLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("naturalLess(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package reportengine
 
 import (
+	"bytes"
 	"context"
+	"image/png"
+	"net/http"
 
+	"go.uber.org/zap"
 
 )
 
@@ -18,23 +22,107 @@ func SetDefaultReportEngine(e ReportEngine) {
 	defaultReportEngine = e
 }
 
+// NewReportEngine chains ReportEngine-s around e as every entrypoint uses them: rendered pages are cached (if enabled), paginated reports are exported instead & images are processed for destinations.
+// NOTE: r is used only if cache is enabled.
+func NewReportEngine(e ReportEngine, c *config.ReportEngineConfig, r DatasetRefreshResolver, l *zap.Logger) (ReportEngine, error) {
+	reportEngine := e
+	if c.ImageCache.Enable {
+		imageCache, err := NewDiskPageCache(c.ImageCache, l)
+		if err != nil {
+			return nil, err
+		}
+
+		reportEngine = NewCachedReportEngine(e, imageCache, r, l)
+	}
+
+	// NOTE: Paginated reports can't be embedded, so they're exported by Power BI & never cached.
+	exportEngine := NewExportReportEngine(c.Export, c.PowerBiClient.APIURL, &http.Client{}, l)
+	reportEngine = NewTypedReportEngine(reportEngine, map[domain.ReportType]ReportEngine{
+		domain.ReportTypePaginated: exportEngine,
+	})
+
+	// NOTE: Images are processed after caching, so cached ones are kept as rendered & processed for each destination anew.
+	return NewProcessedReportEngine(reportEngine, e, c.Image, l), nil
+}
+
 // RenderedReport holds report rendering result.
 type RenderedReport struct {
 	ID    string
 	Name  string
 	Pages []*RenderedPage
+	// Document is set for messagequeue.OutputFormatPDF only; it holds all of Pages, which are kept for page links.
+	Document *RenderedDocument
+	// Data is set if utils.ShareOptions.DataFormat is.
+	Data *RenderedData
 }
 
 // RenderedPage holds page rendering result.
 type RenderedPage struct {
-	ID        string
-	Name      string
-	Filename  string
+	ID   string
+	Name string
+	// VisualName is set if a single visual of the page is rendered.
+	VisualName string
+	Filename   string
+	// ImageData is a PNG image as rendered.
 	ImageData []byte
+	// Images are post-processed for destinations by client ID; a destination w/o one gets ImageData.
+	Images map[string]*RenderedImage
+}
+
+// Image returns an image of a page to post to a destination of clientID.
+func (p *RenderedPage) Image(clientID string) *RenderedImage {
+	i, ok := p.Images[clientID]
+	if ok {
+		return i
+	}
+
+	// NOTE: A page is rendered as PNG, so its dimensions are read from a header of it; they're 0 if it can't be read.
+	ic, _ := png.DecodeConfig(bytes.NewReader(p.ImageData))
+
+	return &RenderedImage{
+		Filename:    p.Filename,
+		ContentType: "image/png",
+		Data:        p.ImageData,
+		Width:       ic.Width,
+		Height:      ic.Height,
+	}
+}
+
+// RenderedImage is an image of a page post-processed for a destination.
+type RenderedImage struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+	// Width & Height are in pixels; they're 0 if unknown.
+	Width  int
+	Height int
+}
+
+// RenderedDocument holds pages rendered into a single file.
+type RenderedDocument struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// RenderedData holds summarized data of visuals exported along w/ pages.
+type RenderedData struct {
+	// Files are CSV files of each visual, or a single ZIP archive holding all of them.
+	Files []*RenderedFile
+	// NotAllowed is set if a report doesn't allow data export, so pages are posted w/o data.
+	NotAllowed bool
+}
+
+// RenderedFile is a file posted along w/ pages.
+type RenderedFile struct {
+	Filename    string
+	ContentType string
+	Data        []byte
 }
 
-// ReportEngine renders reports to images.
+// ReportEngine renders reports to images or documents.
 type ReportEngine interface {
-	NewContext() (context.Context, context.CancelFunc, error)
+	// NewContext creates a context.Context suitable to render a report of o.
+	NewContext(o *utils.ShareOptions) (context.Context, context.CancelFunc, error)
 	RenderReport(ctx context.Context, o *utils.ShareOptions) (*RenderedReport, error)
 }
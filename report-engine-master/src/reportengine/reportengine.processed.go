@@ -0,0 +1,330 @@
+package reportengine
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"math"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+	"go.uber.org/zap"
+
+)
+
+const (
+	// minImageQuality is the lowest quality a lossy image is compressed w/ to fit a size limit; it's downscaled beyond it.
+	minImageQuality  = 50
+	imageQualityStep = 10
+	// downscaleRatio shrinks each dimension of an image which doesn't fit a size limit.
+	downscaleRatio     = 0.75
+	maxProcessAttempts = 10
+)
+
+var imageFormats = map[config.ImageFormat]struct {
+	contentType string
+	extension   string
+}{
+	config.ImagePNG:  {"image/png", ".png"},
+	config.ImageJPEG: {"image/jpeg", ".jpg"},
+	config.ImageWebP: {"image/webp", ".webp"},
+}
+
+// NOTE: JPEG has no alpha channel, so transparent parts are filled w/ white rather than black.
+const convertImageJS = `(async (a) => {
+  const i = new Image();
+  i.src = "data:image/png;base64," + a.data;
+  await i.decode();
+
+  const c = document.createElement("canvas");
+  c.width = a.width;
+  c.height = a.height;
+
+  const x = c.getContext("2d");
+  if (a.contentType === "image/jpeg") {
+    x.fillStyle = "#fff";
+    x.fillRect(0, 0, c.width, c.height);
+  }
+
+  x.imageSmoothingQuality = "high";
+  x.drawImage(i, 0, 0, c.width, c.height);
+
+  return c.toDataURL(a.contentType, a.quality / 100);
+})(%s);`
+
+// ProcessedReportEngine is a ReportEngine post-processing page images for each destination a report is posted to, so each of them gets an image of a format & size it handles well.
+type ProcessedReportEngine struct {
+	engine  ReportEngine
+	browser ReportEngine
+	config  *config.ImageConfig
+	logger  *zap.Logger
+}
+
+// NewProcessedReportEngine creates a ProcessedReportEngine post-processing pages rendered by e; a tab of browser converts images of reports rendered w/o one, e.g. exported ones.
+func NewProcessedReportEngine(e, browser ReportEngine, c *config.ImageConfig, l *zap.Logger) *ProcessedReportEngine {
+	return &ProcessedReportEngine{
+		engine:  e,
+		browser: browser,
+		config:  c,
+		logger:  l,
+	}
+}
+
+// NewContext creates a context.Context of the underlying ReportEngine.
+func (e *ProcessedReportEngine) NewContext(o *utils.ShareOptions) (context.Context, context.CancelFunc, error) {
+	return e.engine.NewContext(o)
+}
+
+// RenderReport adds RenderedPage.Images for destinations w/ a config.ImageProfileConfig which changes an image; RenderedPage.ImageData is kept as rendered.
+// NOTE: Images are converted by Chrome in a tab of its own, opened next to a tab of ctx, if any, or in a tab of the browser held while converting.
+func (e *ProcessedReportEngine) RenderReport(ctx context.Context, o *utils.ShareOptions) (*RenderedReport, error) {
+	l := utils.WithContext(ctx, e.logger)
+
+	renderedReport, err := e.engine.RenderReport(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: A document is posted instead of pages, so they aren't processed.
+	if renderedReport.Document != nil {
+		return renderedReport, nil
+	}
+
+	c := imageConverter{
+		parent: ctx,
+		open: func() (context.Context, context.CancelFunc, error) {
+			return e.browser.NewContext(o)
+		},
+	}
+	defer c.close()
+
+	for _, clientID := range destinations(o) {
+		pr, ok := e.config.Profiles[clientID]
+		if !ok {
+			continue
+		}
+
+		for _, p := range renderedReport.Pages {
+			i, err := e.processImage(&c, p, pr)
+			if err != nil {
+				l.Error("couldn't process image", zap.Error(err), zap.String("pageID", p.ID), zap.String("clientID", clientID))
+
+				return nil, err
+			}
+
+			if i == nil {
+				continue
+			}
+
+			if p.Images == nil {
+				p.Images = map[string]*RenderedImage{}
+			}
+
+			p.Images[clientID] = i
+			l.Debug("processed image", zap.String("pageID", p.ID), zap.String("clientID", clientID), zap.Int("originalSize", len(p.ImageData)), zap.Int("size", len(i.Data)))
+		}
+	}
+
+	return renderedReport, nil
+}
+
+// processImage converts an image of p to pr; nil is returned if it's fine as is.
+func (e *ProcessedReportEngine) processImage(c converter, p *RenderedPage, pr *config.ImageProfileConfig) (*RenderedImage, error) {
+	ic, err := png.DecodeConfig(bytes.NewReader(p.ImageData))
+	if err != nil {
+		return nil, err
+	}
+
+	w, h := fitDimensions(ic.Width, ic.Height, pr.MaxWidth, pr.MaxHeight)
+	maxSize := pr.MaxSizeKB << 10
+	if pr.Format == config.ImagePNG && !e.config.OptimizePNG && w == ic.Width && h == ic.Height && (maxSize == 0 || len(p.ImageData) <= maxSize) {
+		return nil, nil
+	}
+
+	f := imageFormats[pr.Format]
+	q := pr.Quality
+	for attempt := 1; ; attempt++ {
+		// NOTE: Each attempt starts from the image as rendered, so quality isn't lost twice.
+		d := p.ImageData
+		if pr.Format != config.ImagePNG || w != ic.Width || h != ic.Height {
+			d, err = c.convert(p.ImageData, f.contentType, q, w, h)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if pr.Format == config.ImagePNG && e.config.OptimizePNG {
+			d, err = optimizePNG(d)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if maxSize == 0 || len(d) <= maxSize {
+			return &RenderedImage{
+				Filename:    strings.TrimSuffix(p.Filename, imageFormats[config.ImagePNG].extension) + f.extension,
+				ContentType: f.contentType,
+				Data:        d,
+				Width:       w,
+				Height:      h,
+			}, nil
+		}
+
+		if attempt == maxProcessAttempts {
+			return nil, fmt.Errorf("image exceeds size limit: %v > %v bytes", len(d), maxSize)
+		}
+
+		// NOTE: Quality is lowered first, as text of visuals gets unreadable sooner w/ downscaling.
+		if pr.Format != config.ImagePNG && q > minImageQuality {
+			q -= imageQualityStep
+			if q < minImageQuality {
+				q = minImageQuality
+			}
+
+			continue
+		}
+
+		w, h = fitDimensions(w, h, int(float64(w)*downscaleRatio), int(float64(h)*downscaleRatio))
+	}
+}
+
+// converter re-encodes a PNG image to contentType downscaled to width & height; quality is used for lossy formats only.
+type converter interface {
+	convert(data []byte, contentType string, quality, width, height int) ([]byte, error)
+}
+
+// imageConverter is a converter converting images in a blank tab opened on first use; parent w/o a pooled tab is replaced w/ a context of open then.
+type imageConverter struct {
+	parent       context.Context
+	open         func() (context.Context, context.CancelFunc, error)
+	cancelParent context.CancelFunc
+	ctx          context.Context
+	cancel       context.CancelFunc
+}
+
+func (c *imageConverter) convert(data []byte, contentType string, quality, width, height int) ([]byte, error) {
+	if c.ctx == nil {
+		// NOTE: An exported report holds no tab, so one is acquired only if its images need converting.
+		if pooledTabFromContext(c.parent) == nil {
+			parent, cancelParent, err := c.open()
+			if err != nil {
+				return nil, err
+			}
+
+			c.parent, c.cancelParent = parent, cancelParent
+		}
+
+		c.ctx, c.cancel = chromedp.NewContext(c.parent)
+	}
+
+	arg := struct {
+		Data        string `json:"data"`
+		ContentType string `json:"contentType"`
+		Quality     int    `json:"quality"`
+		Width       int    `json:"width"`
+		Height      int    `json:"height"`
+	}{
+		Data:        base64.StdEncoding.EncodeToString(data),
+		ContentType: contentType,
+		Quality:     quality,
+		Width:       width,
+		Height:      height,
+	}
+	argJSON, err := json.Marshal(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	res := ""
+	err = chromedp.Run(c.ctx, chromedp.Evaluate(fmt.Sprintf(convertImageJS, string(argJSON)), &res, evalAwait))
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: Canvas falls back to PNG for a format it can't encode.
+	prefix := "data:" + contentType + ";base64,"
+	if !strings.HasPrefix(res, prefix) {
+		return nil, fmt.Errorf("couldn't encode image as %v", contentType)
+	}
+
+	return base64.StdEncoding.DecodeString(strings.TrimPrefix(res, prefix))
+}
+
+func (c *imageConverter) close() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+
+	if c.cancelParent != nil {
+		c.cancelParent()
+	}
+}
+
+// optimizePNG recompresses a PNG image w/ the best compression; the image is returned as is if it isn't any smaller.
+func optimizePNG(data []byte) ([]byte, error) {
+	i, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	b := bytes.Buffer{}
+	enc := png.Encoder{
+		CompressionLevel: png.BestCompression,
+	}
+	err = enc.Encode(&b, i)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.Len() >= len(data) {
+		return data, nil
+	}
+
+	return b.Bytes(), nil
+}
+
+// fitDimensions downscales width & height to fit maxWidth & maxHeight keeping aspect ratio; 0 means no limit.
+func fitDimensions(width, height, maxWidth, maxHeight int) (int, int) {
+	s := 1.0
+	if maxWidth > 0 && width > maxWidth {
+		s = float64(maxWidth) / float64(width)
+	}
+
+	if maxHeight > 0 && height > maxHeight {
+		s = math.Min(s, float64(maxHeight)/float64(height))
+	}
+
+	if s == 1 {
+		return width, height
+	}
+
+	return int(math.Max(1, math.Round(float64(width)*s))), int(math.Max(1, math.Round(float64(height)*s)))
+}
+
+// destinations returns client IDs a report is posted to.
+func destinations(o *utils.ShareOptions) []string {
+	ids := []string{o.ClientID}
+	if o.DistributeReportMessage == nil {
+		return ids
+	}
+
+	for _, t := range o.DistributeReportMessage.Targets {
+		found := false
+		for _, id := range ids {
+			if id == t.ClientID {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			ids = append(ids, t.ClientID)
+		}
+	}
+
+	return ids
+}
@@ -0,0 +1,265 @@
+package reportengine
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+)
+
+func TestFitDimensions(t *testing.T) {
+	tests := []struct {
+		name                string
+		width, height       int
+		maxWidth, maxHeight int
+		wantWidth           int
+		wantHeight          int
+	}{
+		{
+			name:       "no limits",
+			width:      1280,
+			height:     720,
+			wantWidth:  1280,
+			wantHeight: 720,
+		},
+		{
+			name:       "fits limits",
+			width:      1280,
+			height:     720,
+			maxWidth:   1280,
+			maxHeight:  720,
+			wantWidth:  1280,
+			wantHeight: 720,
+		},
+		{
+			name:       "too wide",
+			width:      1280,
+			height:     720,
+			maxWidth:   640,
+			wantWidth:  640,
+			wantHeight: 360,
+		},
+		{
+			name:       "too high",
+			width:      720,
+			height:     1280,
+			maxHeight:  640,
+			wantWidth:  360,
+			wantHeight: 640,
+		},
+		{
+			name:       "height limits more than width",
+			width:      1000,
+			height:     1000,
+			maxWidth:   800,
+			maxHeight:  500,
+			wantWidth:  500,
+			wantHeight: 500,
+		},
+		{
+			name:       "rounded",
+			width:      1000,
+			height:     333,
+			maxWidth:   500,
+			wantWidth:  500,
+			wantHeight: 167,
+		},
+		{
+			name:       "at least a pixel",
+			width:      10000,
+			height:     10,
+			maxWidth:   100,
+			wantWidth:  100,
+			wantHeight: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, h := fitDimensions(tt.width, tt.height, tt.maxWidth, tt.maxHeight)
+			if w != tt.wantWidth || h != tt.wantHeight {
+				t.Errorf("fitDimensions() = %v, %v, want %v, %v", w, h, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+// conversion is a call of fakeConverter.
+type conversion struct {
+	contentType   string
+	quality       int
+	width, height int
+}
+
+// fakeConverter "converts" an image into width * height * quality / 100 bytes, but no less than minSize.
+type fakeConverter struct {
+	minSize int
+	calls   []conversion
+}
+
+func (c *fakeConverter) convert(_ []byte, contentType string, quality, width, height int) ([]byte, error) {
+	c.calls = append(c.calls, conversion{contentType, quality, width, height})
+	n := width * height * quality / 100
+	if n < c.minSize {
+		n = c.minSize
+	}
+
+	return make([]byte, n), nil
+}
+
+func TestProcessImage(t *testing.T) {
+	b := bytes.Buffer{}
+	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	for x := 0; x < 200; x++ {
+		img.Set(x, x%100, color.RGBA{R: uint8(x), A: 255})
+	}
+
+	err := png.Encode(&b, img)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rendered := b.Bytes()
+
+	tests := []struct {
+		name            string
+		data            []byte
+		profile         config.ImageProfileConfig
+		optimizePNG     bool
+		minSize         int
+		wantNil         bool
+		wantErr         bool
+		wantContentType string
+		wantFilename    string
+		wantCalls       int
+		wantLast        conversion
+	}{
+		{
+			name:    "kept as rendered",
+			data:    rendered,
+			profile: config.ImageProfileConfig{Format: config.ImagePNG},
+			wantNil: true,
+		},
+		{
+			name:            "optimized",
+			data:            rendered,
+			profile:         config.ImageProfileConfig{Format: config.ImagePNG},
+			optimizePNG:     true,
+			wantContentType: "image/png",
+			wantFilename:    "page.png",
+		},
+		{
+			name:            "downscaled",
+			data:            rendered,
+			profile:         config.ImageProfileConfig{Format: config.ImagePNG, MaxWidth: 100},
+			wantContentType: "image/png",
+			wantFilename:    "page.png",
+			wantCalls:       1,
+			wantLast:        conversion{"image/png", 0, 100, 50},
+		},
+		{
+			name:            "converted",
+			data:            rendered,
+			profile:         config.ImageProfileConfig{Format: config.ImageJPEG, Quality: 85},
+			wantContentType: "image/jpeg",
+			wantFilename:    "page.jpg",
+			wantCalls:       1,
+			wantLast:        conversion{"image/jpeg", 85, 200, 100},
+		},
+		{
+			name:            "quality lowered to fit",
+			data:            rendered,
+			profile:         config.ImageProfileConfig{Format: config.ImageWebP, Quality: 85, MaxSizeKB: 10},
+			wantContentType: "image/webp",
+			wantFilename:    "page.webp",
+			wantCalls:       5,
+			wantLast:        conversion{"image/webp", minImageQuality, 200, 100},
+		},
+		{
+			name:            "downscaled at min quality to fit",
+			data:            rendered,
+			profile:         config.ImageProfileConfig{Format: config.ImageJPEG, Quality: 85, MaxSizeKB: 5},
+			wantContentType: "image/jpeg",
+			wantFilename:    "page.jpg",
+			wantCalls:       7,
+			wantLast:        conversion{"image/jpeg", minImageQuality, 112, 56},
+		},
+		{
+			name:      "never fits",
+			data:      rendered,
+			profile:   config.ImageProfileConfig{Format: config.ImageJPEG, Quality: 85, MaxSizeKB: 1},
+			minSize:   2 << 10,
+			wantErr:   true,
+			wantCalls: maxProcessAttempts,
+		},
+		{
+			name:    "not a PNG",
+			data:    []byte("not a PNG"),
+			profile: config.ImageProfileConfig{Format: config.ImageJPEG, Quality: 85},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := ProcessedReportEngine{
+				config: &config.ImageConfig{
+					OptimizePNG: tt.optimizePNG,
+				},
+			}
+			c := fakeConverter{
+				minSize: tt.minSize,
+			}
+			p := RenderedPage{
+				Filename:  "page.png",
+				ImageData: tt.data,
+			}
+
+			i, err := e.processImage(&c, &p, &tt.profile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("processImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if len(c.calls) != tt.wantCalls {
+				t.Errorf("converted %v times, want %v", len(c.calls), tt.wantCalls)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if (i == nil) != tt.wantNil {
+				t.Fatalf("processImage() = %v, wantNil %v", i, tt.wantNil)
+			}
+
+			if i == nil {
+				return
+			}
+
+			if i.ContentType != tt.wantContentType || i.Filename != tt.wantFilename {
+				t.Errorf("processImage() = %v, %v, want %v, %v", i.ContentType, i.Filename, tt.wantContentType, tt.wantFilename)
+			}
+
+			if tt.profile.MaxSizeKB != 0 && len(i.Data) > tt.profile.MaxSizeKB<<10 {
+				t.Errorf("size = %v, want at most %v", len(i.Data), tt.profile.MaxSizeKB<<10)
+			}
+
+			if tt.wantCalls != 0 && c.calls[len(c.calls)-1] != tt.wantLast {
+				t.Errorf("last conversion = %+v, want %+v", c.calls[len(c.calls)-1], tt.wantLast)
+			}
+
+			wantWidth, wantHeight := 200, 100
+			if tt.wantCalls != 0 {
+				wantWidth, wantHeight = tt.wantLast.width, tt.wantLast.height
+			}
+
+			if i.Width != wantWidth || i.Height != wantHeight {
+				t.Errorf("dimensions = %v, %v, want %v, %v", i.Width, i.Height, wantWidth, wantHeight)
+			}
+
+			if tt.optimizePNG && len(i.Data) > len(tt.data) {
+				t.Errorf("optimized size = %v, want at most %v", len(i.Data), len(tt.data))
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package reportengine
+
+import (
+	"context"
+
+
+)
+
+// TypedReportEngine is a ReportEngine rendering each kind of report by a ReportEngine of its own, e.g. paginated reports by ExportReportEngine.
+type TypedReportEngine struct {
+	engine  ReportEngine
+	engines map[domain.ReportType]ReportEngine
+}
+
+// NewTypedReportEngine creates a TypedReportEngine rendering kinds of reports w/ engines; the rest of them are rendered by e.
+func NewTypedReportEngine(e ReportEngine, engines map[domain.ReportType]ReportEngine) *TypedReportEngine {
+	return &TypedReportEngine{
+		engine:  e,
+		engines: engines,
+	}
+}
+
+// NewContext creates a context.Context of an engine of a report kind, so e.g. an export doesn't hold a tab of CDPEngine.
+func (e *TypedReportEngine) NewContext(o *utils.ShareOptions) (context.Context, context.CancelFunc, error) {
+	t, err := e.engineFor(o)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return t.NewContext(o)
+}
+
+// RenderReport renders a report by an engine of its kind.
+func (e *TypedReportEngine) RenderReport(ctx context.Context, o *utils.ShareOptions) (*RenderedReport, error) {
+	t, err := e.engineFor(o)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.RenderReport(ctx, o)
+}
+
+func (e *TypedReportEngine) engineFor(o *utils.ShareOptions) (ReportEngine, error) {
+	ref, err := domain.ParseReportID(o.ReportID)
+	if err != nil {
+		return nil, err
+	}
+
+	if t, ok := e.engines[ref.Type]; ok {
+		return t, nil
+	}
+
+	return e.engine, nil
+}
@@ -3,103 +3,129 @@ package reportengine
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
+	"net"
+	"time"
 
-	"github.com/aws/aws-sdk-go/aws/client"
-	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/replaygaming/amplitude"
 	"go.uber.org/zap"
 
 )
 
-type attributeName string
-
-const (
-	attributeMessageKind attributeName = "messageKind"
-	attributeTraceID     attributeName = "traceID"
-)
-
-type attributeType string
-
-const (
-	typeString attributeType = "String"
-)
-
+// RetryStrategy re-enqueues a report which couldn't be rendered, so it's rendered again later.
 type RetryStrategy struct {
-	Logger      *zap.Logger
-	URL         string
-	MaxAttempts int
-	AWSSession  client.ConfigProvider
+	Logger       *zap.Logger
+	MessageQueue messagequeue.MessageQueue
+	Config       *config.RetryStrategyConfig
 }
 
 type ReportRetryStrategy interface {
 	Retry(context.Context, *utils.ShareOptions, error) (bool, error)
 }
 
-func NewRetryStrategy(l *zap.Logger, url string, attempts int, awsSession client.ConfigProvider) ReportRetryStrategy {
+func NewRetryStrategy(l *zap.Logger, mq messagequeue.MessageQueue, c *config.RetryStrategyConfig) ReportRetryStrategy {
 	return &RetryStrategy{
-		Logger:      l,
-		URL:         url,
-		MaxAttempts: attempts,
-		AWSSession:  awsSession,
+		Logger:       l,
+		MessageQueue: mq,
+		Config:       c,
 	}
 }
 
-func (r *RetryStrategy) Retry(renderReportCtx context.Context, shareOptions *utils.ShareOptions, errorGenerateReport error) (skipPosting bool, err error) {
-	if errorGenerateReport != nil {
-		if shareOptions.RetryAttempt < r.MaxAttempts-1 {
-			shareOptions.PostReportMessage.RetryAttempt++
-			e := messagequeue.Envelope{
-				Kind:    messagequeue.MessagePostReport,
-				Body:    shareOptions.PostReportMessage,
-				TraceID: utils.ActivityInfo(renderReportCtx)["activityID"],
-			}
-			var j []byte
-			j, err = json.Marshal(e)
-			if err != nil {
-				return false, err
-			}
-
-			s := &sqs.SendMessageInput{}
-			s = s.
-				SetMessageGroupId(string(e.Kind)).
-				SetMessageBody(string(j)).
-				SetMessageAttributes(map[string]*sqs.MessageAttributeValue{
-					string(attributeMessageKind): (&sqs.MessageAttributeValue{}).
-						SetDataType(string(typeString)).
-						SetStringValue(string(e.Kind)),
-					string(attributeTraceID): (&sqs.MessageAttributeValue{}).
-						SetDataType(string(typeString)).
-						SetStringValue(e.TraceID),
-				})
-			s.SetMessageBody(string(j))
-			s = s.SetQueueUrl(r.URL)
-			q := sqs.New(r.AWSSession)
-			_, err = q.SendMessageWithContext(renderReportCtx, s)
-			if err != nil {
-				r.Logger.Error("couldn't enqueue message", zap.Error(err))
-			} else {
-				r.Logger.Info("retry to send", zap.String("reportID", shareOptions.ReportID), zap.Int("retryAttempt", shareOptions.RetryAttempt), zap.Error(errorGenerateReport))
-				RetryAttempt := json.RawMessage(fmt.Sprintf(`{"isScheduled": %v, "reportID": "%v","retryAttempt": "%v"}`, shareOptions.IsScheduled, shareOptions.ReportID, shareOptions.RetryAttempt))
-				m := amplitude.Properties{
-					"retryAttempt": &RetryAttempt,
-				}
-				analytics.DefaultAmplitudeClient().Send(
-					analytics.EventKindReportRetried,
-					shareOptions.WorkspaceID,
-					shareOptions.UserID,
-					shareOptions.ClientID,
-					m,
-				)
-			}
-
-			skipPosting = true
-		} else {
-			r.Logger.Error("Couldn't generate report", zap.Error(err))
-			skipPosting = false
-			err = errorGenerateReport
+func (r *RetryStrategy) Retry(ctx context.Context, shareOptions *utils.ShareOptions, errorGenerateReport error) (skipPosting bool, err error) {
+	if errorGenerateReport == nil {
+		return false, nil
+	}
+
+	logger := utils.WithContext(ctx, r.Logger)
+
+	if shareOptions.RetryAttempt >= r.Config.MaxAttempts-1 || !isRetryable(errorGenerateReport) {
+		logger.Error("Couldn't generate report", zap.Error(errorGenerateReport))
+
+		return false, errorGenerateReport
+	}
+
+	shareOptions.RetryAttempt++
+	kind, body := messagequeue.MessagePostReport, interface{}(shareOptions.PostReportMessage)
+	if shareOptions.DistributeReportMessage != nil {
+		shareOptions.DistributeReportMessage.RetryAttempt = shareOptions.RetryAttempt
+		kind, body = messagequeue.MessageDistributeReport, shareOptions.DistributeReportMessage
+	} else {
+		shareOptions.PostReportMessage.RetryAttempt = shareOptions.RetryAttempt
+	}
+
+	priority := messagequeue.PriorityInteractive
+	if shareOptions.IsScheduled {
+		priority = messagequeue.PriorityScheduled
+	}
+
+	delay := r.getDelay(shareOptions.RetryAttempt)
+	e := messagequeue.Envelope{
+		Kind:     kind,
+		Body:     body,
+		TraceID:  utils.ActivityInfo(ctx)["activityID"],
+		Priority: priority,
+		Delay:    delay,
+	}
+	err = r.MessageQueue.Push(ctx, &e, messagequeue.Wait)
+	if err != nil {
+		logger.Error("couldn't enqueue message", zap.Error(err))
+
+		return false, errorGenerateReport
+	}
+
+	logger.Info("retry to send",
+		zap.String("reportID", shareOptions.ReportID),
+		zap.Int("retryAttempt", shareOptions.RetryAttempt),
+		zap.Duration("delay", delay),
+		zap.Error(errorGenerateReport))
+	RetryAttempt := json.RawMessage(fmt.Sprintf(`{"isScheduled": %v, "reportID": "%v","retryAttempt": "%v"}`, shareOptions.IsScheduled, shareOptions.ReportID, shareOptions.RetryAttempt))
+	m := amplitude.Properties{
+		"retryAttempt": &RetryAttempt,
+	}
+	analytics.DefaultAmplitudeClient().Send(
+		analytics.EventKindReportRetried,
+		shareOptions.WorkspaceID,
+		shareOptions.UserID,
+		shareOptions.ClientID,
+		m,
+	)
+
+	return true, nil
+}
+
+// NOTE: Delay doubles w/ each attempt, a random half of it is dropped (so called "equal jitter") not to retry a bunch of reports failed at once at the same moment.
+func (r *RetryStrategy) getDelay(attempt int) time.Duration {
+	d := r.Config.MaxDelay
+	if attempt <= 30 {
+		d = r.Config.InitialDelay << (attempt - 1)
+		if d <= 0 || d > r.Config.MaxDelay {
+			d = r.Config.MaxDelay
 		}
 	}
 
-	return skipPosting, err
+	half := d / 2
+
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// isRetryable tells whether rendering a report again might succeed.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	netErr := net.Error(nil)
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	// NOTE: Retrying won't help if Power BI rejected credentials, user has to sign in again.
+	pbiErr := (*pbiError)(nil)
+	if errors.As(err, &pbiErr) {
+		return !pbiErr.isAuthError()
+	}
+
+	return true
 }
@@ -0,0 +1,284 @@
+package reportengine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"go.uber.org/zap"
+
+)
+
+// Tab states; a tab released while rendering (e.g. on error) isn't reused.
+const (
+	tabReady int32 = iota
+	tabRendering
+	tabRendered
+)
+
+// pooledTab is a tab w/ the report template loaded & initialized, so it's reused by many renders.
+type pooledTab struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	renders int
+	state   int32
+}
+
+type pooledTabKey struct{}
+
+// withPooledTab binds t to ctx, so RenderReport skips loading the report template.
+func withPooledTab(ctx context.Context, t *pooledTab) context.Context {
+	return context.WithValue(ctx, pooledTabKey{}, t)
+}
+
+func pooledTabFromContext(ctx context.Context) *pooledTab {
+	t, _ := ctx.Value(pooledTabKey{}).(*pooledTab)
+
+	return t
+}
+
+// TabPoolStats holds counters of a tab pool; a wait lasts from asking for a tab till getting one.
+type TabPoolStats struct {
+	Open      int
+	Idle      int
+	Waiting   int
+	Acquired  int64
+	Recycled  int64
+	Unhealthy int64
+	TotalWait time.Duration
+	MaxWait   time.Duration
+}
+
+// tabPool keeps up to config.BrowserConfig.PoolSize idle tabs & no more than config.BrowserConfig.MaxTabs tabs overall.
+type tabPool struct {
+	config *config.BrowserConfig
+	logger *zap.Logger
+	open   func() (*pooledTab, error)
+	// NOTE: Each open tab holds a slot, so a tab is opened only if a slot is free.
+	slots  chan struct{}
+	idle   chan *pooledTab
+	fillMu sync.Mutex
+	mu     sync.Mutex
+	stats  TabPoolStats
+}
+
+func newTabPool(c *config.BrowserConfig, l *zap.Logger, open func() (*pooledTab, error)) *tabPool {
+	return &tabPool{
+		config: c,
+		logger: l,
+		open:   open,
+		slots:  make(chan struct{}, c.MaxTabs),
+		idle:   make(chan *pooledTab, c.MaxTabs),
+	}
+}
+
+// start opens idle tabs & checks them periodically until ctx is done.
+func (p *tabPool) start(ctx context.Context) {
+	p.fill()
+
+	go func() {
+		t := time.NewTicker(p.config.HealthCheckInterval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-t.C:
+				p.checkHealth()
+				p.fill()
+
+				s := p.Stats()
+				p.logger.Info("tab pool stats",
+					zap.Int("open", s.Open),
+					zap.Int("idle", s.Idle),
+					zap.Int("waiting", s.Waiting),
+					zap.Int64("acquired", s.Acquired),
+					zap.Int64("recycled", s.Recycled),
+					zap.Int64("unhealthy", s.Unhealthy),
+					zap.Duration("totalWait", s.TotalWait),
+					zap.Duration("maxWait", s.MaxWait))
+			}
+		}
+	}()
+}
+
+// Stats returns a snapshot of pool counters.
+func (p *tabPool) Stats() TabPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s := p.stats
+	s.Open = len(p.slots)
+	s.Idle = len(p.idle)
+
+	return s
+}
+
+// acquire takes an idle tab, opens a new one if there's a free slot, or waits for either until ctx is done.
+func (p *tabPool) acquire(ctx context.Context) (*pooledTab, error) {
+	startedAt := time.Now().UTC()
+
+	p.mu.Lock()
+	p.stats.Waiting++
+	p.mu.Unlock()
+
+	t, err := p.take(ctx)
+
+	waitedFor := time.Now().UTC().Sub(startedAt)
+
+	p.mu.Lock()
+	p.stats.Waiting--
+	if err == nil {
+		p.stats.Acquired++
+		p.stats.TotalWait += waitedFor
+		if waitedFor > p.stats.MaxWait {
+			p.stats.MaxWait = waitedFor
+		}
+	}
+	p.mu.Unlock()
+
+	return t, err
+}
+
+func (p *tabPool) take(ctx context.Context) (*pooledTab, error) {
+	for {
+		t, err := p.next(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// NOTE: Tabs die along w/ the browser, so one left from a browser restarted since is replaced.
+		if t.ctx.Err() == nil {
+			return t, nil
+		}
+
+		p.close(t)
+	}
+}
+
+func (p *tabPool) next(ctx context.Context) (*pooledTab, error) {
+	select {
+	case t := <-p.idle:
+		return t, nil
+
+	default:
+	}
+
+	select {
+	case t := <-p.idle:
+		return t, nil
+
+	case p.slots <- struct{}{}:
+		t, err := p.open()
+		if err != nil {
+			<-p.slots
+
+			return nil, fmt.Errorf("couldn't open tab: %w", err)
+		}
+
+		return t, nil
+
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// release returns t to the pool; t is closed instead if a render failed in it or it rendered too many reports already.
+func (p *tabPool) release(t *pooledTab) {
+	s := atomic.SwapInt32(&t.state, tabReady)
+	if s == tabRendered {
+		t.renders++
+	}
+
+	if s != tabRendering && t.renders < p.config.TabMaxRenders && t.ctx.Err() == nil {
+		p.idle <- t
+
+		return
+	}
+
+	p.close(t)
+
+	p.mu.Lock()
+	p.stats.Recycled++
+	p.mu.Unlock()
+
+	go p.fill()
+}
+
+func (p *tabPool) close(t *pooledTab) {
+	t.cancel()
+	<-p.slots
+}
+
+// fill opens tabs till there are config.BrowserConfig.PoolSize idle ones or no free slots.
+func (p *tabPool) fill() {
+	p.fillMu.Lock()
+	defer p.fillMu.Unlock()
+
+	for len(p.idle) < p.config.PoolSize {
+		select {
+		case p.slots <- struct{}{}:
+
+		default:
+			return
+		}
+
+		t, err := p.open()
+		if err != nil {
+			<-p.slots
+			p.logger.Error("couldn't open tab", zap.Error(err))
+
+			return
+		}
+
+		p.idle <- t
+	}
+}
+
+// checkHealth closes idle tabs which don't respond or lost the report template.
+func (p *tabPool) checkHealth() {
+	for n := len(p.idle); n > 0; n-- {
+		t := (*pooledTab)(nil)
+		select {
+		case t = <-p.idle:
+
+		default:
+			return
+		}
+
+		ready := false
+		checkCtx, cancelCheck := context.WithTimeout(t.ctx, p.config.MinActionTimeout)
+		err := chromedp.Run(checkCtx, chromedp.Evaluate("window.reportRenderer.isReady();", &ready))
+		cancelCheck()
+		if err == nil && ready {
+			p.idle <- t
+
+			continue
+		}
+
+		p.logger.Warn("closing unhealthy tab", zap.Error(err), zap.Bool("ready", ready))
+		p.close(t)
+
+		p.mu.Lock()
+		p.stats.Unhealthy++
+		p.mu.Unlock()
+	}
+}
+
+// drain closes idle tabs.
+func (p *tabPool) drain() {
+	for {
+		select {
+		case t := <-p.idle:
+			p.close(t)
+
+		default:
+			return
+		}
+	}
+}
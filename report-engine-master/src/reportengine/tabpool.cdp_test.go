@@ -0,0 +1,204 @@
+package reportengine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+)
+
+// newTestTabPool creates a tabPool of tabs w/o a browser; opened counts tabs opened so far.
+func newTestTabPool(maxTabs, tabMaxRenders int) (p *tabPool, opened *int) {
+	n := 0
+	c := config.BrowserConfig{
+		MaxTabs:       maxTabs,
+		TabMaxRenders: tabMaxRenders,
+	}
+	p = newTabPool(&c, zap.NewNop(), func() (*pooledTab, error) {
+		n++
+		ctx, cancel := context.WithCancel(context.Background())
+
+		return &pooledTab{
+			ctx:    ctx,
+			cancel: cancel,
+		}, nil
+	})
+
+	return p, &n
+}
+
+func TestTabPoolRelease(t *testing.T) {
+	tests := []struct {
+		name         string
+		renders      int
+		state        int32
+		closed       bool
+		wantReused   bool
+		wantRenders  int
+		wantRecycled int64
+	}{
+		{
+			name:        "rendered",
+			state:       tabRendered,
+			wantReused:  true,
+			wantRenders: 1,
+		},
+		{
+			name:       "released before rendering",
+			state:      tabReady,
+			wantReused: true,
+		},
+		{
+			name:         "failed while rendering",
+			state:        tabRendering,
+			wantRecycled: 1,
+		},
+		{
+			name:         "rendered too many reports",
+			renders:      2,
+			state:        tabRendered,
+			wantRecycled: 1,
+		},
+		{
+			name:         "closed along w/ browser",
+			state:        tabRendered,
+			closed:       true,
+			wantRecycled: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, opened := newTestTabPool(1, 3)
+
+			tab, err := p.acquire(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tab.renders = tt.renders
+			tab.state = tt.state
+			if tt.closed {
+				tab.cancel()
+			}
+
+			p.release(tab)
+
+			s := p.Stats()
+			if s.Recycled != tt.wantRecycled {
+				t.Errorf("Recycled = %v, want %v", s.Recycled, tt.wantRecycled)
+			}
+
+			next, err := p.acquire(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if reused := next == tab; reused != tt.wantReused {
+				t.Fatalf("reused = %v, want %v", reused, tt.wantReused)
+			}
+
+			if tt.wantReused && next.renders != tt.wantRenders {
+				t.Errorf("renders = %v, want %v", next.renders, tt.wantRenders)
+			}
+
+			if !tt.wantReused && *opened != 2 {
+				t.Errorf("opened %v tabs, want 2", *opened)
+			}
+
+			if next.state != tabReady {
+				t.Errorf("state = %v, want %v", next.state, tabReady)
+			}
+		})
+	}
+}
+
+func TestTabPoolAcquire(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxTabs    int
+		acquired   int
+		closeIdle  bool
+		wantErr    error
+		wantOpened int
+		wantOpen   int
+	}{
+		{
+			name:       "opens a tab",
+			maxTabs:    2,
+			wantOpened: 1,
+			wantOpen:   1,
+		},
+		{
+			name:       "opens a tab while slots are free",
+			maxTabs:    2,
+			acquired:   1,
+			wantOpened: 2,
+			wantOpen:   2,
+		},
+		{
+			name:       "waits for a free slot",
+			maxTabs:    1,
+			acquired:   1,
+			wantErr:    context.DeadlineExceeded,
+			wantOpened: 1,
+			wantOpen:   1,
+		},
+		{
+			name:       "replaces a closed idle tab",
+			maxTabs:    1,
+			closeIdle:  true,
+			wantOpened: 2,
+			wantOpen:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, opened := newTestTabPool(tt.maxTabs, 10)
+
+			for i := 0; i < tt.acquired; i++ {
+				_, err := p.acquire(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.closeIdle {
+				tab, err := p.acquire(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				p.release(tab)
+				tab.cancel()
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			tab, err := p.acquire(ctx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("acquire() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && tab.ctx.Err() != nil {
+				t.Error("acquire() returned a closed tab")
+			}
+
+			if *opened != tt.wantOpened {
+				t.Errorf("opened %v tabs, want %v", *opened, tt.wantOpened)
+			}
+
+			s := p.Stats()
+			if s.Waiting != 0 {
+				t.Errorf("Waiting = %v, want 0", s.Waiting)
+			}
+
+			if s.Open != tt.wantOpen {
+				t.Errorf("Open = %v, want %v", s.Open, tt.wantOpen)
+			}
+		})
+	}
+}
@@ -1,13 +1,48 @@
 package reportengine
 
+import (
+	"html/template"
+)
+
 type pageScreenshot struct {
-	pageID   string
-	pageName string
-	rawData  []byte
+	pageID     string
+	pageName   string
+	visualName string
+	rawData    []byte
+	// NOTE: Size includes viewport margin, so it's the size of rawData in CSS pixels.
+	width  int64
+	height int64
+	// data is exported for utils.ShareOptions.DataFormat only.
+	data           []*visualData
+	dataNotAllowed bool
+}
+
+// visualData is summarized data of a visual exported as CSV.
+type visualData struct {
+	pageName    string
+	visualTitle string
+	csv         string
 }
 
 type resource string
 
 const (
-	resourceReportTemplate2 resource = "reportTemplate2.html"
+	resourceReportTemplate2  resource = "reportTemplate2.html"
+	resourceDocumentTemplate resource = "documentTemplate.html"
 )
+
+// documentData is passed to resourceDocumentTemplate.
+type documentData struct {
+	ReportName string
+	Filter     string
+	Timestamp  string
+	Cover      documentPage
+	Pages      []*documentPage
+}
+
+type documentPage struct {
+	Name     string
+	Width    int64
+	Height   int64
+	ImageURL template.URL
+}
@@ -0,0 +1,87 @@
+package reportengine
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"strings"
+
+)
+
+// NOTE: Byte order mark makes Excel read a CSV file as UTF-8.
+const csvBOM = "\ufeff"
+
+// NOTE: Names of pages & visuals become paths of an archive, so separators are replaced.
+var entryNameReplacer = strings.NewReplacer("/", "-", `\`, "-")
+
+// newRenderedData puts data exported along w/ ss into files of utils.ShareOptions.DataFormat.
+// No files are made if a report doesn't allow export on any of its pages.
+func newRenderedData(o *utils.ShareOptions, ss []*pageScreenshot, timestamp string) (*RenderedData, error) {
+	d := RenderedData{}
+	vs := []*visualData(nil)
+	for _, s := range ss {
+		d.NotAllowed = d.NotAllowed || s.dataNotAllowed
+		vs = append(vs, s.data...)
+	}
+
+	if d.NotAllowed || len(vs) == 0 {
+		return &d, nil
+	}
+
+	switch o.DataFormat {
+	case messagequeue.DataFormatCSV:
+		for _, v := range vs {
+			f := RenderedFile{
+				Filename:    strings.TrimSuffix(pageFilename(o, v.pageName, v.visualTitle, timestamp), ".png") + ".csv",
+				ContentType: "text/csv",
+				Data:        []byte(csvBOM + v.csv),
+			}
+			d.Files = append(d.Files, &f)
+		}
+
+	case messagequeue.DataFormatZIP:
+		b := bytes.Buffer{}
+		w := zip.NewWriter(&b)
+		names := map[string]int{}
+		for _, v := range vs {
+			name := fmt.Sprintf("%v/%v", entryNameReplacer.Replace(v.pageName), entryNameReplacer.Replace(v.visualTitle))
+
+			// NOTE: Visuals of a page may share a title, so the rest of them are numbered.
+			names[name]++
+			if n := names[name]; n > 1 {
+				name = fmt.Sprintf("%v (%v)", name, n)
+			}
+
+			f, err := w.Create(name + ".csv")
+			if err != nil {
+				return nil, err
+			}
+
+			_, err = f.Write([]byte(csvBOM + v.csv))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err := w.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		filename := ""
+		if f := o.FilterString(); f != "" {
+			filename = fmt.Sprintf("%v (%v) %v.zip", o.ReportName, f, timestamp)
+		} else {
+			filename = fmt.Sprintf("%v %v.zip", o.ReportName, timestamp)
+		}
+
+		f := RenderedFile{
+			Filename:    filename,
+			ContentType: "application/zip",
+			Data:        b.Bytes(),
+		}
+		d.Files = append(d.Files, &f)
+	}
+
+	return &d, nil
+}
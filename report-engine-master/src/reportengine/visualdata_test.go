@@ -0,0 +1,141 @@
+package reportengine
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+)
+
+func TestNewRenderedData(t *testing.T) {
+	sales := &visualData{
+		pageName:    "Overview",
+		visualTitle: "Sales",
+		csv:         "Region,Sales\nWest,1\n",
+	}
+	margin := &visualData{
+		pageName:    "Overview",
+		visualTitle: "Margin",
+		csv:         "Region,Margin\nWest,2\n",
+	}
+
+	tests := []struct {
+		name           string
+		format         messagequeue.DataFormat
+		ss             []*pageScreenshot
+		wantNotAllowed bool
+		wantFiles      []string
+		wantEntries    []string
+	}{
+		{
+			name:   "no data",
+			format: messagequeue.DataFormatCSV,
+			ss:     []*pageScreenshot{{pageName: "Overview"}},
+		},
+		{
+			name:   "export not allowed on a page",
+			format: messagequeue.DataFormatZIP,
+			ss: []*pageScreenshot{
+				{pageName: "Overview", data: []*visualData{sales}},
+				{pageName: "Details", dataNotAllowed: true},
+			},
+			wantNotAllowed: true,
+		},
+		{
+			name:   "file per visual",
+			format: messagequeue.DataFormatCSV,
+			ss: []*pageScreenshot{
+				{pageName: "Overview", data: []*visualData{sales, margin}},
+			},
+			wantFiles: []string{
+				"Report: Overview - Sales 2026-10-17 09.00.csv",
+				"Report: Overview - Margin 2026-10-17 09.00.csv",
+			},
+		},
+		{
+			name:   "archive of all visuals",
+			format: messagequeue.DataFormatZIP,
+			ss: []*pageScreenshot{
+				{pageName: "Overview", data: []*visualData{sales, margin}},
+				{pageName: "Sales/Region", data: []*visualData{
+					{pageName: "Sales/Region", visualTitle: `West\East`, csv: "a\n"},
+					{pageName: "Sales/Region", visualTitle: `West\East`, csv: "b\n"},
+				}},
+			},
+			wantFiles: []string{"Report 2026-10-17 09.00.zip"},
+			wantEntries: []string{
+				"Overview/Sales.csv",
+				"Overview/Margin.csv",
+				"Sales-Region/West-East.csv",
+				"Sales-Region/West-East (2).csv",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := utils.ShareOptions{
+				ReportName: "Report",
+				DataFormat: tt.format,
+			}
+
+			d, err := newRenderedData(&o, tt.ss, "2026-10-17 09.00")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if d.NotAllowed != tt.wantNotAllowed {
+				t.Errorf("NotAllowed = %v, want %v", d.NotAllowed, tt.wantNotAllowed)
+			}
+
+			files := []string(nil)
+			for _, f := range d.Files {
+				files = append(files, f.Filename)
+			}
+
+			if !reflect.DeepEqual(files, tt.wantFiles) {
+				t.Fatalf("files = %q, want %q", files, tt.wantFiles)
+			}
+
+			for _, f := range d.Files {
+				if tt.format == messagequeue.DataFormatCSV && !bytes.HasPrefix(f.Data, []byte(csvBOM)) {
+					t.Errorf("%v has no byte order mark", f.Filename)
+				}
+			}
+
+			if tt.wantEntries == nil {
+				return
+			}
+
+			r, err := zip.NewReader(bytes.NewReader(d.Files[0].Data), int64(len(d.Files[0].Data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			entries := []string(nil)
+			for _, f := range r.File {
+				entries = append(entries, f.Name)
+
+				rc, err := f.Open()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				b, err := io.ReadAll(rc)
+				rc.Close()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !bytes.HasPrefix(b, []byte(csvBOM)) {
+					t.Errorf("%v has no byte order mark", f.Name)
+				}
+			}
+
+			if !reflect.DeepEqual(entries, tt.wantEntries) {
+				t.Errorf("entries = %q, want %q", entries, tt.wantEntries)
+			}
+		})
+	}
+}
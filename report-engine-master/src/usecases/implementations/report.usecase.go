@@ -90,6 +90,102 @@ func (reportUsecase *ReportUsecase) ShareReport(ctx context.Context, user *domai
 	}
 }
 
+// DistributeReport renders a report once & posts it to each of ds, so a failed delivery doesn't stop the rest.
+func (reportUsecase *ReportUsecase) DistributeReport(ctx context.Context, user *domain.User, o *utils.ShareOptions, ds []*usecases.ReportDelivery) []error {
+	pis := []string(nil)
+	for _, p := range o.Pages {
+		pis = append(pis, p.ID)
+	}
+
+	ctx = utils.WithActivityInfo(ctx, utils.StringSet{
+		"activityKind": "distributeReport",
+		"reportID":     o.ReportID,
+		"pageIDs":      strings.Join(pis, ", "),
+		"userID":       o.UserID,
+		"workspaceID":  o.WorkspaceID,
+	})
+	logger := utils.WithContext(ctx, reportUsecase.logger)
+
+	startedAt := time.Now().UTC()
+	logger.Debug("started distributing report", zap.Int("totalTargets", len(ds)))
+
+	reportProperty := json.RawMessage(fmt.Sprintf(`{"isScheduled": %v, "reportID": "%v"}`, o.IsScheduled, o.ReportID))
+	m := amplitude.Properties{
+		"report": &reportProperty,
+	}
+
+	slackUserID := (*domain.SlackUserID)(nil)
+	if user != nil {
+		slackUserID = user.GetSlackUserID()
+	}
+
+	errs := make([]error, len(ds))
+	report, renderedReport, skipPosting, err := reportUsecase.generateReport(&ctx, o, slackUserID, logger, m)
+	if err != nil {
+		logger.Error("couldn't generate report", zap.Error(err))
+
+		// NOTE: A target notified of the failure isn't retried, otherwise redelivery would render the report again & repeat the notice.
+		for i, d := range ds {
+			errs[i] = &messagequeue.NonRetryableError{Err: err}
+			nErr := reportUsecase.notifyFailure(d)
+			if nErr != nil {
+				logger.Error("couldn't notify of failed report", zap.Error(nErr), zap.String("channelID", d.Options.ChannelID))
+
+				errs[i] = err
+			}
+		}
+
+		return errs
+	}
+
+	if skipPosting {
+		return errs
+	}
+
+	for i, d := range ds {
+		if d.Options.SkipPosting {
+			continue
+		}
+
+		switch d.Options.ClientID {
+		case slackClient:
+			errs[i] = reportUsecase.postToSlack(ctx, d.User, d.Token, d.Options, report, renderedReport, m)
+
+		case teamsClient:
+			errs[i] = reportUsecase.postToTeams(ctx, d.Token, d.Options, report, renderedReport, m)
+
+		default:
+			errs[i] = domain.ErrInvalidType
+		}
+	}
+
+	completedIn := time.Now().UTC().Sub(startedAt)
+	logger.Info("completed distributing report", zap.Duration("completedIn", completedIn), zap.Int("totalPages", len(renderedReport.Pages)), zap.Int("totalTargets", len(ds)))
+
+	return errs
+}
+
+func (reportUsecase *ReportUsecase) notifyFailure(d *usecases.ReportDelivery) error {
+	switch d.Options.ClientID {
+	case slackClient:
+		api := slack.New(d.Token)
+		errText := fmt.Sprintf(failedReportPattern, d.Options.ReportName)
+		_, _, err := api.PostMessage(
+			d.Options.ChannelID,
+			slack.MsgOptionText(errText, false),
+			slack.MsgOptionAsUser(true),
+		)
+
+		return err
+
+	case teamsClient:
+		return teams.SendFailedMessage(d.Options, d.Token)
+
+	default:
+		return domain.ErrInvalidType
+	}
+}
+
 func (reportUsecase *ReportUsecase) generateReport(
 	ctx *context.Context,
 	o *utils.ShareOptions,
@@ -97,7 +193,7 @@ func (reportUsecase *ReportUsecase) generateReport(
 	logger *zap.Logger,
 	m amplitude.Properties,
 ) (*domain.Report, *reportengine.RenderedReport, bool, error) {
-	renderReportCtx, cancelRender, err := reportengine.DefaultReportEngine().NewContext()
+	renderReportCtx, cancelRender, err := reportengine.DefaultReportEngine().NewContext(o)
 	if err != nil {
 		logger.Error("couldn't create context", zap.Error(err))
 
@@ -145,7 +241,8 @@ func (reportUsecase *ReportUsecase) generateReport(
 	}()
 
 	renderedReport, err := reportengine.DefaultReportEngine().RenderReport(renderReportCtx, o)
-	skipPosting, err := reportUsecase.reportRetryStrategy.Retry(renderReportCtx, o, err)
+	// NOTE: renderReportCtx may be already expired, so retry is enqueued in parent context.
+	skipPosting, err := reportUsecase.reportRetryStrategy.Retry(*ctx, o, err)
 	if err != nil {
 		logger.Error("couldn't render report", zap.Error(err))
 
@@ -207,13 +304,19 @@ func (reportUsecase *ReportUsecase) shareToSlack(ctx context.Context, user *doma
 		api := slack.New(slackToken)
 		errText := fmt.Sprintf(failedReportPattern, o.ReportName)
 
-		_, _, err := api.PostMessage(
+		_, _, nErr := api.PostMessage(
 			o.ChannelID,
 			slack.MsgOptionText(errText, false),
 			slack.MsgOptionAsUser(true),
 		)
+		if nErr != nil {
+			logger.Error("couldn't notify of failed report", zap.Error(nErr))
 
-		return err
+			return err
+		}
+
+		// NOTE: User knows the report failed, so the message is moved to dead letters rather than redelivered to repeat the notice.
+		return &messagequeue.NonRetryableError{Err: err}
 	}
 
 	if skipPosting {
@@ -221,107 +324,196 @@ func (reportUsecase *ReportUsecase) shareToSlack(ctx context.Context, user *doma
 	}
 
 	if !o.SkipPosting {
-		api := slack.New(slackToken)
-
-		slackUser, err := api.GetUserInfo(slackUserID.ID)
+		err = reportUsecase.postToSlack(ctx, user, slackToken, o, report, renderedReport, m)
 		if err != nil {
-			if err.Error() == constants.ErrorAccountInactive {
-				err = reportUsecase.workspaceRepository.DeleteSoft(ctx, user.WorkspaceID)
-				if err != nil {
-					logger.Error("couldn't remove workspace", zap.Error(err))
-					return err
-				}
-				logger.Info("workspace had been deactivated, removing it", zap.String("slackID", user.ID))
-
-				analytics.DefaultAmplitudeClient().Send(analytics.EventKindWorkspaceDeleted, user.WorkspaceID, user.ID, slackClient, nil)
+			return err
+		}
+	}
 
-				return nil
-			}
+	if renderedReport != nil {
+		completedIn := time.Now().UTC().Sub(startedAt)
+		logger.Info("completed sharing report", zap.Duration("completedIn", completedIn), zap.Int("totalPages", len(renderedReport.Pages)))
+	}
 
-			if err.Error() == constants.ErrorNotInChannel {
-				err = reportUsecase.postingTaskRepository.DeleteBySlackInfo(ctx, slackUserID, o.ChannelID)
-				if err != nil {
-					logger.Error("couldn't remove scheduled report", zap.Error(err))
-					return err
-				}
-				logger.Info("channel had been deactivated, removing related scheduled tasks", zap.String("slackID", user.ID))
+	return nil
+}
 
-				analytics.DefaultAmplitudeClient().Send(analytics.EventKindChannelDeleted, user.WorkspaceID, user.ID, slackClient, nil)
+// postToSlack uploads rendered pages (or a document holding all of them) to a channel, on behalf of user.
+func (reportUsecase *ReportUsecase) postToSlack(
+	ctx context.Context,
+	user *domain.User,
+	slackToken string,
+	o *utils.ShareOptions,
+	report *domain.Report,
+	renderedReport *reportengine.RenderedReport,
+	m amplitude.Properties,
+) error {
+	slackUserID := user.GetSlackUserID()
+	logger := utils.WithContext(ctx, reportUsecase.logger)
+	api := slack.New(slackToken)
 
-				return nil
+	slackUser, err := api.GetUserInfo(slackUserID.ID)
+	if err != nil {
+		if err.Error() == constants.ErrorAccountInactive {
+			err = reportUsecase.workspaceRepository.DeleteSoft(ctx, user.WorkspaceID)
+			if err != nil {
+				logger.Error("couldn't remove workspace", zap.Error(err))
+				return err
 			}
+			logger.Info("workspace had been deactivated, removing it", zap.String("slackID", user.ID))
 
-			logger.Error("couldn't get user info", zap.Error(err))
-			return err
+			analytics.DefaultAmplitudeClient().Send(analytics.EventKindWorkspaceDeleted, user.WorkspaceID, user.ID, slackClient, nil)
+
+			return nil
 		}
 
-		if slackUser.Deleted && user.IsActive {
-			err = reportUsecase.userRepository.Deactivate(ctx, slackUserID)
+		if err.Error() == constants.ErrorNotInChannel {
+			err = reportUsecase.postingTaskRepository.DeleteBySlackInfo(ctx, slackUserID, o.ChannelID)
 			if err != nil {
-				logger.Error("couldn't deactivate user", zap.Error(err))
-
+				logger.Error("couldn't remove scheduled report", zap.Error(err))
 				return err
 			}
-			logger.Info("deactivating user account", zap.String("slackID", user.ID))
+			logger.Info("channel had been deactivated, removing related scheduled tasks", zap.String("slackID", user.ID))
 
-			analytics.DefaultAmplitudeClient().Send(analytics.EventKindUserDeactivated, user.WorkspaceID, user.ID, slackClient, nil)
+			analytics.DefaultAmplitudeClient().Send(analytics.EventKindChannelDeleted, user.WorkspaceID, user.ID, slackClient, nil)
 
 			return nil
 		}
-		if !slackUser.Deleted && !user.IsActive {
-			err = reportUsecase.userRepository.Reactivate(ctx, slackUserID)
-			if err != nil {
-				logger.Error("couldn't activate user", zap.Error(err))
 
-				return err
-			}
-			logger.Info("reactivating user account", zap.String("slackID", user.ID))
+		logger.Error("couldn't get user info", zap.Error(err))
+		return err
+	}
+
+	if slackUser.Deleted && user.IsActive {
+		err = reportUsecase.userRepository.Deactivate(ctx, slackUserID)
+		if err != nil {
+			logger.Error("couldn't deactivate user", zap.Error(err))
 
-			analytics.DefaultAmplitudeClient().Send(analytics.EventKindUserReactivated, user.WorkspaceID, user.ID, slackClient, nil)
+			return err
 		}
+		logger.Info("deactivating user account", zap.String("slackID", user.ID))
 
-		for _, page := range renderedReport.Pages {
-			title := ""
-			if o.Filter != nil {
-				title = constants.FormatMessageTitleWithFilter(o.ReportName, o.Filter.String(), page.Name)
-			} else {
-				title = constants.FormatMessageTitle(o.ReportName, page.Name)
-			}
+		analytics.DefaultAmplitudeClient().Send(analytics.EventKindUserDeactivated, user.WorkspaceID, user.ID, slackClient, nil)
 
-			comment := constants.FormatPageURL(report.GetWebURL(), page.ID)
-			if o.IsScheduled {
-				comment = fmt.Sprintf("<@%v>, %v", o.UserID, comment)
-			}
+		return nil
+	}
+	if !slackUser.Deleted && !user.IsActive {
+		err = reportUsecase.userRepository.Reactivate(ctx, slackUserID)
+		if err != nil {
+			logger.Error("couldn't activate user", zap.Error(err))
+
+			return err
+		}
+		logger.Info("reactivating user account", zap.String("slackID", user.ID))
+
+		analytics.DefaultAmplitudeClient().Send(analytics.EventKindUserReactivated, user.WorkspaceID, user.ID, slackClient, nil)
+	}
+
+	pages := renderedReport.Pages
+	if renderedReport.Document != nil {
+		// NOTE: Pages are posted as a single document instead.
+		pages = nil
+
+		title := ""
+		if f := o.FilterString(); f != "" {
+			title = constants.FormatDocumentTitleWithFilter(o.ReportName, f)
+		} else {
+			title = constants.FormatDocumentTitle(o.ReportName)
+		}
+
+		comment := constants.FormatReportURL(report.GetWebURL())
+		if o.IsScheduled {
+			comment = fmt.Sprintf("<@%v>, %v", o.UserID, comment)
+		}
+
+		file := bytes.NewReader(renderedReport.Document.Data)
+		uploadDocument := slack.FileUploadParameters{
+			Title:    title,
+			Filename: renderedReport.Document.Filename,
+			Reader:   file,
+			Channels: []string{
+				o.ChannelID,
+			},
+			InitialComment: comment,
+		}
+		_, err := api.UploadFile(uploadDocument)
+		if err != nil {
+			logger.Error("couldn't upload document", zap.Error(err))
+
+			analytics.DefaultAmplitudeClient().Send(analytics.EventKindSendReportMessageFailed, slackUserID.WorkspaceID, slackUserID.ID, slackClient, m)
+			return err
+		}
+	}
+
+	filter := o.FilterString()
+	for _, page := range pages {
+		title := ""
+		if page.VisualName != "" && filter != "" {
+			title = constants.FormatVisualTitleWithFilter(o.ReportName, filter, page.Name, page.VisualName)
+		} else if page.VisualName != "" {
+			title = constants.FormatVisualTitle(o.ReportName, page.Name, page.VisualName)
+		} else if filter != "" {
+			title = constants.FormatMessageTitleWithFilter(o.ReportName, filter, page.Name)
+		} else {
+			title = constants.FormatMessageTitle(o.ReportName, page.Name)
+		}
+
+		comment := constants.FormatPageURL(report.GetWebURL(), page.ID)
+		if o.IsScheduled {
+			comment = fmt.Sprintf("<@%v>, %v", o.UserID, comment)
+		}
+
+		image := page.Image(slackClient)
+		file := bytes.NewReader(image.Data)
+		uploadPage := slack.FileUploadParameters{
+			Title:    title,
+			Filename: image.Filename,
+			Reader:   file,
+			Channels: []string{
+				o.ChannelID,
+			},
+			InitialComment: comment,
+		}
+		_, err := api.UploadFile(uploadPage)
+		if err != nil {
+			logger.Error("couldn't upload page", zap.Error(err), zap.String("pageID", page.ID))
+
+			analytics.DefaultAmplitudeClient().Send(analytics.EventKindSendReportMessageFailed, slackUserID.WorkspaceID, slackUserID.ID, slackClient, m)
+			return err
+		}
+	}
 
-			file := bytes.NewReader(page.ImageData)
-			uploadPage := slack.FileUploadParameters{
-				Title:    title,
-				Filename: page.Filename,
-				Reader:   file,
+	if renderedReport.Data != nil && renderedReport.Data.NotAllowed {
+		_, _, err := api.PostMessage(o.ChannelID, slack.MsgOptionText(constants.FormatDataExportNotAllowed(o.ReportName), false))
+		if err != nil {
+			logger.Error("couldn't post data export notice", zap.Error(err))
+
+			analytics.DefaultAmplitudeClient().Send(analytics.EventKindSendReportMessageFailed, slackUserID.WorkspaceID, slackUserID.ID, slackClient, m)
+			return err
+		}
+	} else if renderedReport.Data != nil {
+		for _, f := range renderedReport.Data.Files {
+			uploadData := slack.FileUploadParameters{
+				Filename: f.Filename,
+				Reader:   bytes.NewReader(f.Data),
 				Channels: []string{
 					o.ChannelID,
 				},
-				InitialComment: comment,
 			}
-			_, err := api.UploadFile(uploadPage)
+			_, err := api.UploadFile(uploadData)
 			if err != nil {
-				logger.Error("couldn't upload page", zap.Error(err), zap.String("pageID", page.ID))
+				logger.Error("couldn't upload data", zap.Error(err), zap.String("filename", f.Filename))
 
 				analytics.DefaultAmplitudeClient().Send(analytics.EventKindSendReportMessageFailed, slackUserID.WorkspaceID, slackUserID.ID, slackClient, m)
 				return err
 			}
 		}
-
-		filterProperty := json.RawMessage(fmt.Sprintf(`{"withFilter": %v}`, o.Filter != nil))
-		m["filter"] = &filterProperty
-
-		analytics.DefaultAmplitudeClient().Send(analytics.EventKindReportGenerated, slackUserID.WorkspaceID, slackUserID.ID, slackClient, m)
 	}
 
-	if renderedReport != nil {
-		completedIn := time.Now().UTC().Sub(startedAt)
-		logger.Info("completed sharing report", zap.Duration("completedIn", completedIn), zap.Int("totalPages", len(renderedReport.Pages)))
-	}
+	filterProperty := json.RawMessage(fmt.Sprintf(`{"withFilter": %v}`, o.Filter != nil || len(o.Filters) != 0))
+	m["filter"] = &filterProperty
+
+	analytics.DefaultAmplitudeClient().Send(analytics.EventKindReportGenerated, slackUserID.WorkspaceID, slackUserID.ID, slackClient, m)
 
 	return nil
 }
@@ -347,23 +539,67 @@ func (reportUsecase *ReportUsecase) shareToTeams(ctx context.Context, token stri
 	report, renderedReport, skipPosting, err := reportUsecase.generateReport(&ctx, o, nil, logger, m)
 	if err != nil {
 		logger.Error("couldn't generate report", zap.Error(err))
-		_ = teams.SendFailedMessage(o, token)
 
-		return err
+		nErr := teams.SendFailedMessage(o, token)
+		if nErr != nil {
+			logger.Error("couldn't notify of failed report", zap.Error(nErr))
+
+			return err
+		}
+
+		// NOTE: User knows the report failed, so the message is moved to dead letters rather than redelivered to repeat the notice.
+		return &messagequeue.NonRetryableError{Err: err}
 	}
 
 	if skipPosting {
 		return nil
 	}
 
-	for _, page := range renderedReport.Pages {
+	err = reportUsecase.postToTeams(ctx, token, o, report, renderedReport, m)
+	if err != nil {
+		return err
+	}
+
+	completedIn := time.Now().UTC().Sub(startedAt)
+	logger.Info("completed sharing report", zap.Duration("completedIn", completedIn), zap.Int("totalPages", len(renderedReport.Pages)))
+
+	return nil
+}
+
+// postToTeams sends rendered pages (or a document holding all of them) to a conversation.
+func (reportUsecase *ReportUsecase) postToTeams(
+	ctx context.Context,
+	token string,
+	o *utils.ShareOptions,
+	report *domain.Report,
+	renderedReport *reportengine.RenderedReport,
+	m amplitude.Properties,
+) error {
+	logger := utils.WithContext(ctx, reportUsecase.logger)
+
+	pages := renderedReport.Pages
+	if renderedReport.Document != nil {
+		// NOTE: Pages are posted as a single document instead.
+		pages = nil
+
+		err := teams.SendFile(renderedReport.Document.Filename, renderedReport.Document.Data, o, token)
+		if err != nil {
+			logger.Error("couldn't upload document", zap.Error(err))
+
+			analytics.DefaultAmplitudeClient().Send(analytics.EventKindSendReportMessageFailed, o.WorkspaceID, o.UserID, teamsClient, m)
+			return err
+		}
+	}
+
+	for _, page := range pages {
 		comment := constants.FormatPageURL(report.GetWebURL(), page.ID)
 		if o.IsScheduled {
 			comment = fmt.Sprintf("<@%v>, %v", o.UserID, comment)
 		}
 
-		encodedReport := base64.StdEncoding.EncodeToString(page.ImageData)
-		err = teams.SendMessage(encodedReport, o, token)
+		image := page.Image(teamsClient)
+		encodedReport := base64.StdEncoding.EncodeToString(image.Data)
+		err := teams.SendMessage(encodedReport, image.ContentType, image.Width, image.Height, o, token)
 		if err != nil {
 			logger.Error("couldn't upload page", zap.Error(err), zap.String("pageID", page.ID))
 
@@ -372,12 +608,30 @@ func (reportUsecase *ReportUsecase) shareToTeams(ctx context.Context, token stri
 		}
 	}
 
-	completedIn := time.Now().UTC().Sub(startedAt)
-	logger.Info("completed sharing report", zap.Duration("completedIn", completedIn), zap.Int("totalPages", len(renderedReport.Pages)))
+	if renderedReport.Data != nil && renderedReport.Data.NotAllowed {
+		err := teams.SendTextMessage(constants.FormatDataExportNotAllowed(o.ReportName), o, token)
+		if err != nil {
+			logger.Error("couldn't post data export notice", zap.Error(err))
 
-	filterProperty := json.RawMessage(fmt.Sprintf(`{"withFilter": %v}`, o.Filter != nil))
+			analytics.DefaultAmplitudeClient().Send(analytics.EventKindSendReportMessageFailed, o.WorkspaceID, o.UserID, teamsClient, m)
+			return err
+		}
+	} else if renderedReport.Data != nil {
+		for _, f := range renderedReport.Data.Files {
+			err := teams.SendFile(f.Filename, f.Data, o, token)
+			if err != nil {
+				logger.Error("couldn't upload data", zap.Error(err), zap.String("filename", f.Filename))
+
+				analytics.DefaultAmplitudeClient().Send(analytics.EventKindSendReportMessageFailed, o.WorkspaceID, o.UserID, teamsClient, m)
+				return err
+			}
+		}
+	}
+
+	filterProperty := json.RawMessage(fmt.Sprintf(`{"withFilter": %v}`, o.Filter != nil || len(o.Filters) != 0))
 	m["filter"] = &filterProperty
 
 	analytics.DefaultAmplitudeClient().Send(analytics.EventKindReportGenerated, o.WorkspaceID, o.UserID, teamsClient, m)
+
 	return nil
 }
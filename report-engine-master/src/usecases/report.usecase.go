@@ -6,7 +6,18 @@ import (
 
 )
 
+// ReportDelivery is a recipient of a report rendered once for many.
+type ReportDelivery struct {
+	// User is set for Slack recipients only.
+	User *domain.User
+	// Token is a bot access token used to post the report.
+	Token   string
+	Options *utils.ShareOptions
+}
+
 // ReportUsecase represent the Report's usecases
 type ReportUsecase interface {
 	ShareReport(ctx context.Context, u *domain.User, token string, options *utils.ShareOptions) error
+	// DistributeReport renders a report once on behalf of u & posts it to each of ds; returned errors are ordered as ds.
+	DistributeReport(ctx context.Context, u *domain.User, options *utils.ShareOptions, ds []*ReportDelivery) []error
 }
@@ -0,0 +1,166 @@
+package messagequeue
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestRenderReportMessageTokensRoundTrip(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 16)
+	tokens := &Tokens{
+		BotAccessToken: "xoxb-bot",
+		PowerBIToken:   "eyJ-pbi",
+	}
+
+	tests := []struct {
+		name            string
+		sealKeys        map[string][]byte
+		sealKeyID       string
+		openKeys        map[string][]byte
+		openKeyID       string
+		plaintext       bool
+		acceptPlaintext bool
+		tamper          func(m *RenderReportMessage)
+		wantErr         error
+		wantAnyErr      bool
+	}{
+		{
+			name:      "sealed w/ current key",
+			sealKeys:  map[string][]byte{"a": oldKey},
+			sealKeyID: "a",
+			openKeys:  map[string][]byte{"a": oldKey},
+			openKeyID: "a",
+		},
+		{
+			name:      "sealed before rotation",
+			sealKeys:  map[string][]byte{"a": oldKey},
+			sealKeyID: "a",
+			openKeys:  map[string][]byte{"a": oldKey, "b": newKey},
+			openKeyID: "b",
+		},
+		{
+			name:      "sealed w/ unknown key",
+			sealKeys:  map[string][]byte{"b": newKey},
+			sealKeyID: "b",
+			openKeys:  map[string][]byte{"a": oldKey},
+			openKeyID: "a",
+			wantErr:   ErrUnknownKey,
+		},
+		{
+			name:      "unique ID changed",
+			sealKeys:  map[string][]byte{"a": oldKey},
+			sealKeyID: "a",
+			openKeys:  map[string][]byte{"a": oldKey},
+			openKeyID: "a",
+			tamper: func(m *RenderReportMessage) {
+				m.UniqueID = "another"
+			},
+			wantAnyErr: true,
+		},
+		{
+			name:            "plaintext within migration window",
+			openKeys:        map[string][]byte{"a": oldKey},
+			openKeyID:       "a",
+			plaintext:       true,
+			acceptPlaintext: true,
+		},
+		{
+			name:      "plaintext after migration window",
+			openKeys:  map[string][]byte{"a": oldKey},
+			openKeyID: "a",
+			plaintext: true,
+			wantErr:   ErrPlaintextSecrets,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := PostReportMessage{
+				RenderReportMessage: &RenderReportMessage{
+					ClientID:  "teams",
+					ReportID:  "report",
+					Pages:     []*PageMessage{{ID: "page"}},
+					ChannelID: "channel",
+					UniqueID:  "unique",
+				},
+			}
+			if tt.plaintext {
+				m.Token = tokens
+			} else {
+				k, err := NewKeyRing(tt.sealKeys, tt.sealKeyID)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				err = m.SealTokens(k, tokens)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			e := Envelope{
+				Kind: MessagePostReport,
+				Body: m,
+			}
+			err := DefaultRegistry.Validate(&e)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			j, err := json.Marshal(e)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.plaintext && bytes.Contains(j, []byte(tokens.PowerBIToken)) {
+				t.Fatalf("sealed message holds plaintext token: %s", j)
+			}
+
+			received := Envelope{}
+			err = json.Unmarshal(j, &received)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = DefaultRegistry.Decode(&received)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b, _ := received.Body.(*json.RawMessage)
+			r := PostReportMessage{}
+			err = json.Unmarshal(*b, &r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.tamper != nil {
+				tt.tamper(r.RenderReportMessage)
+			}
+
+			k, err := NewKeyRing(tt.openKeys, tt.openKeyID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := r.OpenTokens(k, tt.acceptPlaintext)
+			if tt.wantErr != nil || tt.wantAnyErr {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("OpenTokens() error = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if *got != *tokens {
+				t.Errorf("OpenTokens() = %+v, want %+v", got, tokens)
+			}
+		})
+	}
+}
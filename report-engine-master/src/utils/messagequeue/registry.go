@@ -0,0 +1,277 @@
+package messagequeue
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrUnknownKind will be returned for an Envelope of MessageKind missing in Registry.
+var ErrUnknownKind = fmt.Errorf("unknown message kind")
+
+// ErrUnsupportedVersion will be returned for an Envelope of a schema version newer than known to Registry.
+var ErrUnsupportedVersion = fmt.Errorf("unsupported schema version")
+
+// Validator is implemented by message types having invariants beyond their JSON layout.
+type Validator interface {
+	Validate() error
+}
+
+// Schema describes Envelope.Body layout of a MessageKind at a specific version.
+type Schema struct {
+	Kind    MessageKind
+	Version int
+	// New returns a pointer to an empty message of this Schema.
+	New func() interface{}
+	// Fields are top-level JSON fields this version adds to the previous one; fields of all versions must match a type returned by New of the latest one.
+	Fields []string
+	// Upgrade converts a body of the previous version to this one; nil means the previous version is a subset of this one.
+	Upgrade func(j json.RawMessage) (json.RawMessage, error)
+}
+
+// Registry maps MessageKind & schema version to a message type.
+//
+// NOTE: Bot & report engine are deployed independently, so a schema change must follow these rules:
+// - a new version is added to both copies of messages.go, a previous one is never changed;
+// - a new version lists fields it adds, so a field added to a message type w/o a new version fails NewRegistry;
+// - report engine (consumer) is deployed before bot (producer), as an Envelope of an unknown version is never decoded;
+// - an older Envelope is upgraded on receive, so it's never decoded into a type w/ missing fields.
+type Registry struct {
+	schemas map[MessageKind][]*Schema
+	// fields are top-level JSON fields known to each version of each MessageKind.
+	fields map[MessageKind][]map[string]bool
+}
+
+// NewRegistry creates a Registry. Versions of a MessageKind must start from 1 & go w/o gaps.
+func NewRegistry(ss ...*Schema) (*Registry, error) {
+	r := Registry{
+		schemas: map[MessageKind][]*Schema{},
+		fields:  map[MessageKind][]map[string]bool{},
+	}
+	for _, s := range ss {
+		r.schemas[s.Kind] = append(r.schemas[s.Kind], s)
+	}
+
+	for k, vs := range r.schemas {
+		sort.Slice(vs, func(i, j int) bool {
+			return vs[i].Version < vs[j].Version
+		})
+		known := map[string]bool{}
+		for i, s := range vs {
+			if s.Version != i+1 {
+				return nil, fmt.Errorf("schema versions of %v must go w/o gaps starting from 1", k)
+			}
+
+			fs := map[string]bool{}
+			for f := range known {
+				fs[f] = true
+			}
+
+			for _, f := range s.Fields {
+				fs[f] = true
+			}
+
+			known = fs
+			r.fields[k] = append(r.fields[k], fs)
+		}
+
+		// NOTE: Older versions are decoded into the latest type, so it must hold exactly fields of all versions.
+		latest := jsonFields(reflect.TypeOf(vs[len(vs)-1].New()))
+		for _, f := range latest {
+			if !known[f] {
+				return nil, fmt.Errorf("field %v of %v must be added by a new schema version", f, k)
+			}
+		}
+
+		if len(latest) != len(known) {
+			return nil, fmt.Errorf("fields of %v v%v don't match its type", k, len(vs))
+		}
+	}
+
+	return &r, nil
+}
+
+// jsonFields lists top-level JSON fields of a message type, including ones of embedded structs.
+func jsonFields(t reflect.Type) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	fs := []string(nil)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if f.Anonymous && ft.Kind() == reflect.Struct {
+			fs = append(fs, jsonFields(ft)...)
+
+			continue
+		}
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fs = append(fs, name)
+	}
+
+	return fs
+}
+
+func mustNewRegistry(ss ...*Schema) *Registry {
+	r, err := NewRegistry(ss...)
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+// CurrentVersion returns the latest schema version of k.
+func (r *Registry) CurrentVersion(k MessageKind) (int, error) {
+	vs, ok := r.schemas[k]
+	if !ok {
+		return 0, fmt.Errorf("%w: %v", ErrUnknownKind, k)
+	}
+
+	return vs[len(vs)-1].Version, nil
+}
+
+// Validate checks an outgoing Envelope against its Schema; unset Envelope.Version is set to the current one.
+func (r *Registry) Validate(e *Envelope) error {
+	v, err := r.CurrentVersion(e.Kind)
+	if err != nil {
+		return err
+	}
+
+	if e.Version == 0 {
+		e.Version = v
+	}
+
+	if e.Version > v {
+		return fmt.Errorf("%w: %v v%v", ErrUnsupportedVersion, e.Kind, e.Version)
+	}
+
+	j, err := json.Marshal(e.Body)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.decode(r.schemas[e.Kind][e.Version-1], j)
+
+	return err
+}
+
+// Decode checks an incoming Envelope against its Schema & upgrades Envelope.Body to the current version.
+// Envelope w/o version is considered to be of version 1, as it was produced before versioning was introduced.
+func (r *Registry) Decode(e *Envelope) error {
+	v, err := r.CurrentVersion(e.Kind)
+	if err != nil {
+		return err
+	}
+
+	if e.Version == 0 {
+		e.Version = 1
+	}
+
+	if e.Version > v {
+		return fmt.Errorf("%w: %v v%v", ErrUnsupportedVersion, e.Kind, e.Version)
+	}
+
+	j, err := json.Marshal(e.Body)
+	if err != nil {
+		return err
+	}
+
+	vs := r.schemas[e.Kind]
+	err = r.checkFields(vs[e.Version-1], j)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range vs[e.Version:] {
+		if s.Upgrade == nil {
+			continue
+		}
+
+		j, err = s.Upgrade(j)
+		if err != nil {
+			return fmt.Errorf("couldn't upgrade %v to v%v: %w", e.Kind, s.Version, err)
+		}
+	}
+
+	m, err := r.decode(vs[v-1], j)
+	if err != nil {
+		return err
+	}
+
+	j, err = json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	raw := json.RawMessage(j)
+	e.Body = &raw
+	e.Version = v
+
+	return nil
+}
+
+// NOTE: Unknown fields are rejected, so a field added w/o bumping schema version is never dropped silently.
+func (r *Registry) decode(s *Schema, j json.RawMessage) (interface{}, error) {
+	err := r.checkFields(s, j)
+	if err != nil {
+		return nil, err
+	}
+
+	m := s.New()
+	d := json.NewDecoder(bytes.NewReader(j))
+	d.DisallowUnknownFields()
+	err = d.Decode(m)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %v v%v: %w", s.Kind, s.Version, err)
+	}
+
+	v, ok := m.(Validator)
+	if ok {
+		err = v.Validate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v v%v: %w", s.Kind, s.Version, err)
+		}
+	}
+
+	return m, nil
+}
+
+// checkFields rejects top-level fields unknown to a version of s, as a type of the latest version accepts fields of all of them.
+func (r *Registry) checkFields(s *Schema, j json.RawMessage) error {
+	fs := map[string]json.RawMessage{}
+	err := json.Unmarshal(j, &fs)
+	if err != nil {
+		return fmt.Errorf("invalid %v v%v: %w", s.Kind, s.Version, err)
+	}
+
+	known := r.fields[s.Kind][s.Version-1]
+	for f := range fs {
+		if !known[f] {
+			return fmt.Errorf("invalid %v v%v: unknown field %q", s.Kind, s.Version, f)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package messagequeue
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+)
+
+// ErrUnknownKey will be returned for a SealedSecret sealed w/ a key missing in KeyRing.
+var ErrUnknownKey = fmt.Errorf("unknown encryption key")
+
+// SealedSecret is a value encrypted w/ AES-GCM by one of KeyRing keys.
+type SealedSecret struct {
+	KeyID      string `json:"keyID"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// KeyRing keeps AES keys by ID. New secrets are sealed w/ the current key, while the rest of keys only open secrets sealed before rotation.
+type KeyRing struct {
+	keys      map[string]cipher.AEAD
+	currentID string
+}
+
+// NewKeyRing creates a KeyRing; each key must be 16, 24 or 32 bytes long. An empty KeyRing can't seal or open anything.
+func NewKeyRing(keys map[string][]byte, currentID string) (*KeyRing, error) {
+	r := KeyRing{
+		keys:      map[string]cipher.AEAD{},
+		currentID: currentID,
+	}
+	for id, k := range keys {
+		b, err := aes.NewCipher(k)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %v: %w", id, err)
+		}
+
+		a, err := cipher.NewGCM(b)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %v: %w", id, err)
+		}
+
+		r.keys[id] = a
+	}
+
+	_, ok := r.keys[currentID]
+	if len(keys) > 0 && !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownKey, currentID)
+	}
+
+	return &r, nil
+}
+
+// Seal encrypts v marshalled to JSON; ad (e.g. a message ID) must be the same to open the secret.
+func (r *KeyRing) Seal(v interface{}, ad []byte) (*SealedSecret, error) {
+	a, ok := r.keys[r.currentID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownKey, r.currentID)
+	}
+
+	j, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	n := make([]byte, a.NonceSize())
+	_, err = rand.Read(n)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SealedSecret{
+		KeyID:      r.currentID,
+		Nonce:      n,
+		Ciphertext: a.Seal(nil, n, j, ad),
+	}, nil
+}
+
+// Open decrypts s into v.
+func (r *KeyRing) Open(s *SealedSecret, ad []byte, v interface{}) error {
+	a, ok := r.keys[s.KeyID]
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrUnknownKey, s.KeyID)
+	}
+
+	if len(s.Nonce) != a.NonceSize() {
+		return fmt.Errorf("invalid nonce size")
+	}
+
+	j, err := a.Open(nil, s.Nonce, s.Ciphertext, ad)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(j, v)
+}
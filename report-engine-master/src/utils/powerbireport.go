@@ -40,6 +40,38 @@ type ShareOptions struct {
 	SkipPosting       bool
 	RetryAttempt      int
 	PostReportMessage *messagequeue.PostReportMessage
+	// DistributeReportMessage is set instead of PostReportMessage for a report rendered once for many targets.
+	DistributeReportMessage *messagequeue.DistributeReportMessage
+	// OutputFormat is messagequeue.OutputFormatPNG if unset.
+	OutputFormat messagequeue.OutputFormat
+	// VisualName is a title of a single visual rendered instead of a whole page.
+	VisualName string
+	// BypassCache makes pages rendered anew instead of taken from a cache.
+	BypassCache bool
+	// DataFormat makes data of visuals exported along w/ pages; no data is exported if unset.
+	DataFormat messagequeue.DataFormat
+	// BookmarkName is a name of a report bookmark applied before each page is rendered; no bookmark is applied if unset.
+	BookmarkName string
+	// Filters are applied together along w/ Filter.
+	Filters []*domain.TypedFilter
+	// ReportParameters are values of parameters of a paginated report; a parameter w/o a value gets its default one.
+	ReportParameters []*ReportParameterOptions
+	// Layout is messagequeue.LayoutMaster if unset.
+	Layout messagequeue.Layout
+}
+
+// FilterString describes Filter & Filters applied to a report; it's empty if none of them are applied.
+func (o *ShareOptions) FilterString() string {
+	s := domain.DescribeFilters(o.Filters)
+	if o.Filter == nil {
+		return s
+	}
+
+	if s == "" {
+		return o.Filter.String()
+	}
+
+	return o.Filter.String() + "; " + s
 }
 
 // PageOptions holds page parameters.
@@ -48,6 +80,12 @@ type PageOptions struct {
 	Name string
 }
 
+// ReportParameterOptions holds a value of a paginated report parameter.
+type ReportParameterOptions struct {
+	Name  string
+	Value string
+}
+
 // WithAccessToken adds an access token to a report.ShareOptions.
 func WithAccessToken(o ShareOptions, accessToken string) *ShareOptions {
 	o.AccessToken = accessToken
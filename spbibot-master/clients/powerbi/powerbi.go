@@ -11,12 +11,14 @@ import (
 
 	"go.uber.org/zap"
 
-	
+
 )
 
 const (
-	reportsURI = "/reports"
-	groupsURI  = "/groups"
+	reportsURI    = "/reports"
+	groupsURI     = "/groups"
+	dashboardsURI = "/dashboards"
+	tilesURI      = "/tiles"
 )
 
 // MyWorkspaceGroup group with Reports from My Workspace
@@ -74,6 +76,7 @@ func (c *ServiceClient) GetGroupedReports(consumerID interface{}) (allReports do
 			return
 		}
 
+		rs.Value = append(rs.Value, c.getDashboardsWithTiles(consumerID, MyWorkspaceGroup.ID)...)
 		if len(rs.Value) > 0 {
 			allReportsMutex.Lock()
 			allReports[&MyWorkspaceGroup] = rs
@@ -108,6 +111,7 @@ func (c *ServiceClient) GetGroupedReports(consumerID interface{}) (allReports do
 					return
 				}
 
+				rs.Value = append(rs.Value, c.getDashboardsWithTiles(consumerID, g.ID)...)
 				if len(rs.Value) > 0 {
 					allReportsMutex.Lock()
 					allReports[g] = rs
@@ -128,9 +132,72 @@ func (c *ServiceClient) GetGroupedReports(consumerID interface{}) (allReports do
 	return allReports, err
 }
 
-// GetReport returns report by reportID
+// getDashboardsWithTiles returns dashboards of a group (or of My Workspace for an empty groupID), each of them followed by its tiles.
+// NOTE: Dashboards are listed along w/ reports, so a failure is logged & reports are still listed w/o them.
+func (c *ServiceClient) getDashboardsWithTiles(consumerID interface{}, groupID string) []domain.IReport {
+	ds, err := c.GetDashboards(consumerID, groupID)
+	if err != nil {
+		return nil
+	}
+
+	rs := []domain.IReport(nil)
+	for _, r := range ds.Value {
+		d := r.(*domain.Dashboard)
+		rs = append(rs, d)
+
+		ts, err := c.GetTiles(consumerID, groupID, d)
+		if err != nil {
+			continue
+		}
+
+		rs = append(rs, ts.Value...)
+	}
+
+	return rs
+}
+
+// GetDashboards returns dashboards in a group, or in My Workspace for an empty groupID
+func (c *ServiceClient) GetDashboards(consumerID interface{}, groupID string) (*domain.ReportsContainer, error) {
+	r, err := c.get(consumerID, dashboardsURIOf(groupID), func(b io.ReadCloser) (interface{}, error) {
+		return domain.DeserializeDashboards(b)
+	})
+	if err != nil {
+		c.logger.Error("couldn't get dashboards", zap.Error(err), zap.String("groupID", groupID))
+
+		return nil, err
+	}
+
+	return r.(*domain.ReportsContainer), nil
+}
+
+// GetTiles returns tiles of dashboard d in a group, or in My Workspace for an empty groupID
+func (c *ServiceClient) GetTiles(consumerID interface{}, groupID string, d *domain.Dashboard) (*domain.ReportsContainer, error) {
+	r, err := c.get(consumerID, tilesURIOf(groupID, d.ID), func(b io.ReadCloser) (interface{}, error) {
+		return domain.DeserializeTiles(b, d)
+	})
+	if err != nil {
+		c.logger.Error("couldn't get tiles", zap.Error(err), zap.String("groupID", groupID), zap.String("dashboardID", d.ID))
+
+		return nil, err
+	}
+
+	return r.(*domain.ReportsContainer), nil
+}
+
+// GetReport returns report by reportID; a dashboard or a tile is returned as a report, so it's posted the same way.
 func (c *ServiceClient) GetReport(consumerID interface{}, reportID string) (*domain.Report, error) {
-	r, err := c.get(consumerID, reportsURI+"/"+reportID, func(b io.ReadCloser) (interface{}, error) {
+	ref, err := domain.ParseReportID(reportID)
+	if err != nil {
+		c.logger.Error("couldn't parse report id", zap.Error(err), zap.String("reportID", reportID))
+
+		return nil, err
+	}
+
+	if ref.Type == domain.ReportTypeDashboard || ref.Type == domain.ReportTypeTile {
+		return c.getDashboardOrTile(consumerID, reportID, ref)
+	}
+
+	r, err := c.get(consumerID, reportsURI+"/"+ref.ID, func(b io.ReadCloser) (interface{}, error) {
 		return domain.DeserializeReport(b)
 	})
 	if err != nil {
@@ -142,6 +209,44 @@ func (c *ServiceClient) GetReport(consumerID interface{}, reportID string) (*dom
 	return r.(*domain.Report), nil
 }
 
+func (c *ServiceClient) getDashboardOrTile(consumerID interface{}, reportID string, ref *domain.ReportRef) (*domain.Report, error) {
+	dashboardID := ref.ID
+	if ref.Type == domain.ReportTypeTile {
+		dashboardID = ref.DashboardID
+	}
+
+	d, err := c.get(consumerID, dashboardsURI+"/"+dashboardID, func(b io.ReadCloser) (interface{}, error) {
+		return domain.DeserializeDashboard(b)
+	})
+	if err != nil {
+		c.logger.Error("couldn't get dashboard", zap.Error(err), zap.String("dashboardID", dashboardID), zap.Any("consumerID", consumerID))
+
+		return nil, err
+	}
+
+	r := domain.IReport(d.(*domain.Dashboard))
+	if ref.Type == domain.ReportTypeTile {
+		t, err := c.get(consumerID, tilesURIOf(MyWorkspaceGroup.ID, dashboardID)+"/"+ref.ID, func(b io.ReadCloser) (interface{}, error) {
+			return domain.DeserializeTile(b, d.(*domain.Dashboard))
+		})
+		if err != nil {
+			c.logger.Error("couldn't get tile", zap.Error(err), zap.String("tileID", ref.ID), zap.Any("consumerID", consumerID))
+
+			return nil, err
+		}
+
+		r = t.(*domain.Tile)
+	}
+
+	report := domain.Report{
+		ID:     reportID,
+		Name:   r.GetName(),
+		WebURL: r.GetWebURL(),
+	}
+
+	return &report, nil
+}
+
 // GetReports returns reports from My Workspace
 func (c *ServiceClient) GetReports(consumerID interface{}) (*domain.ReportsContainer, error) {
 	r, err := c.get(consumerID, reportsURI, func(b io.ReadCloser) (interface{}, error) {
@@ -185,7 +290,24 @@ func (c *ServiceClient) GetReportsInGroup(consumerID interface{}, groupID string
 }
 
 // GetPages retrieves report pages.
+// NOTE: A dashboard, a tile or a paginated report is rendered as a whole, so it has a single page of its own ID.
 func (c *ServiceClient) GetPages(customerID interface{}, reportID string) (*domain.PagesContainer, error) {
+	ref, err := domain.ParseReportID(reportID)
+	if err != nil {
+		c.logger.Error("couldn't parse report id", zap.Error(err), zap.String("reportID", reportID))
+
+		return nil, err
+	}
+
+	if ref.Type != domain.ReportTypeReport {
+		p := domain.Page{
+			Name:        ref.ID,
+			DisplayName: ref.Type.String(),
+		}
+
+		return &domain.PagesContainer{Value: []*domain.Page{&p}}, nil
+	}
+
 	r, err := c.get(customerID, pagesURI(reportID), func(b io.ReadCloser) (interface{}, error) {
 		return domain.DeserializePagesContainer(b)
 	})
@@ -237,6 +359,18 @@ func reportByGroupURI(groupID string) string {
 	return fmt.Sprintf("%s/%v%s", groupsURI, groupID, reportsURI)
 }
 
+func dashboardsURIOf(groupID string) string {
+	if groupID == "" {
+		return dashboardsURI
+	}
+
+	return fmt.Sprintf("%s/%v%s", groupsURI, groupID, dashboardsURI)
+}
+
+func tilesURIOf(groupID, dashboardID string) string {
+	return fmt.Sprintf("%s/%v%s", dashboardsURIOf(groupID), dashboardID, tilesURI)
+}
+
 func pagesURI(reportID string) string {
 	return fmt.Sprintf("%v/%v/pages", reportsURI, reportID)
 }
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/julienschmidt/httprouter"
+	"github.com/replaygaming/amplitude"
+	"go.uber.org/zap"
+
+
+)
+
+// NOTE: Runs both bot & report engine in a single process sharing an in-process or MySQL message queue & a Chrome instance.
+func main() {
+	fallbackLogger := log.New(os.Stderr, "ERROR ", log.Ldate|log.Ltime|log.Lshortfile|log.LUTC|log.Lmsgprefix)
+
+	baseProvider, err := config.NewDotenvProvider("./env/base.env")
+	if err != nil {
+		fallbackLogger.Println("couldn't create config provider:", err)
+
+		return
+	}
+
+	allInOneProvider, err := config.NewDotenvProvider("allinone.env")
+	if err != nil {
+		fallbackLogger.Println("couldn't create config provider:", err)
+
+		return
+	}
+
+	configProvider := config.NewProviderChain(allInOneProvider, baseProvider)
+	conf, err := config.NewBotConfig(configProvider)
+	if err != nil {
+		fallbackLogger.Println("couldn't create config:", err)
+
+		return
+	}
+
+	// NOTE: SQS queues are consumed by a separate report engine, so the ones kept by this process only are supported.
+	if conf.MessageQueue.Implementation != config.MQInProcess && conf.MessageQueue.Implementation != config.MQMySQL {
+		fallbackLogger.Println("unsupported message queue implementation:", conf.MessageQueue.Implementation)
+
+		return
+	}
+
+	// NOTE: Report engine keeps its own config package, so we read the same values once again.
+	engineConf, err := engineConfig.NewReportEngineConfig(configProvider)
+	if err != nil {
+		fallbackLogger.Println("couldn't create report engine config:", err)
+
+		return
+	}
+
+	awsSession, err := aws2.NewSessionBuilder().
+		WithAWSConfig(conf.AWS).
+		WithStdLogger(fallbackLogger).
+		NewSession()
+	if err != nil {
+		fallbackLogger.Println("couldn't create AWS session:", err)
+
+		return
+	}
+
+	cloudWatchLogs := cloudwatchlogs.New(awsSession)
+	logger, syncLogger, err := logging.NewBuilder().
+		WithHostConfig(conf.Host).
+		WithLoggerConfig(conf.Logger).
+		WithFallbackLogger(fallbackLogger).
+		WithCloudWatchLogs(cloudWatchLogs).
+		NewLogger()
+	if err != nil {
+		fallbackLogger.Println("couldn't create logger:", err)
+
+		return
+	}
+
+	defer syncLogger()
+
+	pid := os.Getpid()
+	logger = logger.With(zap.Int("pid", pid))
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		logger.Error("couldn't get hostname", zap.Error(err))
+	} else {
+		logger = logger.With(zap.String("host", hostname))
+	}
+
+	logger.Info("starting")
+
+	mysqlConn, err := db.InitDB("mysql", conf.DB)
+	if err != nil {
+		logger.Error("couldn't connect to DB", zap.Error(err))
+
+		return
+	}
+
+	defer func() {
+		logger.Debug("closing DB connection")
+		err := mysqlConn.Close()
+		if err != nil {
+			logger.Error("couldn't close DB connection", zap.Error(err))
+		}
+	}()
+
+	cdpEngine := reportengine.NewCDPReportEngine(engineConf.Browser, logger)
+	err = cdpEngine.Start(context.Background())
+	if err != nil {
+		logger.Error("couldn't start Chrome instance", zap.Error(err))
+
+		return
+	}
+
+	defer func() {
+		logger.Debug("stopping Chrome instance")
+		err := cdpEngine.Stop()
+		if err != nil && err != context.Canceled {
+			logger.Error("couldn't stop Chrome instance", zap.Error(err))
+		}
+	}()
+
+	// NOTE: Chrome instance is revived by the engine if it crashes, so alerts take its context each time.
+	browser.UseContext(cdpEngine.BrowserContext)
+
+	scheduledConfig := *conf.MessageQueue
+	scheduledConfig.URL = conf.MessageQueue.ScheduledURL
+
+	deadLetterConfig := *conf.MessageQueue
+	deadLetterConfig.URL = conf.MessageQueue.DeadLetterURL
+
+	queue := messagequeue.MessageQueue(nil)
+	scheduledQueue := messagequeue.MessageQueue(nil)
+	deadLetters := messagequeue.MessageQueue(nil)
+	if conf.MessageQueue.Implementation == config.MQMySQL {
+		// NOTE: Pending reports survive a restart, as they're kept in DB.
+		queue = messagequeue.NewMySQLMessageQueue(mysqlConn, conf.MessageQueue, logger)
+		scheduledQueue = messagequeue.NewMySQLMessageQueue(mysqlConn, &scheduledConfig, logger)
+		deadLetters = messagequeue.NewMySQLMessageQueue(mysqlConn, &deadLetterConfig, logger)
+	} else {
+		queue = messagequeue.NewInProcessMessageQueue(conf.MessageQueue.VisibilityTimeout)
+		scheduledQueue = messagequeue.NewInProcessMessageQueue(conf.MessageQueue.VisibilityTimeout)
+		deadLetters = messagequeue.NewInProcessMessageQueue(conf.MessageQueue.VisibilityTimeout)
+	}
+
+	queue = messagequeue.NewPrioritizedMessageQueue(queue, scheduledQueue, conf.MessageQueue.InteractiveRatio)
+
+	// NOTE: Both sides validate envelopes w/ their own copy of message schemas.
+	mq := messagequeue.NewValidatingMessageQueue(queue, messagequeue.DefaultRegistry)
+	engineMessageQueue := engineMQ.NewValidatingMessageQueue(newEngineMessageQueue(queue), engineMQ.DefaultRegistry)
+	engineDeadLetters := engineMQ.NewValidatingMessageQueue(newEngineMessageQueue(deadLetters), engineMQ.DefaultRegistry)
+
+	dbQueryTimeout := time.Duration(conf.DB.Timeout) * time.Second
+
+	keyRing, err := messagequeue.NewKeyRing(conf.MessageSecrets.Keys, conf.MessageSecrets.CurrentKeyID)
+	if err != nil {
+		logger.Error("couldn't create key ring", zap.Error(err))
+
+		return
+	}
+
+	mysqlUserRepository := mysqlDB.NewMysqlUserRepository(mysqlConn, logger)
+	mysqlUserTokenRepository := mysqlDB.NewMysqlUserTokenRepository(mysqlUserRepository, logger)
+	mysqlWorkspaceRepository := mysqlDB.NewMysqlWorkspaceRepository(mysqlConn, logger)
+	mysqlAlertRepository := mysqlDB.NewMysqlAlertRepository(mysqlConn, logger)
+	mysqlFilterRepository := mysqlDB.NewMySQLFilterRepository(mysqlConn, logger)
+	mysqlPostingTaskRepository := mysqlDB.NewMySQLPostReportTaskRepository(mysqlConn, logger)
+
+	powerBiClient := powerbi.NewServiceClient(conf.OAuthConfig, &conf.PowerBiClient, mysqlUserTokenRepository, logger)
+
+	botErrorHandler := useCase.NewBotErrorHandler(logger)
+	schedulerErrorHandler := useCase.NewSchedulerErrorHandler(mysqlPostingTaskRepository, logger, powerBiClient)
+	deletedChannelsHandler := useCase.NewDeletedChannelsHandler(mysqlPostingTaskRepository, mysqlWorkspaceRepository, logger)
+	activePagesFilter := useCase.NewActivePagesFilter(*powerBiClient, schedulerErrorHandler, mysqlWorkspaceRepository, logger, mysqlPostingTaskRepository)
+	userUsecase := useCase.NewUserUsecase(mysqlUserRepository, dbQueryTimeout, conf.DB.UserIDHashCost, conf.OAuthConfig, logger)
+	reportUsecase := useCase.NewReportUsecase(*powerBiClient, mysqlWorkspaceRepository, mysqlPostingTaskRepository, mysqlUserRepository, mq, keyRing, dbQueryTimeout, logger, conf.FeatureToggles, botErrorHandler, schedulerErrorHandler, activePagesFilter, deletedChannelsHandler)
+	workspaceUsecase := useCase.NewWorkspaceUsecase(mysqlWorkspaceRepository, dbQueryTimeout)
+	alertUsecase := useCase.NewAlertUsecase(mysqlAlertRepository, *powerBiClient, mysqlUserTokenRepository, mysqlWorkspaceRepository, dbQueryTimeout, logger, botErrorHandler)
+	filterUsecase := useCase.NewFilterUsecase(mysqlFilterRepository, dbQueryTimeout)
+
+	engineUserRepository := engineMySQL.NewMysqlUserRepository(mysqlConn, logger)
+	engineUserTokenRepository := engineMySQL.NewMysqlUserTokenRepository(engineUserRepository, logger)
+	engineWorkspaceRepository := engineMySQL.NewMysqlWorkspaceRepository(mysqlConn, logger)
+	enginePostingTaskRepository := engineMySQL.NewMySQLPostReportTaskRepository(mysqlConn, logger)
+	engineProcessedMessageRepository := engineMySQL.NewMySQLProcessedMessageRepository(mysqlConn, logger)
+
+	enginePowerBiClient := enginePowerBI.NewServiceClient(engineConf.OAuthConfig, &engineConf.PowerBiClient, engineUserTokenRepository, logger)
+
+	// NOTE: A token isn't refreshed here, so a report w/ an expired one is just rendered w/o cache; the same goes for a slow Power BI API.
+	resolveRefresh := func(ctx context.Context, o *engineUtils.ShareOptions) (time.Time, error) {
+		resolveCtx, cancelResolve := context.WithTimeout(ctx, engineConf.ImageCache.RefreshTimeout)
+		defer cancelResolve()
+
+		return enginePowerBiClient.GetDatasetRefreshTime(resolveCtx, nil, engineUseCase.Token{AccessToken: o.AccessToken}, o.ReportID)
+	}
+	reportEngine, err := reportengine.NewReportEngine(cdpEngine, engineConf, resolveRefresh, logger)
+	if err != nil {
+		logger.Error("couldn't create report engine", zap.Error(err))
+
+		return
+	}
+
+	reportengine.SetDefaultReportEngine(reportEngine)
+
+	retryStrategy := reportengine.NewRetryStrategy(logger, engineMessageQueue, engineConf.RetryStrategy)
+	engineUserUsecase := engineUseCase.NewUserUsecase(engineUserRepository, dbQueryTimeout, engineConf.DB.UserIDHashCost, engineConf.OAuthConfig, logger)
+	engineReportUsecase := engineUseCase.NewReportUsecase(*enginePowerBiClient, engineWorkspaceRepository, enginePostingTaskRepository, engineUserRepository, engineMessageQueue, dbQueryTimeout, logger, retryStrategy)
+	engineWorkspaceUsecase := engineUseCase.NewWorkspaceUsecase(engineWorkspaceRepository, dbQueryTimeout)
+
+	analytics.SetDefaultAmplitudeClient(amplitude.NewClient(conf.AmplitudeKey), logger)
+	engineAnalytics.SetDefaultAmplitudeClient(amplitude.NewClient(engineConf.AmplitudeKey), logger)
+
+	alertUsecase.ScheduleAlertsCheck(context.Background()) // schedule check alerts tasks
+
+	if conf.FeatureToggles.ReportScheduling {
+		scheduleTasksCtx, cancelScheduling := context.WithCancel(context.Background())
+		defer cancelScheduling()
+		utils.SafeRoutine(func() {
+			reportUsecase.StartScheduledPosting(scheduleTasksCtx)
+		})
+	}
+
+	handleMessagesCtx, cancelHandling := context.WithCancel(context.Background())
+	dispatcher := engineHandler.NewMessageDispatcher(engineMessageQueue, engineDeadLetters, engineConf.MessageHandler, logger)
+	engineKeyRing, err := engineMQ.NewKeyRing(engineConf.MessageSecrets.Keys, engineConf.MessageSecrets.CurrentKeyID)
+	if err != nil {
+		logger.Error("couldn't create key ring", zap.Error(err))
+
+		return
+	}
+
+	handleReportMessages := engineHandler.NewReportWorker(engineReportUsecase, engineUserUsecase, engineWorkspaceUsecase, engineProcessedMessageRepository, engineConf.Dedupe, engineKeyRing, engineConf.MessageSecrets, logger)
+	err = dispatcher.RegisterWorker(handleReportMessages)
+	if err != nil {
+		logger.Error("couldn't register worker", zap.Error(err))
+
+		return
+	}
+
+	distributeReports := engineHandler.NewDistributeReportWorker(engineReportUsecase, engineUserUsecase, engineWorkspaceUsecase, engineProcessedMessageRepository, engineConf.Dedupe, engineKeyRing, engineConf.MessageSecrets, logger)
+	err = dispatcher.RegisterWorker(distributeReports)
+	if err != nil {
+		logger.Error("couldn't register worker", zap.Error(err))
+
+		return
+	}
+
+	dispatcher.Start(handleMessagesCtx)
+	engineHandler.StartProcessedMessagesCleanup(handleMessagesCtx, engineProcessedMessageRepository, engineConf.Dedupe, logger)
+
+	defer func() {
+		logger.Debug("stopping message handling")
+		// NOTE: handleMessagesCtx is cancelled by now, while in-flight messages should be handled till shutdown timeout.
+		stopHandlingCtx, cancelStopping := context.WithTimeout(context.Background(), time.Duration(conf.Host.ShutdownTimeout)*time.Second)
+		defer cancelStopping()
+		err := dispatcher.Stop(stopHandlingCtx)
+		if err != nil && err != context.Canceled {
+			logger.Error("couldn't stop message handling", zap.Error(err))
+		}
+	}()
+
+	defer cancelHandling()
+
+	router := httprouter.New()
+	router.PanicHandler = newPanicHandler(logger)
+
+	httpHandler.NewSlashCommandHandler(router, reportUsecase, userUsecase, workspaceUsecase, alertUsecase, conf.Slack, &conf.OAuthConfig, conf.FeatureToggles, logger)
+	httpHandler.NewInteractionPayloadHandler(router, reportUsecase, userUsecase, workspaceUsecase, alertUsecase, filterUsecase, mq, keyRing, conf.Slack, &conf.OAuthConfig, conf.FeatureToggles, logger)
+	httpHandler.NewBotAuthHandler(router, workspaceUsecase, conf.BotAccessTokenConfig, logger)
+	httpHandler.NewEventsHandler(router, userUsecase, workspaceUsecase, conf.Slack, &conf.OAuthConfig, conf.FeatureToggles, logger)
+	httpHandler.ConfigureStaticFilesHandler(router)
+	httpHandler.ConfigureHealthCheck(router)
+
+	if conf.TestAPI.Enable {
+		httpHandler.ConfigureTestAPIHandler(router, reportUsecase, mq, keyRing, conf.TestAPI, logger)
+	}
+
+	pipeline := middlewares.NewRouterMiddleware(router)
+	pipeline = middlewares.NewCORSMiddleware(pipeline)
+	pipeline = middlewares.NewRequestLoggingMiddleware(pipeline, conf.RequestLogging, logger)
+	pipeline = middlewares.NewRequestIDMiddleware(pipeline)
+
+	httpAddress := utils.JoinHostPort("", conf.Server.Port)
+	httpsAddress := utils.JoinHostPort("", conf.Server.TLSPort)
+
+	shutdownRequested := make(chan os.Signal, 1)
+	go signal.Notify(shutdownRequested, os.Interrupt, syscall.SIGTERM)
+
+	switch conf.Host.Environment {
+	case config.EnvironmentDevelopment:
+		server := http.Server{
+			Addr:    httpAddress,
+			Handler: pipeline,
+		}
+
+		go func() {
+			err = server.ListenAndServe()
+			if err != nil && err != http.ErrServerClosed {
+				logger.Error("server error", zap.Error(err), zap.String("address", server.Addr))
+			}
+		}()
+
+		defer func() {
+			logger.Debug("stopping HTTP server")
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(conf.Host.ShutdownTimeout)*time.Second)
+			defer cancel()
+			err := server.Shutdown(ctx)
+			if err != nil {
+				logger.Warn("shut down w/ errors", zap.Error(err))
+			}
+		}()
+
+	case config.EnvironmentProduction:
+		httpPipeline := middlewares.NewHTTPSRedirectionMiddleware(pipeline, conf.Server.TLSPort)
+		httpServer := http.Server{
+			Addr:    httpAddress,
+			Handler: httpPipeline,
+		}
+
+		go func() {
+			err = httpServer.ListenAndServe()
+			if err != nil && err != http.ErrServerClosed {
+				logger.Error("server error", zap.Error(err), zap.String("address", httpServer.Addr))
+			}
+		}()
+
+		httpsServer := http.Server{
+			Addr:    httpsAddress,
+			Handler: pipeline,
+		}
+
+		go func() {
+			err = httpsServer.ListenAndServeTLS(conf.Server.Certificate, conf.Server.Key)
+			if err != nil && err != http.ErrServerClosed {
+				logger.Error("server error", zap.Error(err), zap.String("address", httpsServer.Addr))
+			}
+		}()
+
+		defer func() {
+			done := sync.WaitGroup{}
+			for _, s := range []*http.Server{&httpServer, &httpsServer} {
+				done.Add(1)
+				go func(s *http.Server) {
+					logger.Debug("stopping HTTP server")
+					ctx, cancel := context.WithTimeout(context.Background(), time.Duration(conf.Host.ShutdownTimeout)*time.Second)
+					defer cancel()
+					err := s.Shutdown(ctx)
+					if err != nil {
+						logger.Warn("shut down w/ errors", zap.Error(err))
+					}
+
+					done.Done()
+				}(s)
+			}
+
+			done.Wait()
+		}()
+
+	default:
+		logger.Error("unknown environment", zap.String("environment", string(conf.Host.Environment)))
+
+		return
+	}
+
+	logger.Info("ready")
+
+	s := <-shutdownRequested
+	logger.Info("shutting down", zap.String("signal", s.String()))
+}
+
+func newPanicHandler(l *zap.Logger) func(http.ResponseWriter, *http.Request, interface{}) {
+	return func(w http.ResponseWriter, r *http.Request, p interface{}) {
+		l := utils.WithContext(r.Context(), l)
+		l.Error("route handler panicked", zap.Any("panic", p))
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
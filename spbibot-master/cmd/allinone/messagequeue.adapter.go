@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+)
+
+// engineMessageQueue exposes bot's messagequeue.MessageQueue to report engine, which keeps its own copy of message types.
+type engineMessageQueue struct {
+	mq messagequeue.MessageQueue
+}
+
+func newEngineMessageQueue(mq messagequeue.MessageQueue) engineMQ.MessageQueue {
+	return &engineMessageQueue{
+		mq: mq,
+	}
+}
+
+func (q *engineMessageQueue) Push(ctx context.Context, e *engineMQ.Envelope, w engineMQ.WaitOption) error {
+	be, err := toBotEnvelope(e)
+	if err != nil {
+		return err
+	}
+
+	err = q.mq.Push(ctx, be, messagequeue.WaitOption(w))
+	if err != nil {
+		return err
+	}
+
+	e.ID = be.ID
+
+	return nil
+}
+
+func (q *engineMessageQueue) PushBatch(ctx context.Context, es []*engineMQ.Envelope, w engineMQ.WaitOption) error {
+	errs := make([]error, len(es))
+	bes := []*messagequeue.Envelope(nil)
+	is := []int(nil)
+	for i, e := range es {
+		be, err := toBotEnvelope(e)
+		if err != nil {
+			errs[i] = err
+
+			continue
+		}
+
+		bes = append(bes, be)
+		is = append(is, i)
+	}
+
+	err := q.mq.PushBatch(ctx, bes, messagequeue.WaitOption(w))
+	be, ok := err.(*messagequeue.BatchError)
+	for j, i := range is {
+		switch {
+		case ok:
+			errs[i] = be.Errs[j]
+
+		case err != nil:
+			errs[i] = err
+		}
+
+		if errs[i] == nil {
+			es[i].ID = bes[j].ID
+		}
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return &engineMQ.BatchError{
+				Errs: errs,
+			}
+		}
+	}
+
+	return nil
+}
+
+func (q *engineMessageQueue) Peek(ctx context.Context, w engineMQ.WaitOption) (*engineMQ.Envelope, error) {
+	be, err := q.mq.Peek(ctx, messagequeue.WaitOption(w))
+	if err == messagequeue.ErrNoMessages {
+		return nil, engineMQ.ErrNoMessages
+	} else if err != nil {
+		return nil, err
+	}
+
+	j, err := json.Marshal(be)
+	if err != nil {
+		return nil, err
+	}
+
+	e := engineMQ.Envelope{}
+	err = json.Unmarshal(j, &e)
+	if err != nil {
+		return nil, err
+	}
+
+	e.Handle = be.Handle
+	e.ReceiveCount = be.ReceiveCount
+
+	return &e, nil
+}
+
+func (q *engineMessageQueue) Delete(ctx context.Context, h string) error {
+	return q.mapError(q.mq.Delete(ctx, h))
+}
+
+func (q *engineMessageQueue) ChangeVisibility(ctx context.Context, h string, d time.Duration) error {
+	return q.mapError(q.mq.ChangeVisibility(ctx, h, d))
+}
+
+func (q *engineMessageQueue) Release(ctx context.Context) error {
+	return q.mq.Release(ctx)
+}
+
+func toBotEnvelope(e *engineMQ.Envelope) (*messagequeue.Envelope, error) {
+	j, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	be := messagequeue.Envelope{}
+	err = json.Unmarshal(j, &be)
+	if err != nil {
+		return nil, err
+	}
+
+	be.Delay = e.Delay
+
+	return &be, nil
+}
+
+func (q *engineMessageQueue) mapError(err error) error {
+	if err == messagequeue.ErrInvalidHandle {
+		return engineMQ.ErrInvalidHandle
+	}
+
+	return err
+}
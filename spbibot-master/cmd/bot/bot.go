@@ -123,14 +123,41 @@ func main() {
 
 	dbQueryTimeout := time.Duration(conf.DB.Timeout) * time.Second
 
+	keyRing, err := messagequeue.NewKeyRing(conf.MessageSecrets.Keys, conf.MessageSecrets.CurrentKeyID)
+	if err != nil {
+		logger.Error("couldn't create key ring", zap.Error(err))
+
+		return
+	}
+
+	scheduledConfig := *conf.MessageQueue
+	scheduledConfig.URL = conf.MessageQueue.ScheduledURL
+
 	mq := messagequeue.MessageQueue(nil)
+	scheduledMQ := messagequeue.MessageQueue(nil)
 	switch conf.MessageQueue.Implementation {
 	case config.MQSQS:
 		q := sqs.New(awsSession)
 		mq = messagequeue.NewSQSMessageQueue(q, conf.MessageQueue, logger)
+		if scheduledConfig.URL != "" {
+			scheduledMQ = messagequeue.NewSQSMessageQueue(q, &scheduledConfig, logger)
+		}
+
+		if conf.MessageQueue.DelayURL != "" {
+			forwardCtx, cancelForwarding := context.WithCancel(context.Background())
+			defer cancelForwarding()
+			utils.SafeRoutine(func() {
+				messagequeue.ForwardDelayed(forwardCtx, q, conf.MessageQueue, logger)
+			})
+		}
 
 	case config.MQInProcess:
-		mq = messagequeue.NewInProcessMessageQueue()
+		mq = messagequeue.NewInProcessMessageQueue(conf.MessageQueue.VisibilityTimeout)
+		scheduledMQ = messagequeue.NewInProcessMessageQueue(conf.MessageQueue.VisibilityTimeout)
+
+	case config.MQMySQL:
+		mq = messagequeue.NewMySQLMessageQueue(mysqlConn, conf.MessageQueue, logger)
+		scheduledMQ = messagequeue.NewMySQLMessageQueue(mysqlConn, &scheduledConfig, logger)
 
 	default:
 		logger.Error("unknown message queue implementation", zap.String("implementation", string(conf.MessageQueue.Implementation)))
@@ -138,12 +165,18 @@ func main() {
 		return
 	}
 
+	if scheduledMQ != nil {
+		mq = messagequeue.NewPrioritizedMessageQueue(mq, scheduledMQ, conf.MessageQueue.InteractiveRatio)
+	}
+
+	mq = messagequeue.NewValidatingMessageQueue(mq, messagequeue.DefaultRegistry)
+
 	botErrorHandler := useCase.NewBotErrorHandler(logger)
 	schedulerErrorHandler := useCase.NewSchedulerErrorHandler(mysqlPostingTaskRepository, logger, powerBiClient)
 	deletedChannelsHandler := useCase.NewDeletedChannelsHandler(mysqlPostingTaskRepository, mysqlWorkspaceRepository, logger)
 	activePagesFilter := useCase.NewActivePagesFilter(*powerBiClient, schedulerErrorHandler, mysqlWorkspaceRepository, logger, mysqlPostingTaskRepository)
 	userUsecase := useCase.NewUserUsecase(mysqlUserRepository, dbQueryTimeout, conf.DB.UserIDHashCost, conf.OAuthConfig, logger)
-	reportUsecase := useCase.NewReportUsecase(*powerBiClient, mysqlWorkspaceRepository, mysqlPostingTaskRepository, mysqlUserRepository, mq, dbQueryTimeout, logger, conf.FeatureToggles, botErrorHandler, schedulerErrorHandler, activePagesFilter, deletedChannelsHandler)
+	reportUsecase := useCase.NewReportUsecase(*powerBiClient, mysqlWorkspaceRepository, mysqlPostingTaskRepository, mysqlUserRepository, mq, keyRing, dbQueryTimeout, logger, conf.FeatureToggles, botErrorHandler, schedulerErrorHandler, activePagesFilter, deletedChannelsHandler)
 	workspaceUsecase := useCase.NewWorkspaceUsecase(mysqlWorkspaceRepository, dbQueryTimeout)
 	alertUsecase := useCase.NewAlertUsecase(mysqlAlertRepository, *powerBiClient, mysqlUserTokenRepository, mysqlWorkspaceRepository, dbQueryTimeout, logger, botErrorHandler)
 	filterUsecase := useCase.NewFilterUsecase(mysqlFilterRepository, dbQueryTimeout)
@@ -161,7 +194,12 @@ func main() {
 	analytics.SetDefaultAmplitudeClient(amplitude.NewClient(conf.AmplitudeKey), logger)
 
 	switch conf.MessageQueue.Implementation {
-	case config.MQSQS:
+	case config.MQSQS, config.MQMySQL:
+
+	case config.MQInProcess:
+		logger.Error("in-process message queue has no consumer, use cmd/allinone instead")
+
+		return
 
 	default:
 		logger.Error("unknown message queue implementation", zap.String("implementation", string(conf.MessageQueue.Implementation)))
@@ -173,14 +211,14 @@ func main() {
 	router.PanicHandler = newPanicHandler(logger)
 
 	httpHandler.NewSlashCommandHandler(router, reportUsecase, userUsecase, workspaceUsecase, alertUsecase, conf.Slack, &conf.OAuthConfig, conf.FeatureToggles, logger)
-	httpHandler.NewInteractionPayloadHandler(router, reportUsecase, userUsecase, workspaceUsecase, alertUsecase, filterUsecase, mq, conf.Slack, &conf.OAuthConfig, conf.FeatureToggles, logger)
+	httpHandler.NewInteractionPayloadHandler(router, reportUsecase, userUsecase, workspaceUsecase, alertUsecase, filterUsecase, mq, keyRing, conf.Slack, &conf.OAuthConfig, conf.FeatureToggles, logger)
 	httpHandler.NewBotAuthHandler(router, workspaceUsecase, conf.BotAccessTokenConfig, logger)
 	httpHandler.NewEventsHandler(router, userUsecase, workspaceUsecase, conf.Slack, &conf.OAuthConfig, conf.FeatureToggles, logger)
 	httpHandler.ConfigureStaticFilesHandler(router)
 	httpHandler.ConfigureHealthCheck(router)
 
 	if conf.TestAPI.Enable {
-		httpHandler.ConfigureTestAPIHandler(router, reportUsecase, mq, conf.TestAPI, logger)
+		httpHandler.ConfigureTestAPIHandler(router, reportUsecase, mq, keyRing, conf.TestAPI, logger)
 	}
 
 	pipeline := middlewares.NewRouterMiddleware(router)
@@ -0,0 +1,647 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+
+)
+
+const usage = `usage: mqctl <command> [flags]
+
+commands:
+  count   print the number of messages of each queue
+  peek    print messages w/o removing them
+  replay  push dead-lettered messages back to their queues
+  purge   delete messages of a kind or a workspace
+  push    push a postReport message
+
+Run "mqctl <command> -h" for command flags.
+NOTE: Scanned messages count as received ones & stay hidden from report engine until the scan is over.
+A scan which couldn't visit every message (e.g. the ones behind a hidden message of the same trace) fails,
+so run it again once report engine handles the rest of them.
+`
+
+const (
+	queueDefault    = "default"
+	queueScheduled  = "scheduled"
+	queueDeadLetter = "deadLetter"
+)
+
+// errIncompleteScan tells that a scan is over before visiting every message of a queue.
+var errIncompleteScan = errors.New("scan ended early")
+
+// redactedFields are body fields never printed, wherever they're nested.
+var redactedFields = map[string]bool{
+	"tokens":       true,
+	"sealedTokens": true,
+}
+
+type queues struct {
+	byName map[string]messagequeue.MessageQueue
+}
+
+func (qs *queues) get(name string) (messagequeue.MessageQueue, error) {
+	q, ok := qs.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown or unconfigured queue: %v", name)
+	}
+
+	return q, nil
+}
+
+func main() {
+	fallbackLogger := log.New(os.Stderr, "ERROR ", log.Ldate|log.Ltime|log.Lshortfile|log.LUTC|log.Lmsgprefix)
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	baseProvider, err := config.NewDotenvProvider("./env/base.env")
+	if err != nil {
+		fallbackLogger.Fatalln("couldn't create config provider:", err)
+	}
+
+	conf, err := config.NewBotConfig(baseProvider)
+	if err != nil {
+		fallbackLogger.Fatalln("couldn't create config:", err)
+	}
+
+	keyRing, err := messagequeue.NewKeyRing(conf.MessageSecrets.Keys, conf.MessageSecrets.CurrentKeyID)
+	if err != nil {
+		fallbackLogger.Fatalln("couldn't create key ring:", err)
+	}
+
+	// NOTE: Exit code is set last, so deferred cleanup runs before exiting.
+	exitCode := 0
+	defer func() {
+		os.Exit(exitCode)
+	}()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		fallbackLogger.Fatalln("couldn't create logger:", err)
+	}
+
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	scheduledConfig := *conf.MessageQueue
+	scheduledConfig.URL = conf.MessageQueue.ScheduledURL
+
+	deadLetterConfig := *conf.MessageQueue
+	deadLetterConfig.URL = conf.MessageQueue.DeadLetterURL
+
+	qs := queues{
+		byName: map[string]messagequeue.MessageQueue{},
+	}
+	switch conf.MessageQueue.Implementation {
+	case config.MQSQS:
+		awsSession, err := aws2.NewSessionBuilder().
+			WithAWSConfig(conf.AWS).
+			WithStdLogger(fallbackLogger).
+			NewSession()
+		if err != nil {
+			fallbackLogger.Fatalln("couldn't create AWS session:", err)
+		}
+
+		q := sqs.New(awsSession)
+		qs.byName[queueDefault] = messagequeue.NewSQSMessageQueue(q, conf.MessageQueue, logger)
+		if scheduledConfig.URL != "" {
+			qs.byName[queueScheduled] = messagequeue.NewSQSMessageQueue(q, &scheduledConfig, logger)
+		}
+
+		if deadLetterConfig.URL != "" {
+			qs.byName[queueDeadLetter] = messagequeue.NewSQSMessageQueue(q, &deadLetterConfig, logger)
+		}
+
+	case config.MQMySQL:
+		mysqlConn, err := db.InitDB("mysql", conf.DB)
+		if err != nil {
+			fallbackLogger.Fatalln("couldn't connect to DB:", err)
+		}
+
+		defer func(c *sql.DB) {
+			err := c.Close()
+			if err != nil {
+				fallbackLogger.Println("couldn't close DB connection:", err)
+			}
+		}(mysqlConn)
+
+		qs.byName[queueDefault] = messagequeue.NewMySQLMessageQueue(mysqlConn, conf.MessageQueue, logger)
+		qs.byName[queueScheduled] = messagequeue.NewMySQLMessageQueue(mysqlConn, &scheduledConfig, logger)
+		qs.byName[queueDeadLetter] = messagequeue.NewMySQLMessageQueue(mysqlConn, &deadLetterConfig, logger)
+
+	default:
+		fallbackLogger.Fatalln("unsupported message queue implementation:", conf.MessageQueue.Implementation)
+	}
+
+	ctx := context.Background()
+	args := os.Args[2:]
+	switch os.Args[1] {
+	case "count":
+		err = count(ctx, &qs)
+
+	case "peek":
+		err = peek(ctx, &qs, args)
+
+	case "replay":
+		err = replay(ctx, &qs, args)
+
+	case "purge":
+		err = purge(ctx, &qs, args)
+
+	case "push":
+		err = push(ctx, &qs, keyRing, args)
+
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		exitCode = 2
+
+		return
+	}
+
+	if err != nil {
+		fallbackLogger.Println(err)
+		exitCode = 1
+	}
+}
+
+func count(ctx context.Context, qs *queues) error {
+	ns := []string(nil)
+	for n := range qs.byName {
+		ns = append(ns, n)
+	}
+
+	sort.Strings(ns)
+	for _, n := range ns {
+		c, ok := qs.byName[n].(messagequeue.Counter)
+		if !ok {
+			return fmt.Errorf("queue %v can't be counted", n)
+		}
+
+		m, err := c.Count(ctx)
+		if err != nil {
+			return fmt.Errorf("couldn't count messages of %v: %w", n, err)
+		}
+
+		fmt.Printf("%v\t%v\n", n, m)
+	}
+
+	return nil
+}
+
+func peek(ctx context.Context, qs *queues, args []string) error {
+	fs := flag.NewFlagSet("peek", flag.ExitOnError)
+	queue := fs.String("queue", queueDefault, "queue to peek: default, scheduled or deadLetter")
+	kind := fs.String("kind", "", "print messages of this kind only (of dead-lettered one for deadLetter queue)")
+	workspace := fs.String("workspace", "", "print messages of this workspace only")
+	limit := fs.Int("limit", 10, "max number of messages to scan, 0 for all")
+	_ = fs.Parse(args)
+
+	q, err := qs.get(*queue)
+	if err != nil {
+		return err
+	}
+
+	kinds := map[messagequeue.MessageKind]int{}
+	err = scan(ctx, q, *limit, func(e *messagequeue.Envelope) (bool, error) {
+		k, ok, err := match(e, messagequeue.MessageKind(*kind), *workspace)
+		if err != nil || !ok {
+			return false, err
+		}
+
+		kinds[k]++
+
+		return false, printEnvelope(e)
+	})
+	if err != nil {
+		return err
+	}
+
+	ks := []string(nil)
+	for k := range kinds {
+		ks = append(ks, string(k))
+	}
+
+	sort.Strings(ks)
+	for _, k := range ks {
+		fmt.Printf("%v\t%v\n", k, kinds[messagequeue.MessageKind(k)])
+	}
+
+	return nil
+}
+
+func replay(ctx context.Context, qs *queues, args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	kind := fs.String("kind", "", "replay messages of this kind only")
+	workspace := fs.String("workspace", "", "replay messages of this workspace only")
+	limit := fs.Int("limit", 10, "max number of messages to scan, 0 for all")
+	_ = fs.Parse(args)
+
+	deadLetters, err := qs.get(queueDeadLetter)
+	if err != nil {
+		return err
+	}
+
+	n := 0
+	err = scan(ctx, deadLetters, *limit, func(e *messagequeue.Envelope) (bool, error) {
+		_, ok, err := match(e, messagequeue.MessageKind(*kind), *workspace)
+		if err != nil || !ok {
+			return false, err
+		}
+
+		m, err := unpackDeadLetter(e)
+		if err != nil {
+			return false, err
+		}
+
+		// NOTE: A scheduled message is replayed to the default queue if there's no scheduled one.
+		name := queueDefault
+		if m.Envelope.GetPriority() == messagequeue.PriorityScheduled {
+			_, ok := qs.byName[queueScheduled]
+			if ok {
+				name = queueScheduled
+			}
+		}
+
+		r := m.Envelope
+		r.ID = ""
+		r.Handle = ""
+		r.ReceiveCount = 0
+		q := messagequeue.NewValidatingMessageQueue(qs.byName[name], messagequeue.DefaultRegistry)
+		err = q.Push(ctx, r, messagequeue.Wait)
+		if err != nil {
+			return false, fmt.Errorf("couldn't replay message %v: %w", e.ID, err)
+		}
+
+		n++
+
+		return true, nil
+	})
+
+	fmt.Printf("replayed\t%v\n", n)
+
+	return err
+}
+
+func purge(ctx context.Context, qs *queues, args []string) error {
+	fs := flag.NewFlagSet("purge", flag.ExitOnError)
+	queue := fs.String("queue", queueDefault, "queue to purge: default, scheduled or deadLetter")
+	kind := fs.String("kind", "", "delete messages of this kind (of dead-lettered one for deadLetter queue)")
+	workspace := fs.String("workspace", "", "delete messages of this workspace")
+	limit := fs.Int("limit", 0, "max number of messages to scan, 0 for all")
+	_ = fs.Parse(args)
+
+	if *kind == "" && *workspace == "" {
+		return fmt.Errorf("either kind or workspace must be set")
+	}
+
+	q, err := qs.get(*queue)
+	if err != nil {
+		return err
+	}
+
+	n := 0
+	err = scan(ctx, q, *limit, func(e *messagequeue.Envelope) (bool, error) {
+		_, ok, err := match(e, messagequeue.MessageKind(*kind), *workspace)
+		if err != nil || !ok {
+			return false, err
+		}
+
+		n++
+
+		return true, nil
+	})
+
+	fmt.Printf("deleted\t%v\n", n)
+
+	return err
+}
+
+func push(ctx context.Context, qs *queues, k *messagequeue.KeyRing, args []string) error {
+	fs := flag.NewFlagSet("push", flag.ExitOnError)
+	clientID := fs.String("client", "slack", "client ID")
+	reportID := fs.String("report", "", "report ID")
+	reportName := fs.String("reportname", "", "report name")
+	pages := fs.String("pages", "", "comma-separated pages, each as ID or ID:name")
+	userID := fs.String("user", "", "user ID")
+	channelID := fs.String("channel", "", "channel ID")
+	workspaceID := fs.String("workspace", "", "workspace ID")
+	isScheduled := fs.Bool("scheduled", false, "push to scheduled queue as a scheduled report")
+	skipPosting := fs.Bool("skipposting", false, "render report w/o posting it")
+	filterTable := fs.String("filtertable", "", "filter table")
+	filterColumn := fs.String("filtercolumn", "", "filter column")
+	filterValue := fs.String("filtervalue", "", "filter value")
+	filterOperator := fs.String("filteroperator", "Is", "filter condition operator, e.g. Is or Contains")
+	format := fs.String("format", "", "output format, either png or pdf (or xlsx for a paginated report)")
+	visual := fs.String("visual", "", "title of a single visual to render instead of a whole page")
+	noCache := fs.Bool("nocache", false, "render pages anew even if they're cached")
+	data := fs.String("data", "", "format to attach data of visuals in, either csv or zip")
+	bookmark := fs.String("bookmark", "", "name (not display name) of a report bookmark to apply before each page")
+	filters := fs.String("filters", "", `JSON array of typed filters, e.g. [{"kind":"basic","target":{"table":"Store","column":"City"},"operator":"In","values":["Oslo"]}]`)
+	layout := fs.String("layout", "", "layout of pages, either master, mobilePortrait or mobileLandscape")
+	params := fs.String("params", "", "parameters of a paginated report as a query string, e.g. State=WA&Year=2021&Year=2022")
+	botToken := fs.String("bottoken", "", "bot access token of a non-Slack client, it's sealed before push")
+	powerBIToken := fs.String("pbitoken", "", "Power BI access token of a non-Slack client, it's sealed before push")
+	_ = fs.Parse(args)
+
+	pms := []*messagequeue.PageMessage(nil)
+	for _, p := range strings.Split(*pages, ",") {
+		if p == "" {
+			continue
+		}
+
+		ps := strings.SplitN(p, ":", 2)
+		pm := messagequeue.PageMessage{
+			ID: ps[0],
+		}
+		if len(ps) == 2 {
+			pm.Name = ps[1]
+		}
+
+		pms = append(pms, &pm)
+	}
+
+	m := messagequeue.PostReportMessage{
+		RenderReportMessage: &messagequeue.RenderReportMessage{
+			ClientID:     *clientID,
+			ReportID:     *reportID,
+			ReportName:   *reportName,
+			Pages:        pms,
+			UserID:       *userID,
+			ChannelID:    *channelID,
+			WorkspaceID:  *workspaceID,
+			UniqueID:     uuid.New().String(),
+			OutputFormat: messagequeue.OutputFormat(*format),
+			VisualName:   *visual,
+			BypassCache:  *noCache,
+			DataFormat:   messagequeue.DataFormat(*data),
+			BookmarkName: *bookmark,
+			Layout:       messagequeue.Layout(*layout),
+		},
+		IsScheduled: *isScheduled,
+		SkipPosting: *skipPosting,
+	}
+	if *filterTable != "" {
+		m.Filter = &messagequeue.FilterMessage{
+			Table:             *filterTable,
+			Column:            *filterColumn,
+			Value:             *filterValue,
+			ConditionOperator: *filterOperator,
+		}
+	}
+
+	if *filters != "" {
+		err := json.Unmarshal([]byte(*filters), &m.Filters)
+		if err != nil {
+			return fmt.Errorf("invalid filters: %w", err)
+		}
+	}
+
+	if *params != "" {
+		vs, err := url.ParseQuery(*params)
+		if err != nil {
+			return fmt.Errorf("invalid params: %w", err)
+		}
+
+		// NOTE: Parameters are sorted by name, so a message is the same for the same flags.
+		ns := []string(nil)
+		for n := range vs {
+			ns = append(ns, n)
+		}
+
+		sort.Strings(ns)
+		for _, n := range ns {
+			for _, v := range vs[n] {
+				m.ReportParameters = append(m.ReportParameters, &messagequeue.ReportParameterMessage{
+					Name:  n,
+					Value: v,
+				})
+			}
+		}
+	}
+
+	err := m.SealTokens(k, &messagequeue.Tokens{
+		BotAccessToken: *botToken,
+		PowerBIToken:   *powerBIToken,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't seal tokens: %w", err)
+	}
+
+	name, p := queueDefault, messagequeue.PriorityInteractive
+	if *isScheduled {
+		name, p = queueScheduled, messagequeue.PriorityScheduled
+	}
+
+	q, err := qs.get(name)
+	if err != nil {
+		return err
+	}
+
+	e := messagequeue.Envelope{
+		Kind:     messagequeue.MessagePostReport,
+		Body:     m,
+		TraceID:  uuid.New().String(),
+		Priority: p,
+	}
+	err = messagequeue.NewValidatingMessageQueue(q, messagequeue.DefaultRegistry).Push(ctx, &e, messagequeue.Wait)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("pushed\t%v\t%v\n", m.UniqueID, e.TraceID)
+
+	return nil
+}
+
+// scan visits up to limit messages of q; a message is deleted if visit returns true, otherwise it's returned to q once the scan is over.
+// NOTE: Visited messages stay hidden until the scan is over, so each of them is visited once. As messages behind a hidden one of the same group aren't received, errIncompleteScan is returned if some messages weren't visited.
+func scan(ctx context.Context, q messagequeue.MessageQueue, limit int, visit func(e *messagequeue.Envelope) (bool, error)) (err error) {
+	hs := []string(nil)
+	visited := map[string]bool{}
+	defer func() {
+		for _, h := range hs {
+			err2 := q.ChangeVisibility(ctx, h, 0)
+			if err2 != nil && err == nil {
+				err = fmt.Errorf("couldn't return message: %w", err2)
+			}
+		}
+
+		err2 := q.Release(ctx)
+		if err2 != nil && err == nil {
+			err = fmt.Errorf("couldn't return messages: %w", err2)
+		}
+	}()
+
+	for n := 0; limit == 0 || n < limit; n++ {
+		e, err := q.Peek(ctx, messagequeue.NoWait)
+		if err == messagequeue.ErrNoMessages {
+			return checkScanned(ctx, q, len(hs))
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// NOTE: A message is received again once its visibility timeout is over, so the scan took too long to visit the rest of them.
+		if visited[e.ID] {
+			hs = append(hs, e.Handle)
+
+			return fmt.Errorf("%w: message %v became visible again", errIncompleteScan, e.ID)
+		}
+
+		visited[e.ID] = true
+
+		del, err := visit(e)
+		if err != nil || !del {
+			hs = append(hs, e.Handle)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if del {
+			err = q.Delete(ctx, e.Handle)
+			if err != nil {
+				return fmt.Errorf("couldn't delete message %v: %w", e.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkScanned tells whether messages of q besides hidden ones were visited; q w/o messagequeue.Counter is assumed to be.
+func checkScanned(ctx context.Context, q messagequeue.MessageQueue, hidden int) error {
+	c, ok := q.(messagequeue.Counter)
+	if !ok {
+		return nil
+	}
+
+	n, err := c.Count(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't count messages: %w", err)
+	}
+
+	if n > hidden {
+		return fmt.Errorf("%w: %v messages weren't visited (delayed, in flight or behind hidden ones)", errIncompleteScan, n-hidden)
+	}
+
+	return nil
+}
+
+// match tells whether e (or a message dead-lettered in it) is of kind & workspace; empty kind or workspace matches any.
+func match(e *messagequeue.Envelope, kind messagequeue.MessageKind, workspace string) (messagequeue.MessageKind, bool, error) {
+	m := e
+	if e.Kind == messagequeue.MessageDeadLetter {
+		d, err := unpackDeadLetter(e)
+		if err != nil {
+			return "", false, err
+		}
+
+		m = d.Envelope
+	}
+
+	if kind != "" && m.Kind != kind {
+		return m.Kind, false, nil
+	}
+
+	if workspace == "" {
+		return m.Kind, true, nil
+	}
+
+	b := struct {
+		WorkspaceID string `json:"workspaceID"`
+	}{}
+	err := json.Unmarshal(*m.Body.(*json.RawMessage), &b)
+	if err != nil {
+		return "", false, fmt.Errorf("couldn't unpack message %v: %w", e.ID, err)
+	}
+
+	return m.Kind, b.WorkspaceID == workspace, nil
+}
+
+func unpackDeadLetter(e *messagequeue.Envelope) (*messagequeue.DeadLetterMessage, error) {
+	p, err := e.Unpack(func(_ *messagequeue.Envelope, j json.RawMessage) (interface{}, error) {
+		m := messagequeue.DeadLetterMessage{}
+		err := json.Unmarshal(j, &m)
+		if err != nil {
+			return nil, err
+		}
+
+		return &m, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't unpack dead letter %v: %w", e.ID, err)
+	}
+
+	m := p.(*messagequeue.DeadLetterMessage)
+	if m.Envelope == nil {
+		return nil, fmt.Errorf("dead letter %v has no envelope", e.ID)
+	}
+
+	return m, nil
+}
+
+func printEnvelope(e *messagequeue.Envelope) error {
+	j, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	v := map[string]interface{}{}
+	err = json.Unmarshal(j, &v)
+	if err != nil {
+		return err
+	}
+
+	v["receiveCount"] = e.ReceiveCount
+	redact(v)
+
+	j, err = json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(j))
+
+	return nil
+}
+
+func redact(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, f := range v {
+			if redactedFields[k] {
+				v[k] = "<redacted>"
+
+				continue
+			}
+
+			redact(f)
+		}
+
+	case []interface{}:
+		for _, f := range v {
+			redact(f)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "top-level tokens",
+			in:   `{"reportID":"r","tokens":{"powerBIToken":"p"},"sealedTokens":"s"}`,
+			want: `{"reportID":"r","tokens":"<redacted>","sealedTokens":"<redacted>"}`,
+		},
+		{
+			name: "nested in dead letter",
+			in:   `{"envelope":{"body":{"tokens":{"botAccessToken":"b"},"channelID":"c"}},"error":"e"}`,
+			want: `{"envelope":{"body":{"tokens":"<redacted>","channelID":"c"}},"error":"e"}`,
+		},
+		{
+			name: "inside arrays",
+			in:   `{"targets":[{"sealedTokens":"s","channelID":"c"}]}`,
+			want: `{"targets":[{"sealedTokens":"<redacted>","channelID":"c"}]}`,
+		},
+		{
+			name: "nothing to redact",
+			in:   `{"reportID":"r","pages":[{"id":"p"}]}`,
+			want: `{"reportID":"r","pages":[{"id":"p"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, want := map[string]interface{}{}, map[string]interface{}{}
+			if err := json.Unmarshal([]byte(tt.in), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+
+			redact(got)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("redact() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	postReport := `{"id":"1","kind":"postReport","version":1,"body":{"workspaceID":"T1"}}`
+	deadLetter := `{"id":"2","kind":"deadLetter","version":1,"body":{"envelope":` + postReport + `,"error":"e"}}`
+
+	tests := []struct {
+		name      string
+		envelope  string
+		kind      messagequeue.MessageKind
+		workspace string
+		wantKind  messagequeue.MessageKind
+		want      bool
+		wantErr   bool
+	}{
+		{
+			name:     "any message",
+			envelope: postReport,
+			wantKind: messagequeue.MessagePostReport,
+			want:     true,
+		},
+		{
+			name:     "kind matches",
+			envelope: postReport,
+			kind:     messagequeue.MessagePostReport,
+			wantKind: messagequeue.MessagePostReport,
+			want:     true,
+		},
+		{
+			name:     "kind differs",
+			envelope: postReport,
+			kind:     messagequeue.MessageDistributeReport,
+			wantKind: messagequeue.MessagePostReport,
+		},
+		{
+			name:      "workspace matches",
+			envelope:  postReport,
+			workspace: "T1",
+			wantKind:  messagequeue.MessagePostReport,
+			want:      true,
+		},
+		{
+			name:      "workspace differs",
+			envelope:  postReport,
+			workspace: "T2",
+			wantKind:  messagequeue.MessagePostReport,
+		},
+		{
+			name:      "dead letter matched by its envelope",
+			envelope:  deadLetter,
+			kind:      messagequeue.MessagePostReport,
+			workspace: "T1",
+			wantKind:  messagequeue.MessagePostReport,
+			want:      true,
+		},
+		{
+			name:     "dead letter w/o envelope",
+			envelope: `{"id":"3","kind":"deadLetter","version":1,"body":{"error":"e"}}`,
+			wantErr:  true,
+		},
+		{
+			name:      "body isn't an object",
+			envelope:  `{"id":"4","kind":"postReport","version":1,"body":"x"}`,
+			workspace: "T1",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := messagequeue.Envelope{}
+			if err := json.Unmarshal([]byte(tt.envelope), &e); err != nil {
+				t.Fatal(err)
+			}
+
+			kind, ok, err := match(&e, tt.kind, tt.workspace)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("match() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if kind != tt.wantKind || ok != tt.want {
+				t.Errorf("match() = %v, %v, want %v, %v", kind, ok, tt.wantKind, tt.want)
+			}
+		})
+	}
+}
+
+// scannedQueue returns Envelope w/ ids one by one & counts count messages.
+type scannedQueue struct {
+	messagequeue.MessageQueue
+	ids   []string
+	count int
+}
+
+func (q *scannedQueue) Peek(context.Context, messagequeue.WaitOption) (*messagequeue.Envelope, error) {
+	if len(q.ids) == 0 {
+		return nil, messagequeue.ErrNoMessages
+	}
+
+	e := messagequeue.Envelope{ID: q.ids[0], Handle: q.ids[0]}
+	q.ids = q.ids[1:]
+
+	return &e, nil
+}
+
+func (q *scannedQueue) Delete(context.Context, string) error {
+	return nil
+}
+
+func (q *scannedQueue) ChangeVisibility(context.Context, string, time.Duration) error {
+	return nil
+}
+
+func (q *scannedQueue) Release(context.Context) error {
+	return nil
+}
+
+func (q *scannedQueue) Count(context.Context) (int, error) {
+	return q.count, nil
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name        string
+		ids         []string
+		count       int
+		del         bool
+		wantVisited int
+		wantErr     error
+	}{
+		{
+			name:        "all visited",
+			ids:         []string{"1", "2"},
+			count:       2,
+			wantVisited: 2,
+		},
+		{
+			name:        "all deleted",
+			ids:         []string{"1", "2"},
+			del:         true,
+			wantVisited: 2,
+		},
+		{
+			name:        "messages behind hidden ones",
+			ids:         []string{"1"},
+			count:       3,
+			wantVisited: 1,
+			wantErr:     errIncompleteScan,
+		},
+		{
+			name:        "message visible again",
+			ids:         []string{"1", "2", "1", "3"},
+			count:       3,
+			wantVisited: 2,
+			wantErr:     errIncompleteScan,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := scannedQueue{ids: tt.ids, count: tt.count}
+			visited := 0
+			err := scan(context.Background(), &q, 0, func(e *messagequeue.Envelope) (bool, error) {
+				visited++
+
+				return tt.del, nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("scan() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if visited != tt.wantVisited {
+				t.Errorf("visited %v messages, want %v", visited, tt.wantVisited)
+			}
+		})
+	}
+}
@@ -9,6 +9,10 @@ const (
 	AppName = "Power BI integration"
 	// ActionIDVisual is the action id of the visual selection dropdown.
 	ActionIDVisual = "visual"
+	// ActionIDBookmark is the action id of the bookmark selection dropdown.
+	ActionIDBookmark = "bookmark"
+	// ActionIDSavedFilter is the action id of the saved filter dropdown of a scheduled report.
+	ActionIDSavedFilter = "savedFilter"
 	// ActionIDReport is the action id of the report selection dropdown.
 	ActionIDReport = "report"
 	// ActionIDScheduledReport is the action id of the report selection dropdown.
@@ -87,6 +91,38 @@ const (
 	ActionIDDayOfMonth = "dayOfMonth"
 	// ActionIDPages is the action id of the pages input.
 	ActionIDPages = "pages"
+	// ActionIDShareMode is the action id of the "page or single visual" radio buttons.
+	ActionIDShareMode = "shareMode"
+	// ActionIDAttachData is the action id of the "attach data" radio buttons.
+	ActionIDAttachData = "attachData"
+	// ActionIDLayout is the action id of the "layout" radio buttons.
+	ActionIDLayout = "layout"
+	// ActionIDOutputFormat is the action id of the "format" radio buttons.
+	ActionIDOutputFormat = "outputFormat"
+	// ActionIDFilterKind is the action id of the filter type dropdown.
+	ActionIDFilterKind = "filterKind"
+	// ActionIDFilterOperator is the action id of the operator dropdown of a typed filter.
+	ActionIDFilterOperator = "filterOperator"
+	// ActionIDFilterValues is the action id of the "one value per line" input.
+	ActionIDFilterValues = "filterValues"
+	// ActionIDRelativeCount is the action id of the count of time units input of a relative filter.
+	ActionIDRelativeCount = "relativeCount"
+	// ActionIDRelativeUnit is the action id of the time unit dropdown of a relative filter.
+	ActionIDRelativeUnit = "relativeUnit"
+	// ActionIDIncludeToday is the action id of the "include today" checkbox.
+	ActionIDIncludeToday = "includeToday"
+	// ActionIDItemCount is the action id of the item count input of a top N filter.
+	ActionIDItemCount = "itemCount"
+	// ActionIDOrderByTable is the action id of the table name input of a top N filter.
+	ActionIDOrderByTable = "orderByTable"
+	// ActionIDOrderByMeasure is the action id of the measure name input of a top N filter.
+	ActionIDOrderByMeasure = "orderByMeasure"
+	// ActionIDTupleColumns is the action id of the "one column per line" input of a tuple filter.
+	ActionIDTupleColumns = "tupleColumns"
+	// ActionIDTupleValues is the action id of the "one combination per line" input of a tuple filter.
+	ActionIDTupleValues = "tupleValues"
+	// ActionIDAddAnotherFilter is the action id of the "Add another filter" button.
+	ActionIDAddAnotherFilter = "addAnotherFilter"
 	// ActionIDWorkspacePBI is the action id of the PBI workspace input
 	ActionIDWorkspacePBI = "workspacesPBI"
 	// BlockIDSaveFilter is the block id of the "save edited filter" checkbox.
@@ -169,6 +205,46 @@ const (
 	HintScheduleReport = "Schedule automatic report posting."
 	// BlockIDPages is the block id of the pages input.
 	BlockIDPages = "Pages"
+	// BlockIDShareMode is the block id of the "page or single visual" radio buttons.
+	BlockIDShareMode = "ShareMode"
+	// BlockIDAttachData is the block id of the "attach data" radio buttons.
+	BlockIDAttachData = "AttachData"
+	// BlockIDLayout is the block id of the "layout" radio buttons.
+	BlockIDLayout = "Layout"
+	// BlockIDOutputFormat is the block id of the "format" radio buttons.
+	BlockIDOutputFormat = "OutputFormat"
+	// BlockIDBookmark is the block id of the bookmark selection dropdown.
+	BlockIDBookmark = "Bookmark"
+	// BlockIDSavedFilter is the block id of the saved filter dropdown of a scheduled report.
+	BlockIDSavedFilter = "SavedFilter"
+	// BlockIDFilterKind is the block id of the filter type dropdown.
+	BlockIDFilterKind = "FilterKind"
+	// BlockIDFilterOperator is the block id of the operator dropdown of a typed filter.
+	BlockIDFilterOperator = "FilterOperator"
+	// BlockIDFilterValues is the block id of the "one value per line" input.
+	BlockIDFilterValues = "FilterValues"
+	// BlockIDRelativeCount is the block id of the count of time units input of a relative filter.
+	BlockIDRelativeCount = "RelativeCount"
+	// BlockIDRelativeUnit is the block id of the time unit dropdown of a relative filter.
+	BlockIDRelativeUnit = "RelativeUnit"
+	// BlockIDIncludeToday is the block id of the "include today" checkbox.
+	BlockIDIncludeToday = "IncludeToday"
+	// BlockIDItemCount is the block id of the item count input of a top N filter.
+	BlockIDItemCount = "ItemCount"
+	// BlockIDOrderByTable is the block id of the table name input of a top N filter.
+	BlockIDOrderByTable = "OrderByTable"
+	// BlockIDOrderByMeasure is the block id of the measure name input of a top N filter.
+	BlockIDOrderByMeasure = "OrderByMeasure"
+	// BlockIDTupleColumns is the block id of the "one column per line" input of a tuple filter.
+	BlockIDTupleColumns = "TupleColumns"
+	// BlockIDTupleValues is the block id of the "one combination per line" input of a tuple filter.
+	BlockIDTupleValues = "TupleValues"
+	// BlockIDAddAnotherFilter is the block id of the "Add another filter" button.
+	BlockIDAddAnotherFilter = "AddAnotherFilter"
+	// BlockIDAddedFilter is the block id of a summary of a filter added already.
+	BlockIDAddedFilter = "AddedFilter"
+	// BlockIDFilterWarning is the block id of a warning shown when another filter can't be added.
+	BlockIDFilterWarning = "FilterWarning"
 	// BlockIDWorkspacePBI is the block id of the PBI workspaces input
 	BlockIDWorkspacePBI = "WorkspacesPBI"
 	// HeaderChooseReport is the header text for report sharing modal.
@@ -239,6 +315,22 @@ const (
 	PlaceholderDayOfMonth = "Day of month"
 	// PlaceholderPages is the placeholder of the pages input.
 	PlaceholderPages = "Pages"
+	// PlaceholderVisual is the placeholder of the single visual input.
+	PlaceholderVisual = "Visual"
+	// PlaceholderAttachData is the label of the "attach data" radio buttons.
+	PlaceholderAttachData = "Data"
+	// PlaceholderLayout is the label of the "layout" radio buttons.
+	PlaceholderLayout = "Layout"
+	// PlaceholderOutputFormat is the label of the "format" radio buttons.
+	PlaceholderOutputFormat = "Format"
+	// PlaceholderBookmark is the placeholder of the bookmark input.
+	PlaceholderBookmark = "Bookmark"
+	// PlaceholderSavedFilter is the placeholder of the saved filter dropdown of a scheduled report.
+	PlaceholderSavedFilter = "Saved filter"
+	// HintSavedFilter explains date tokens of a saved filter of a scheduled report.
+	HintSavedFilter = "Values like {{today-7d}} are replaced w/ dates each time the report is posted."
+	// PlaceholderFilterKind is the placeholder of the filter type dropdown.
+	PlaceholderFilterKind = "Filter type"
 	// PlaceholderPBIWorkspaces is the placeholder of the PBI workspaces input
 	PlaceholderPBIWorkspaces = "Workspaces"
 	// SignInLabel composes text for sign-in button.
@@ -305,12 +397,52 @@ const (
 	WarningFilterExists = "A saved filter named like this already exists. Choose another name."
 	// ValueAddSecondFilter is the value of the "Add filter" button.
 	ValueAddSecondFilter = "AddFilterButtonValue"
+	// ValueAddAnotherFilter is the value of the "Add another filter" button.
+	ValueAddAnotherFilter = "addAnotherFilter"
+	// ValueIncludeToday is the value of the "include today" checkbox.
+	ValueIncludeToday = "includeToday"
 	// ValueSearchReport is the value of the "search report" button
 	ValueSearchReport = "searchReportValue"
 	// ValueSearchWorkspace is the value of the "search report" button
 	ValueSearchWorkspace = "searchWorkspaceValue"
+	// WarningVisualNeedsSinglePage is the error shown when a user is sharing a single visual of several pages.
+	WarningVisualNeedsSinglePage = "Choose exactly one page to share a single visual of it."
+	// WarningChooseVisual is the error shown when a user is sharing a single visual, but it isn't chosen yet.
+	WarningChooseVisual = "Choose a visual of this page, or share the whole page."
+	// WarningNoVisuals is the warning shown when a page has no visuals w/ a title to choose from.
+	WarningNoVisuals = "This page has no visuals w/ a title. Share the whole page instead."
+	// WarningIncompleteFilter is the warning shown when a user is adding another filter before filling in the current one.
+	WarningIncompleteFilter = "Fill in the filter before adding another one."
+	// WarningNotPositiveNumber is the validation error shown when a count isn't a positive whole number.
+	WarningNotPositiveNumber = "Enter a positive whole number."
+	// WarningInvalidTupleColumns is the validation error shown when a column of a tuple filter isn't named like Table.Column.
+	WarningInvalidTupleColumns = "Enter one column per line, named like Table.Column."
+	// WarningInvalidTupleValues is the validation error shown when a combination of a tuple filter doesn't hold a value for each column.
+	WarningInvalidTupleValues = "Enter one combination per line, holding a value for each column separated by commas."
 	// WarningScheduleExists is the error shown when a user is adding a posting schedule w/ same parameters.
 	WarningScheduleExists = "A posting schedule for this report, channel, & periodicity already exists."
+	// ValueShareModePage is the value of the "whole page" radio button.
+	ValueShareModePage = "page"
+	// ValueShareModeVisual is the value of the "single visual" radio button.
+	ValueShareModeVisual = "visual"
+	// ValueAttachDataNone is the value of the "images only" radio button.
+	ValueAttachDataNone = "none"
+	// ValueAttachDataCSV is the value of the "CSV files" radio button.
+	ValueAttachDataCSV = "csv"
+	// ValueAttachDataZIP is the value of the "ZIP archive" radio button.
+	ValueAttachDataZIP = "zip"
+	// ValueLayoutMaster is the value of the "desktop" radio button.
+	ValueLayoutMaster = "master"
+	// ValueLayoutMobilePortrait is the value of the "phone, portrait" radio button.
+	ValueLayoutMobilePortrait = "mobilePortrait"
+	// ValueLayoutMobileLandscape is the value of the "phone, landscape" radio button.
+	ValueLayoutMobileLandscape = "mobileLandscape"
+	// ValueOutputFormatPNG is the value of the "images" radio button.
+	ValueOutputFormatPNG = "png"
+	// ValueOutputFormatPDF is the value of the "PDF document" radio button.
+	ValueOutputFormatPDF = "pdf"
+	// ValueOutputFormatXLSX is the value of the "Excel workbook" radio button.
+	ValueOutputFormatXLSX = "xlsx"
 	// ValueApplyFilter is the value of the "apply a filter" button.
 	ValueApplyFilter = "applyFilter"
 	// ValueOperationAnd is the value "And" of the radio buttons group.
@@ -355,6 +487,36 @@ const (
 	LabelViewReport = "View the report in Power BI"
 	// LabelApplyFilter is the label of the "apply a filter" checkbox.
 	LabelApplyFilter = "Apply a filter"
+	// LabelShareModePage is the label of the "whole page" radio button.
+	LabelShareModePage = "Whole page"
+	// LabelShareModeVisual is the label of the "single visual" radio button.
+	LabelShareModeVisual = "Single visual"
+	// LabelAttachDataNone is the label of the "images only" radio button.
+	LabelAttachDataNone = "Images only"
+	// LabelAttachDataCSV is the label of the "CSV files" radio button.
+	LabelAttachDataCSV = "Attach data of each visual as a CSV file"
+	// LabelAttachDataZIP is the label of the "ZIP archive" radio button.
+	LabelAttachDataZIP = "Attach data of all visuals as a ZIP archive"
+	// LabelLayoutMaster is the label of the "desktop" radio button.
+	LabelLayoutMaster = "Desktop"
+	// LabelLayoutMobilePortrait is the label of the "phone, portrait" radio button.
+	LabelLayoutMobilePortrait = "Phone, portrait"
+	// LabelLayoutMobileLandscape is the label of the "phone, landscape" radio button.
+	LabelLayoutMobileLandscape = "Phone, landscape"
+	// HintLayout is the hint of the "layout" radio buttons.
+	HintLayout = "A page w/o a mobile layout is posted as designed for desktop."
+	// LabelOutputFormatPNG is the label of the "images" radio button.
+	LabelOutputFormatPNG = "An image of each page"
+	// LabelOutputFormatPDF is the label of the "PDF document" radio button.
+	LabelOutputFormatPDF = "A single PDF document of all pages"
+	// LabelOutputFormatXLSX is the label of the "Excel workbook" radio button.
+	LabelOutputFormatXLSX = "An Excel workbook"
+	// LabelLoadingVisuals is shown while visuals of a page are being listed.
+	LabelLoadingVisuals = "⏳ Loading visuals..."
+	// LabelLoadingBookmarks is shown while bookmarks of a report are being listed.
+	LabelLoadingBookmarks = "⏳ Loading bookmarks..."
+	// LabelAddAnotherFilter is the label of the "Add another filter" button.
+	LabelAddAnotherFilter = "Add another filter"
 	// LabelDayOfMonthLast is the label for the "last day of month" option.
 	LabelDayOfMonthLast            = "Last"
 	LabelPBIWorkspacesList         = "Power BI Workspaces"
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE queueMessages
+(
+    sequence      BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    id            VARCHAR(64)     NOT NULL,
+    kind          VARCHAR(64)     NOT NULL,
+    body          MEDIUMBLOB      NOT NULL,
+    traceID       VARCHAR(64)     NOT NULL DEFAULT '',
+    receiptHandle VARCHAR(64)     NULL,
+    receiveCount  INT             NOT NULL DEFAULT 0,
+    visibleAt     DATETIME(6)     NOT NULL,
+    createdAt     DATETIME(6)     NOT NULL,
+    PRIMARY KEY (sequence),
+    UNIQUE KEY queueMessages_id (id),
+    UNIQUE KEY queueMessages_receiptHandle (receiptHandle),
+    KEY queueMessages_visibleAt (visibleAt, sequence),
+    KEY queueMessages_kind (kind, sequence)
+);
+
+-- +goose Down
+DROP TABLE queueMessages;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE queueMessages
+    ADD COLUMN queue VARCHAR(64) NOT NULL DEFAULT 'default' AFTER sequence,
+    DROP INDEX queueMessages_visibleAt,
+    ADD INDEX queueMessages_queue_visibleAt (queue, visibleAt, sequence),
+    DROP INDEX queueMessages_kind,
+    ADD INDEX queueMessages_queue_kind_traceID (queue, kind, traceID, sequence);
+
+-- +goose Down
+ALTER TABLE queueMessages
+    DROP INDEX queueMessages_queue_kind_traceID,
+    ADD INDEX queueMessages_kind (kind, sequence),
+    DROP INDEX queueMessages_queue_visibleAt,
+    ADD INDEX queueMessages_visibleAt (visibleAt, sequence),
+    DROP COLUMN queue;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE processedMessages
+(
+    id        VARCHAR(128) NOT NULL,
+    outcome   VARCHAR(16)  NOT NULL,
+    expiresAt DATETIME(6)  NOT NULL,
+    createdAt DATETIME(6)  NOT NULL,
+    updatedAt DATETIME(6)  NOT NULL,
+    PRIMARY KEY (id),
+    KEY processedMessages_expiresAt (expiresAt)
+);
+
+-- +goose Down
+DROP TABLE processedMessages;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE postReportTasks
+    ADD COLUMN outputFormat VARCHAR(8) NULL;
+
+-- +goose Down
+ALTER TABLE postReportTasks
+    DROP COLUMN outputFormat;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE postReportTasks
+    ADD COLUMN visualName VARCHAR(255) NULL AFTER pageIDs;
+
+-- +goose Down
+ALTER TABLE postReportTasks
+    DROP COLUMN visualName;
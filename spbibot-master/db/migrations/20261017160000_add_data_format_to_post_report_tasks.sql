@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE postReportTasks
+    ADD COLUMN dataFormat VARCHAR(16) NULL AFTER visualName;
+
+-- +goose Down
+ALTER TABLE postReportTasks
+    DROP COLUMN dataFormat;
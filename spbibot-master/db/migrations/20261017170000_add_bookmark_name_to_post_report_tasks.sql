@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE postReportTasks
+    ADD COLUMN bookmarkName VARCHAR(255) NULL AFTER dataFormat;
+
+-- +goose Down
+ALTER TABLE postReportTasks
+    DROP COLUMN bookmarkName;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE postReportTasks
+    ADD COLUMN filters JSON NULL AFTER bookmarkName;
+
+-- +goose Down
+ALTER TABLE postReportTasks
+    DROP COLUMN filters;
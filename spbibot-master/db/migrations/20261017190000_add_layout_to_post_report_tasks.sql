@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE postReportTasks
+    ADD COLUMN layout VARCHAR(16) NULL AFTER filters;
+
+-- +goose Down
+ALTER TABLE postReportTasks
+    DROP COLUMN layout;
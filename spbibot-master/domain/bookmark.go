@@ -0,0 +1,7 @@
+package domain
+
+// Bookmark represents Report bookmark.
+type Bookmark struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
@@ -20,6 +20,8 @@ var (
 	ErrEmptyBotToken = errors.New("bot Access Token is empty")
 	// ErrUnknownReportType will throw if they try to get something different from the known report types (such as report or dashboard)
 	ErrUnknownReportType = errors.New("unknown report type")
+	// ErrInvalidReportID is returned if an ID of a dashboard or a tile lacks a part of it
+	ErrInvalidReportID = errors.New("invalid report id")
 	// ErrUnknownModal will throw if they try to get something different from the known modals (such as SendReport or SaveAlert)
 	ErrUnknownModal = errors.New("unknown modal")
 	// ErrReportDoesntExist is thrown when report doesn't exist
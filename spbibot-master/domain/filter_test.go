@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResolveFilterTokens(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, time.March, 31, 23, 30, 0, 0, berlin)
+
+	tests := []struct {
+		name   string
+		now    time.Time
+		filter TypedFilter
+		want   TypedFilter
+	}{
+		{
+			name: "today",
+			now:  now,
+			filter: TypedFilter{
+				Kind:   TypedFilterBasic,
+				Values: []string{"{{today}}", "{{ today }}"},
+			},
+			want: TypedFilter{
+				Kind:   TypedFilterBasic,
+				Values: []string{"2026-03-31T00:00:00", "2026-03-31T00:00:00"},
+			},
+		},
+		{
+			name: "days & weeks",
+			now:  now,
+			filter: TypedFilter{
+				Kind:   TypedFilterBasic,
+				Values: []string{"{{today-7d}}", "{{today+1d}}", "{{today - 2w}}"},
+			},
+			want: TypedFilter{
+				Kind:   TypedFilterBasic,
+				Values: []string{"2026-03-24T00:00:00", "2026-04-01T00:00:00", "2026-03-17T00:00:00"},
+			},
+		},
+		{
+			name: "months clamped to the last day",
+			now:  now,
+			filter: TypedFilter{
+				Kind:   TypedFilterBasic,
+				Values: []string{"{{today-1m}}", "{{today+2m}}", "{{today-13m}}"},
+			},
+			want: TypedFilter{
+				Kind:   TypedFilterBasic,
+				Values: []string{"2026-02-28T00:00:00", "2026-05-31T00:00:00", "2025-02-28T00:00:00"},
+			},
+		},
+		{
+			name: "years clamped on a leap day",
+			now:  time.Date(2028, time.February, 29, 12, 0, 0, 0, time.UTC),
+			filter: TypedFilter{
+				Kind:   TypedFilterBasic,
+				Values: []string{"{{today-1y}}", "{{today+4y}}"},
+			},
+			want: TypedFilter{
+				Kind:   TypedFilterBasic,
+				Values: []string{"2027-02-28T00:00:00", "2032-02-29T00:00:00"},
+			},
+		},
+		{
+			name: "conditions & tuples",
+			now:  now,
+			filter: TypedFilter{
+				Kind: TypedFilterAdvanced,
+				Conditions: []*FilterCondition{
+					{Operator: "GreaterThanOrEqual", Value: "{{today-1m}}"},
+					{Operator: "IsBlank"},
+				},
+				Tuples: [][]string{{"West", "{{today}}"}},
+			},
+			want: TypedFilter{
+				Kind: TypedFilterAdvanced,
+				Conditions: []*FilterCondition{
+					{Operator: "GreaterThanOrEqual", Value: "2026-02-28T00:00:00"},
+					{Operator: "IsBlank"},
+				},
+				Tuples: [][]string{{"West", "2026-03-31T00:00:00"}},
+			},
+		},
+		{
+			name: "no tokens",
+			now:  now,
+			filter: TypedFilter{
+				Kind:   TypedFilterBasic,
+				Values: []string{"West", "{{yesterday}}", "{{today-1q}}"},
+			},
+			want: TypedFilter{
+				Kind:   TypedFilterBasic,
+				Values: []string{"West", "{{yesterday}}", "{{today-1q}}"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.filter
+			got := ResolveFilterTokens([]*TypedFilter{&f}, tt.now)
+			if len(got) != 1 {
+				t.Fatalf("resolved %v filters, want 1", len(got))
+			}
+
+			if !reflect.DeepEqual(*got[0], tt.want) {
+				t.Errorf("ResolveFilterTokens() = %+v, want %+v", *got[0], tt.want)
+			}
+		})
+	}
+}
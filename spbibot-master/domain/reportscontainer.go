@@ -28,8 +28,12 @@ func (reportContainer *ReportsContainer) UnmarshalJSON(b []byte) (err error) {
 	for _, raw := range reportContainer.RawValue {
 		var i IReport
 		switch reportContainer.Type {
-		case report:
+		case ReportTypeReport:
 			i = &Report{}
+		case ReportTypeDashboard:
+			i = &Dashboard{}
+		case ReportTypeTile:
+			i = &Tile{}
 		default:
 			return ErrUnknownReportType
 		}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -11,7 +12,7 @@ import (
 	"go.uber.org/zap/zapcore"
 	"golang.org/x/oauth2"
 
-	
+
 )
 
 const (
@@ -95,6 +96,7 @@ type BaseConfig struct {
 	MessageQueue         *MessageQueueConfig
 	MessageHandler       *MessageHandlerConfig
 	AWS                  *AWSConfig
+	MessageSecrets       *MessageSecretsConfig
 }
 
 // BotConfig controls cmd/bot behavior.
@@ -328,11 +330,13 @@ const (
 	MQInProcess MessageQueueImplementation = "inProcess"
 	// MQSQS enables SQS implementation.
 	MQSQS MessageQueueImplementation = "sqs"
+	// MQMySQL enables MySQL implementation.
+	MQMySQL MessageQueueImplementation = "mysql"
 )
 
 func parseImplementation(s string) (MessageQueueImplementation, error) {
 	switch MessageQueueImplementation(s) {
-	case MQInProcess, MQSQS:
+	case MQInProcess, MQSQS, MQMySQL:
 		return MessageQueueImplementation(s), nil
 
 	default:
@@ -342,10 +346,15 @@ func parseImplementation(s string) (MessageQueueImplementation, error) {
 
 // MessageQueueConfig controls MQ behavior.
 type MessageQueueConfig struct {
-	Implementation  MessageQueueImplementation `envconfig:"MQ_IMPLEMENTATION"`
-	URL             string                     `envconfig:"MQ_URL"`
-	BatchSize       uint                       `envconfig:"MQ_BATCHSIZE"`
-	PollingInterval time.Duration              `envconfig:"MQ_POLLINGINTERVAL"`
+	Implementation    MessageQueueImplementation `envconfig:"MQ_IMPLEMENTATION"`
+	URL               string                     `envconfig:"MQ_URL"`
+	ScheduledURL      string                     `envconfig:"MQ_SCHEDULEDURL"`
+	DeadLetterURL     string                     `envconfig:"MQ_DEADLETTERURL"`
+	DelayURL          string                     `envconfig:"MQ_DELAYURL"`
+	BatchSize         uint                       `envconfig:"MQ_BATCHSIZE"`
+	PollingInterval   time.Duration              `envconfig:"MQ_POLLINGINTERVAL"`
+	VisibilityTimeout time.Duration              `envconfig:"MQ_VISIBILITYTIMEOUT"`
+	InteractiveRatio  uint                       `envconfig:"MQ_INTERACTIVERATIO"`
 }
 
 func newMessageQueueConfig(p Provider) (*MessageQueueConfig, error) {
@@ -360,17 +369,62 @@ func newMessageQueueConfig(p Provider) (*MessageQueueConfig, error) {
 		return nil, err
 	}
 
+	t := getDuration(p, prefix+"_VISIBILITYTIMEOUT", 30*time.Minute)
+	if t <= 0 {
+		return nil, fmt.Errorf("visibility timeout must be positive")
+	}
+
+	// NOTE: For MySQL implementation, URLs are queue names within a single table.
+	u := p.Get(prefix+"_URL", "")
+	su := p.Get(prefix+"_SCHEDULEDURL", "")
+	d := p.Get(prefix+"_DEADLETTERURL", "")
+	// NOTE: Delayed messages wait in a standard SQS queue, since FIFO ones don't support per-message delays.
+	dl := p.Get(prefix+"_DELAYURL", "")
+	if i == MQMySQL {
+		if u == "" {
+			u = "default"
+		}
+
+		if su == "" {
+			su = "scheduled"
+		}
+
+		if d == "" {
+			d = "deadLetter"
+		}
+	}
+
+	// NOTE: A batch of 0 messages would never receive anything.
+	b := getUint(p, prefix+"_BATCHSIZE", 8)
+	if b == 0 {
+		return nil, fmt.Errorf("batch size must be positive")
+	}
+
 	return &MessageQueueConfig{
-		Implementation:  i,
-		URL:             p.Get(prefix+"_URL", ""),
-		BatchSize:       getUint(p, prefix+"BATCHSIZE", 8),
-		PollingInterval: getDuration(p, prefix+"POLLINGINTERVAL", 20*time.Second),
+		Implementation:    i,
+		URL:               u,
+		ScheduledURL:      su,
+		DeadLetterURL:     d,
+		DelayURL:          dl,
+		BatchSize:         b,
+		PollingInterval:   getDuration(p, prefix+"_POLLINGINTERVAL", 20*time.Second),
+		VisibilityTimeout: t,
+		InteractiveRatio:  getUint(p, prefix+"_INTERACTIVERATIO", 4),
 	}, nil
 }
 
 // MessageHandlerConfig controls message handler behavior.
 type MessageHandlerConfig struct {
 	ConcurrencyLevel uint `envconfig:"MESSAGEHANDLER_CONCURRENCYLEVEL"`
+	// KindConcurrencyLevels overrides ConcurrencyLevel for specific message kinds.
+	KindConcurrencyLevels map[string]uint `envconfig:"MESSAGEHANDLER_KINDCONCURRENCYLEVELS"`
+	// MaxInFlight limits the number of received messages not handled yet, across all message kinds.
+	MaxInFlight         uint          `envconfig:"MESSAGEHANDLER_MAXINFLIGHT"`
+	MaxReceiveCount     int           `envconfig:"MESSAGEHANDLER_MAXRECEIVECOUNT"`
+	VisibilityExtension time.Duration `envconfig:"MESSAGEHANDLER_VISIBILITYEXTENSION"`
+	RetryDelay          time.Duration `envconfig:"MESSAGEHANDLER_RETRYDELAY"`
+	// BusyDelay postpones a message of a kind whose workers & backlog are full, so it doesn't hold messages of other kinds.
+	BusyDelay time.Duration `envconfig:"MESSAGEHANDLER_BUSYDELAY"`
 }
 
 func newMessageHandlerConfig(p Provider) (*MessageHandlerConfig, error) {
@@ -381,8 +435,80 @@ func newMessageHandlerConfig(p Provider) (*MessageHandlerConfig, error) {
 		return nil, fmt.Errorf("concurrency level must be set")
 	}
 
+	kl := map[string]uint(nil)
+	err := json.Unmarshal([]byte(p.Get(prefix+"_KINDCONCURRENCYLEVELS", `{}`)), &kl)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid message kind concurrency levels")
+	}
+
+	for k, n := range kl {
+		if n == 0 {
+			return nil, fmt.Errorf("concurrency level of %v must be positive", k)
+		}
+	}
+
+	f := getUint(p, prefix+"_MAXINFLIGHT", l)
+	if f == 0 {
+		return nil, fmt.Errorf("max in-flight messages must be positive")
+	}
+
+	c := getInt(p, prefix+"_MAXRECEIVECOUNT", 5)
+	if c <= 0 {
+		return nil, fmt.Errorf("max receive count must be positive")
+	}
+
+	e := getDuration(p, prefix+"_VISIBILITYEXTENSION", 5*time.Minute)
+	if e <= 0 {
+		return nil, fmt.Errorf("visibility extension must be positive")
+	}
+
 	return &MessageHandlerConfig{
-		ConcurrencyLevel: l,
+		ConcurrencyLevel:      l,
+		KindConcurrencyLevels: kl,
+		MaxInFlight:           f,
+		MaxReceiveCount:       c,
+		VisibilityExtension:   e,
+		RetryDelay:            getDuration(p, prefix+"_RETRYDELAY", 30*time.Second),
+		BusyDelay:             getDuration(p, prefix+"_BUSYDELAY", 5*time.Second),
+	}, nil
+}
+
+// MessageSecretsConfig controls encryption of secrets carried in queue messages.
+type MessageSecretsConfig struct {
+	// Keys are AES keys by ID, they must be the same as ones of report engine.
+	Keys         map[string][]byte `envconfig:"MESSAGESECRETS_KEYS"`
+	CurrentKeyID string            `envconfig:"MESSAGESECRETS_CURRENTKEYID"`
+}
+
+// NOTE: Bot seals tokens of each message it produces, so a current key must be set.
+func newMessageSecretsConfig(p Provider) (*MessageSecretsConfig, error) {
+	const prefix = "MESSAGESECRETS"
+
+	eks := map[string]string(nil)
+	err := json.Unmarshal([]byte(p.Get(prefix+"_KEYS", `{}`)), &eks)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid keys")
+	}
+
+	ks := map[string][]byte{}
+	for id, ek := range eks {
+		k, err := base64.StdEncoding.DecodeString(ek)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key %v", id)
+		}
+
+		ks[id] = k
+	}
+
+	id := p.Get(prefix+"_CURRENTKEYID", "")
+	_, ok := ks[id]
+	if !ok {
+		return nil, fmt.Errorf("current key must be one of keys")
+	}
+
+	return &MessageSecretsConfig{
+		Keys:         ks,
+		CurrentKeyID: id,
 	}, nil
 }
 
@@ -531,6 +657,13 @@ func NewBaseConfig(p Provider) (*BaseConfig, error) {
 
 	c.AWS = a
 
+	ms, err := newMessageSecretsConfig(p)
+	if err != nil {
+		return nil, err
+	}
+
+	c.MessageSecrets = ms
+
 	return &c, nil
 }
 
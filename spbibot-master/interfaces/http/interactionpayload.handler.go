@@ -31,6 +31,7 @@ type interactionCommandHandler struct {
 	alertUsecase     usecases.AlertUsecase
 	filterUsecase    usecases.FilterUsecase
 	mq               messagequeue.MessageQueue
+	keyRing          *messagequeue.KeyRing
 	oauthConfig      *oauth.Config
 	featuresConfig   *config.FeatureTogglesConfig
 	logger           *zap.Logger
@@ -45,6 +46,7 @@ func NewInteractionPayloadHandler(
 	a usecases.AlertUsecase,
 	f usecases.FilterUsecase,
 	m messagequeue.MessageQueue,
+	k *messagequeue.KeyRing,
 	s *config.SlackConfig,
 	o *oauth.Config,
 	t *config.FeatureTogglesConfig,
@@ -58,6 +60,7 @@ func NewInteractionPayloadHandler(
 		filterUsecase:    f,
 		oauthConfig:      o,
 		mq:               m,
+		keyRing:          k,
 		featuresConfig:   t,
 		logger:           l,
 	}
@@ -126,8 +129,14 @@ func (h *interactionCommandHandler) handleBlockActions(ctx context.Context, w ht
 	if strings.HasPrefix(a.BlockID, constants.BlockIDReport) {
 		return h.handleShareReportBlockActions(ctx, w, c)
 	}
+	if strings.HasPrefix(a.BlockID, constants.BlockIDShareMode) {
+		return h.handleShareReportBlockActions(ctx, w, c)
+	}
+	if strings.HasPrefix(a.BlockID, constants.BlockIDFilterKind) {
+		return h.handleShareReportBlockActions(ctx, w, c)
+	}
 	switch a.BlockID {
-	case constants.BlockIDReuseFilter, constants.BlockIDSaveFilter, constants.BlockIDAddSecondFilter, constants.BlockIDRemoveSecondFilter, constants.BlockIDSearchReportButton, constants.BlockIDSearchWorkspaceButton, constants.BlockIDWorkspacePBI:
+	case constants.BlockIDReuseFilter, constants.BlockIDSaveFilter, constants.BlockIDAddSecondFilter, constants.BlockIDAddAnotherFilter, constants.BlockIDRemoveSecondFilter, constants.BlockIDSearchReportButton, constants.BlockIDSearchWorkspaceButton, constants.BlockIDWorkspacePBI:
 		return h.handleShareReportBlockActions(ctx, w, c)
 	case constants.BlockIDEditFilter:
 		return h.showManageFilterControls(ctx, w, c, a.ActionID)
@@ -201,6 +210,17 @@ func (h *interactionCommandHandler) handleViewSubmission(ctx context.Context, w
 
 	c.View = *modals.HideBotIsNotInChannelWarning(&c.View)
 
+	if warning := s.ValidateVisual(); warning != "" {
+		err := slackClient.SendValidationError(w, modals.FindBlock(c.View.Blocks.BlockSet, constants.BlockIDPages), warning)
+		if err != nil {
+			l.Error("couldn't send validation error", zap.Error(err))
+
+			return err
+		}
+
+		return nil
+	}
+
 	if strings.HasPrefix(c.View.CallbackID, constants.CallbackIDShareReport) {
 		return h.handleShareReportViewSubmission(ctx, w, c)
 	} else if strings.HasPrefix(c.View.CallbackID, constants.CallbackIDSaveAlert) {
@@ -318,8 +338,14 @@ func (h *interactionCommandHandler) handleShareReportBlockActions(ctx context.Co
 		return h.showAddFilterControls(ctx, w, c)
 	} else if a.BlockID == constants.BlockIDRemoveSecondFilter && a.ActionID == constants.ActionIDRemoveSecondFilter {
 		return h.hideAddFilterControls(ctx, w, c)
+	} else if strings.HasPrefix(a.BlockID, constants.BlockIDFilterKind) && a.ActionID == constants.ActionIDFilterKind {
+		return h.showFilterKindControls(ctx, w, c, domain.TypedFilterKind(a.SelectedOption.Value))
+	} else if a.BlockID == constants.BlockIDAddAnotherFilter && a.ActionID == constants.ActionIDAddAnotherFilter {
+		return h.addComposedFilter(ctx, w, c)
 	} else if strings.HasPrefix(a.BlockID, constants.BlockIDReport) && a.ActionID == constants.ActionIDReport {
 		return h.showOrUpdateChoosePagesControls(ctx, c)
+	} else if strings.HasPrefix(a.BlockID, constants.BlockIDShareMode) && a.ActionID == constants.ActionIDShareMode {
+		return h.showOrHideChooseVisualControls(ctx, c)
 	} else if a.BlockID == constants.BlockIDWorkspacePBI && a.ActionID == constants.ActionIDWorkspacePBI {
 		return h.UpdateChooseReportControls(ctx, c)
 	} else if a.BlockID == constants.BlockIDSearchReportButton && a.ActionID == constants.ActionIDSearchReport {
@@ -499,6 +525,33 @@ func (h *interactionCommandHandler) handleScheduleReportViewSubmission(ctx conte
 		TZ:          u.TZ,
 		IsActive:    true,
 	}
+	if i.ReportSelection.SingleVisual {
+		t.VisualName = i.ReportSelection.VisualName
+	}
+
+	t.DataFormat = i.ReportSelection.DataFormat
+	t.BookmarkName = i.ReportSelection.BookmarkName
+	t.Layout = i.ReportSelection.Layout
+	t.OutputFormat = i.ReportSelection.OutputFormat
+	if i.ReportSelection.SavedFilterID != "" {
+		id, err := strconv.ParseInt(i.ReportSelection.SavedFilterID, 10, 64)
+		if err != nil {
+			l.Error("couldn't parse filter id", zap.Error(err))
+
+			return err
+		}
+
+		f, err := h.filterUsecase.Get(ctx, id)
+		if err != nil {
+			l.Error("couldn't get filter", zap.Error(err), zap.Int64("filterID", id))
+
+			return err
+		}
+
+		// NOTE: Filters are copied to a task, so date tokens of them are kept unresolved till it's posted.
+		t.Filters = utils.SavedTypedFilters(f)
+	}
+
 	err = h.reportUsecase.AddPostingTask(context.Background(), &t)
 	if err == domain.ErrConflict {
 		pagesBlockModifiedID := modals.FindBlock(c.View.Blocks.BlockSet, constants.BlockIDPages)
@@ -613,6 +666,18 @@ func (h *interactionCommandHandler) handleShareReportViewSubmission(ctx context.
 		})
 
 	case constants.CallbackIDShareReportEditFilter:
+		_, blockID, warning := modals.NewComposedFilterInput(&c.View)
+		if warning != "" {
+			err := slackClient.SendValidationError(w, blockID, warning)
+			if err != nil {
+				l.Error("couldn't send validation error", zap.Error(err))
+
+				return err
+			}
+
+			return nil
+		}
+
 		err := slackClient.ClearView(w)
 		if err != nil {
 			l.Error("couldn't clear report view", zap.Error(err))
@@ -637,6 +702,18 @@ func (h *interactionCommandHandler) handleShareReportViewSubmission(ctx context.
 		})
 
 	case constants.CallbackIDShareReportSaveFilter:
+		_, blockID, warning := modals.NewComposedFilterInput(&c.View)
+		if warning != "" {
+			err := slackClient.SendValidationError(w, blockID, warning)
+			if err != nil {
+				l.Error("couldn't send validation error", zap.Error(err))
+
+				return err
+			}
+
+			return nil
+		}
+
 		s, err := modals.NewShareReportInput(&c.View)
 		if err != nil {
 			l.Error("invalid input", zap.Error(err))
@@ -649,8 +726,13 @@ func (h *interactionCommandHandler) handleShareReportViewSubmission(ctx context.
 			UserID:      c.User.ID,
 			ReportID:    s.ReportSelection.ReportID,
 			Name:        s.SaveFilter.Name,
-			Kind:        domain.FilterKindIn,
-			Definition: &utils.FilterOptions{
+			Kind:        domain.FilterKindTyped,
+			Definition:  s.Filters,
+		}
+		// NOTE: A single advanced filter is saved as before, so it can still be updated w/ the "manage filters" dialog.
+		if len(s.Filters) == 1 && s.Filters[0].Kind == domain.TypedFilterAdvanced {
+			f.Kind = domain.FilterKindIn
+			f.Definition = &utils.FilterOptions{
 				Table:                   s.SaveFilter.EditInFilterInput.Table,
 				Column:                  s.SaveFilter.EditInFilterInput.Column,
 				Value:                   s.SaveFilter.EditInFilterInput.Value,
@@ -658,8 +740,9 @@ func (h *interactionCommandHandler) handleShareReportViewSubmission(ctx context.
 				ConditionOperator:       s.SaveFilter.EditInFilterInput.ConditionOperator,
 				SecondValue:             s.SaveFilter.EditInFilterInput.SecondValue,
 				SecondConditionOperator: s.SaveFilter.EditInFilterInput.SecondConditionOperator,
-			},
+			}
 		}
+
 		err = h.filterUsecase.Store(ctx, &f)
 		if err != nil {
 			l.Error("couldn't store filter", zap.Error(err))
@@ -720,6 +803,27 @@ func (h *interactionCommandHandler) handleShareReportViewSubmission(ctx context.
 				SecondConditionOperator: c.View.State.Values[constants.BlockIDSecondConditionOperator][constants.ActionIDSecondConditionOperator].SelectedOption.Value,
 			},
 		}
+
+		// NOTE: Only a name of a filter combining typed filters is updated, so its definition is kept.
+		if blockID := modals.FindBlock(c.View.Blocks.BlockSet, constants.BlockIDFilterToUpdate); blockID != "" {
+			id, err := strconv.ParseInt(strings.TrimPrefix(blockID, constants.BlockIDFilterToUpdate), 10, 64)
+			if err != nil {
+				l.Error("couldn't parse filter id", zap.Error(err))
+
+				return err
+			}
+
+			current, err := h.filterUsecase.Get(ctx, id)
+			if err != nil {
+				l.Error("couldn't get filter", zap.Error(err), zap.Int64("filterID", id))
+
+				return err
+			}
+
+			f.Kind = current.Kind
+			f.Definition = current.Definition
+		}
+
 		if c.View.CallbackID == constants.CallbackIDCreateFilter {
 			err := h.filterUsecase.Store(ctx, &f)
 			if err != nil {
@@ -1101,6 +1205,20 @@ func (h *interactionCommandHandler) shareReport(ctx context.Context, c *slack.In
 		return
 	}
 
+	// NOTE: Date tokens of filters are resolved in the time zone of a user, as they are for a scheduled report.
+	filters := o.Filters
+	if len(filters) != 0 {
+		now := time.Now().UTC()
+		u, err := slack.New(workspace.BotAccessToken).GetUserInfo(c.User.ID)
+		if err != nil {
+			l.Warn("couldn't get user", zap.Error(err))
+		} else if location, err := time.LoadLocation(u.TZ); err == nil {
+			now = now.In(location)
+		}
+
+		filters = domain.ResolveFilterTokens(filters, now)
+	}
+
 	pms := []*messagequeue.PageMessage(nil)
 	for _, p := range o.Pages {
 		pm := messagequeue.PageMessage{
@@ -1110,36 +1228,48 @@ func (h *interactionCommandHandler) shareReport(ctx context.Context, c *slack.In
 		pms = append(pms, &pm)
 	}
 
-	for _, page := range pms {
+	// NOTE: A document holds all pages, so they're posted by a single message; an image of each page is posted by a message of its own.
+	batches := [][]*messagequeue.PageMessage{pms}
+	if !messagequeue.OutputFormat(o.OutputFormat).IsDocument() {
+		batches = nil
+		for _, pm := range pms {
+			batches = append(batches, []*messagequeue.PageMessage{pm})
+		}
+	}
+
+	for _, pages := range batches {
 		m := messagequeue.PostReportMessage{
 			RenderReportMessage: &messagequeue.RenderReportMessage{
-				ClientID:    clientID,
-				ReportID:    o.ReportID,
-				ReportName:  o.ReportName,
-				Pages:       []*messagequeue.PageMessage{page},
-				UserID:      c.User.ID,
-				ChannelID:   o.ChannelID,
-				WorkspaceID: workspace.ID,
-				UniqueID:    uuid.New().String(),
-				Token:       messagequeue.Tokens{},
+				ClientID:     clientID,
+				ReportID:     o.ReportID,
+				ReportName:   o.ReportName,
+				Pages:        pages,
+				UserID:       c.User.ID,
+				ChannelID:    o.ChannelID,
+				WorkspaceID:  workspace.ID,
+				UniqueID:     uuid.New().String(),
+				VisualName:   o.VisualName,
+				DataFormat:   messagequeue.DataFormat(o.DataFormat),
+				BookmarkName: o.BookmarkName,
+				Layout:       messagequeue.Layout(o.Layout),
+				OutputFormat: messagequeue.OutputFormat(o.OutputFormat),
 			},
 		}
-		if o.Filter != nil {
-			m.Filter = &messagequeue.FilterMessage{
-				Table:                   o.Filter.Table,
-				Column:                  o.Filter.Column,
-				Value:                   o.Filter.Value,
-				LogicalOperator:         o.Filter.LogicalOperator,
-				ConditionOperator:       o.Filter.ConditionOperator,
-				SecondValue:             o.Filter.SecondValue,
-				SecondConditionOperator: o.Filter.SecondConditionOperator,
-			}
+		m.Filters = newTypedFilterMessages(filters)
+
+		// NOTE: Slack tokens are looked up by report engine, so sealed ones are empty; they're sealed anyway not to produce plaintext ones.
+		err = m.SealTokens(h.keyRing, &messagequeue.Tokens{})
+		if err != nil {
+			l.Error("couldn't seal message tokens", zap.Error(err))
+
+			continue
 		}
 
 		e := messagequeue.Envelope{
-			Kind:    messagequeue.MessagePostReport,
-			Body:    m,
-			TraceID: utils.ActivityInfo(ctx)["activityID"],
+			Kind:     messagequeue.MessagePostReport,
+			Body:     m,
+			TraceID:  utils.ActivityInfo(ctx)["activityID"],
+			Priority: messagequeue.PriorityInteractive,
 		}
 		err = h.mq.Push(ctx, &e, messagequeue.Wait)
 		if err != nil {
@@ -1157,6 +1287,10 @@ func (h *interactionCommandHandler) searchAndShowReports(ctx context.Context, c
 		l.Error("couldn't get grouped reports", zap.Error(err))
 	}
 
+	if isReportsOnlyView(&c.View) {
+		groupedReports = implementations.RemoveNonReports(groupedReports)
+	}
+
 	modal := modals.FindReportsByInput(&c.View, groupedReports)
 
 	workspace, err := h.workspaceUsecase.Get(ctx, c.User.TeamID)
@@ -1188,7 +1322,7 @@ func (h *interactionCommandHandler) searchAndShowReports(ctx context.Context, c
 		return err
 	}
 
-	modal, err = modals.ShowOrUpdateChoosePagesControls(&c.View, ps, i.ReportID)
+	modal, err = modals.ShowOrUpdateChoosePagesControls(&c.View, ps, nil, i.ReportID)
 	if err != nil {
 		return err
 	}
@@ -1325,17 +1459,21 @@ func (h *interactionCommandHandler) showManageFilterControls(ctx context.Context
 			return err
 		}
 
-		filter := map[string]string{}
-		filter["Table"] = f.Definition.(*utils.FilterOptions).Table
-		filter["Column"] = f.Definition.(*utils.FilterOptions).Column
-		filter["Value"] = f.Definition.(*utils.FilterOptions).Value
-		filter["Name"] = f.Name
-		filter["ConditionOperator"] = f.Definition.(*utils.FilterOptions).ConditionOperator
-		filter["SecondValue"] = f.Definition.(*utils.FilterOptions).SecondValue
-		filter["SecondConditionOperator"] = f.Definition.(*utils.FilterOptions).SecondConditionOperator
-		filter["LogicalOperator"] = f.Definition.(*utils.FilterOptions).LogicalOperator
+		if f.Kind == domain.FilterKindTyped {
+			modal = modals.ShowManageTypedFilterUpdateControls(&c.View, f)
+		} else {
+			filter := map[string]string{}
+			filter["Table"] = f.Definition.(*utils.FilterOptions).Table
+			filter["Column"] = f.Definition.(*utils.FilterOptions).Column
+			filter["Value"] = f.Definition.(*utils.FilterOptions).Value
+			filter["Name"] = f.Name
+			filter["ConditionOperator"] = f.Definition.(*utils.FilterOptions).ConditionOperator
+			filter["SecondValue"] = f.Definition.(*utils.FilterOptions).SecondValue
+			filter["SecondConditionOperator"] = f.Definition.(*utils.FilterOptions).SecondConditionOperator
+			filter["LogicalOperator"] = f.Definition.(*utils.FilterOptions).LogicalOperator
 
-		modal := modals.ShowManageFilterCurrentUpdateControls(&c.View, filter)
+			modal = modals.ShowManageFilterCurrentUpdateControls(&c.View, filter)
+		}
 
 		api := slack.New(workspace.BotAccessToken)
 		_, err = api.UpdateView(*modal, c.View.ExternalID, c.View.Hash, c.View.ID)
@@ -1594,6 +1732,78 @@ func (h *interactionCommandHandler) hideAddFilterControls(ctx context.Context, w
 	return nil
 }
 
+func (h *interactionCommandHandler) showFilterKindControls(ctx context.Context, w http.ResponseWriter, c *slack.InteractionCallback, kind domain.TypedFilterKind) error {
+	l := utils.WithContext(ctx, h.logger)
+
+	err := slackClient.Ack(w)
+	if err != nil {
+		l.Error("couldn't acknowledge", zap.Error(err))
+
+		return err
+	}
+
+	workspace, err := h.workspaceUsecase.Get(ctx, c.User.TeamID)
+	if err != nil {
+		l.Error("couldn't get workspace", zap.Error(err))
+
+		return err
+	}
+
+	if workspace.BotAccessToken == "" {
+		return domain.ErrEmptyBotToken
+	}
+
+	modal := modals.ShowFilterKindControls(&c.View, kind)
+	api := slack.New(workspace.BotAccessToken)
+	_, err = api.UpdateView(*modal, c.View.ExternalID, c.View.Hash, c.View.ID)
+	if err != nil {
+		l.Error("couldn't update filter view", zap.Error(err))
+
+		return domain.ErrUpdatingView(err)
+	}
+
+	return nil
+}
+
+func (h *interactionCommandHandler) addComposedFilter(ctx context.Context, w http.ResponseWriter, c *slack.InteractionCallback) error {
+	l := utils.WithContext(ctx, h.logger)
+
+	err := slackClient.Ack(w)
+	if err != nil {
+		l.Error("couldn't acknowledge", zap.Error(err))
+
+		return err
+	}
+
+	workspace, err := h.workspaceUsecase.Get(ctx, c.User.TeamID)
+	if err != nil {
+		l.Error("couldn't get workspace", zap.Error(err))
+
+		return err
+	}
+
+	if workspace.BotAccessToken == "" {
+		return domain.ErrEmptyBotToken
+	}
+
+	modal, err := modals.AddComposedFilter(&c.View)
+	if err != nil {
+		l.Error("couldn't add filter", zap.Error(err))
+
+		return err
+	}
+
+	api := slack.New(workspace.BotAccessToken)
+	_, err = api.UpdateView(*modal, c.View.ExternalID, c.View.Hash, c.View.ID)
+	if err != nil {
+		l.Error("couldn't update filter view", zap.Error(err))
+
+		return domain.ErrUpdatingView(err)
+	}
+
+	return nil
+}
+
 func (h *interactionCommandHandler) hideSaveFilterControls(ctx context.Context, w http.ResponseWriter, c *slack.InteractionCallback) error {
 	l := utils.WithContext(ctx, h.logger)
 
@@ -1761,6 +1971,10 @@ func (h *interactionCommandHandler) UpdateChooseReportControls(ctx context.Conte
 	if err != nil {
 		l.Error("couldn't get slack user ID", zap.Error(err))
 	}
+
+	if isReportsOnlyView(&c.View) {
+		rs = implementations.RemoveNonReports(rs)
+	}
 	workspaceReports := modals.FindReportsInChosenPBIWorkspace(&c.View, rs, chosenPBIWorkspace)
 
 	modal := modals.UpdateChooseReportControls(&c.View, *workspaceReports, chosenPBIWorkspace)
@@ -1785,6 +1999,57 @@ func (h *interactionCommandHandler) UpdateChooseReportControls(ctx context.Conte
 	return nil
 }
 
+func (h *interactionCommandHandler) showOrHideChooseVisualControls(ctx context.Context, c *slack.InteractionCallback) error {
+	l := utils.WithContext(ctx, h.logger)
+
+	i, err := modals.NewReportSelectionInput(&c.View)
+	if err != nil {
+		l.Error("invalid input", zap.Error(err))
+
+		return err
+	}
+
+	workspace, err := h.workspaceUsecase.Get(ctx, c.User.TeamID)
+	if err != nil {
+		return domain.ErrUpdatingView(err)
+	}
+
+	if workspace.BotAccessToken == "" {
+		return domain.ErrEmptyBotToken
+	}
+
+	// NOTE: Visuals are listed for a page chosen at the moment; the choice is validated again on submission.
+	pageID := ""
+	if len(i.Pages) == 1 {
+		pageID = i.Pages[0].ID
+	}
+
+	modal := modals.HideChooseVisualControls(&c.View)
+	if i.SingleVisual {
+		modal = modals.ShowChooseVisualControls(&c.View, pageID, nil, pageID != "")
+	}
+
+	api := slack.New(workspace.BotAccessToken)
+	r, err := api.UpdateView(*modal, c.View.ExternalID, c.View.Hash, c.View.ID)
+	if err != nil {
+		l.Error("couldn't update visuals view", zap.Error(err))
+
+		return domain.ErrUpdatingView(err)
+	}
+
+	if !i.SingleVisual || pageID == "" {
+		return nil
+	}
+
+	v := r.View
+	u := domain.SlackUserIDFromInteractionCallback(c)
+	utils.SafeRoutine(func() {
+		h.reportUsecase.UpdateChooseVisualControls(context.Background(), &v, u, i.ReportID, pageID)
+	})
+
+	return nil
+}
+
 func (h *interactionCommandHandler) showOrUpdateChoosePagesControls(ctx context.Context, c *slack.InteractionCallback) error {
 	l := utils.WithContext(ctx, h.logger)
 
@@ -1807,7 +2072,16 @@ func (h *interactionCommandHandler) showOrUpdateChoosePagesControls(ctx context.
 		return err
 	}
 
-	modal, err := modals.ShowOrUpdateChoosePagesControls(&c.View, ps, i.ReportID)
+	fs := []*domain.Filter(nil)
+	if strings.HasPrefix(c.View.CallbackID, constants.CallbackIDScheduleReport) && modals.IsReportOfPages(i.ReportID) {
+		// NOTE: A failure is shown as a report w/o saved filters, so it's still scheduled as is.
+		fs, err = h.filterUsecase.ListByReportID(ctx, i.ReportID)
+		if err != nil {
+			l.Error("couldn't list filters", zap.Error(err), zap.String("reportID", i.ReportID))
+		}
+	}
+
+	modal, err := modals.ShowOrUpdateChoosePagesControls(&c.View, ps, fs, i.ReportID)
 	if err != nil {
 		l.Error("invalid input", zap.Error(err))
 
@@ -1824,12 +2098,73 @@ func (h *interactionCommandHandler) showOrUpdateChoosePagesControls(ctx context.
 	}
 
 	api := slack.New(workspace.BotAccessToken)
-	_, err = api.UpdateView(*modal, c.View.ExternalID, c.View.Hash, c.View.ID)
+	r, err := api.UpdateView(*modal, c.View.ExternalID, c.View.Hash, c.View.ID)
 	if err != nil {
 		l.Error("couldn't update pages view", zap.Error(err))
 
 		return domain.ErrUpdatingView(err)
 	}
 
+	// NOTE: Alerts are checked against a report as is, so no bookmark is chosen there; a dashboard, a tile or a paginated report has no bookmarks.
+	if strings.HasPrefix(c.View.CallbackID, constants.CallbackIDSaveAlert) || !modals.IsReportOfPages(i.ReportID) {
+		return nil
+	}
+
+	v := r.View
+	utils.SafeRoutine(func() {
+		h.reportUsecase.UpdateChooseBookmarkControls(context.Background(), &v, u, i.ReportID)
+	})
+
 	return nil
 }
+
+// isReportsOnlyView tells if dashboards, tiles & paginated reports aren't listed in a view, since alerts & filters need pages & visuals of a report.
+func isReportsOnlyView(v *slack.View) bool {
+	return strings.HasPrefix(v.CallbackID, constants.CallbackIDSaveAlert) || v.Title.Text == constants.TitleManageFilters
+}
+
+// newTypedFilterMessages maps filters of utils.ShareOptions to filters of a messagequeue.RenderReportMessage.
+func newTypedFilterMessages(fs []*domain.TypedFilter) []*messagequeue.TypedFilterMessage {
+	ms := []*messagequeue.TypedFilterMessage(nil)
+	for _, f := range fs {
+		m := messagequeue.TypedFilterMessage{
+			Kind:            messagequeue.FilterKind(f.Kind),
+			Target:          newFilterTargetMessage(f.Target),
+			Operator:        f.Operator,
+			Values:          f.Values,
+			LogicalOperator: f.LogicalOperator,
+			TimeUnitsCount:  f.TimeUnitsCount,
+			TimeUnit:        f.TimeUnit,
+			IncludeToday:    f.IncludeToday,
+			ItemCount:       f.ItemCount,
+			OrderBy:         newFilterTargetMessage(f.OrderBy),
+			Tuples:          f.Tuples,
+		}
+		for _, c := range f.Conditions {
+			m.Conditions = append(m.Conditions, &messagequeue.FilterConditionMessage{
+				Operator: c.Operator,
+				Value:    c.Value,
+			})
+		}
+
+		for _, t := range f.Targets {
+			m.Targets = append(m.Targets, newFilterTargetMessage(t))
+		}
+
+		ms = append(ms, &m)
+	}
+
+	return ms
+}
+
+func newFilterTargetMessage(t *domain.FilterTarget) *messagequeue.FilterTargetMessage {
+	if t == nil {
+		return nil
+	}
+
+	return &messagequeue.FilterTargetMessage{
+		Table:   t.Table,
+		Column:  t.Column,
+		Measure: t.Measure,
+	}
+}
@@ -16,6 +16,7 @@ import (
 type testAPIHandler struct {
 	reportUsecase usecases.ReportUsecase
 	mq            messagequeue.MessageQueue
+	keyRing       *messagequeue.KeyRing
 	config        *config.TestAPIConfig
 	logger        *zap.Logger
 }
@@ -25,6 +26,7 @@ func ConfigureTestAPIHandler(
 	r *httprouter.Router,
 	report usecases.ReportUsecase,
 	m messagequeue.MessageQueue,
+	k *messagequeue.KeyRing,
 	c *config.TestAPIConfig,
 	l *zap.Logger,
 ) {
@@ -35,6 +37,7 @@ func ConfigureTestAPIHandler(
 	h := testAPIHandler{
 		reportUsecase: report,
 		mq:            m,
+		keyRing:       k,
 		config:        c,
 		logger:        l,
 	}
@@ -66,6 +69,22 @@ type postReportRequest struct {
 	ChannelID   string         `json:"channelID"`
 	WorkspaceID string         `json:"workspaceID"`
 	SkipPosting bool           `json:"skipPosting"`
+	// OutputFormat is either png (default) or pdf; xlsx is for a paginated report only.
+	OutputFormat messagequeue.OutputFormat `json:"outputFormat,omitempty"`
+	// VisualName is a title of a single visual to render instead of a whole page.
+	VisualName string `json:"visualName,omitempty"`
+	// BypassCache makes pages rendered anew even if they're cached.
+	BypassCache bool `json:"bypassCache,omitempty"`
+	// DataFormat is either csv or zip to attach data of visuals; no data is attached if unset.
+	DataFormat messagequeue.DataFormat `json:"dataFormat,omitempty"`
+	// BookmarkName is a name of a report bookmark applied before each page; no bookmark is applied if unset.
+	BookmarkName string `json:"bookmarkName,omitempty"`
+	// Filters are typed filters applied together along w/ Filter.
+	Filters []*messagequeue.TypedFilterMessage `json:"filters,omitempty"`
+	// ReportParameters are values of parameters of a paginated report.
+	ReportParameters []*messagequeue.ReportParameterMessage `json:"reportParameters,omitempty"`
+	// Layout is either master, mobilePortrait or mobileLandscape; pages are rendered as designed for desktop if unset.
+	Layout messagequeue.Layout `json:"layout,omitempty"`
 }
 
 func (h *testAPIHandler) handleRenderReport(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -132,14 +151,22 @@ func (h *testAPIHandler) shareReport(ctx context.Context, r *postReportRequest)
 
 	m := messagequeue.PostReportMessage{
 		RenderReportMessage: &messagequeue.RenderReportMessage{
-			ClientID:    "slack",
-			ReportID:    r.ReportID,
-			ReportName:  r.ReportName,
-			Pages:       pms,
-			UserID:      r.UserID,
-			ChannelID:   r.ChannelID,
-			WorkspaceID: r.WorkspaceID,
-			UniqueID:    uuid.New().String(),
+			ClientID:         "slack",
+			ReportID:         r.ReportID,
+			ReportName:       r.ReportName,
+			Pages:            pms,
+			UserID:           r.UserID,
+			ChannelID:        r.ChannelID,
+			WorkspaceID:      r.WorkspaceID,
+			UniqueID:         uuid.New().String(),
+			OutputFormat:     r.OutputFormat,
+			VisualName:       r.VisualName,
+			BypassCache:      r.BypassCache,
+			DataFormat:       r.DataFormat,
+			BookmarkName:     r.BookmarkName,
+			Filters:          r.Filters,
+			ReportParameters: r.ReportParameters,
+			Layout:           r.Layout,
 		},
 		SkipPosting: r.SkipPosting,
 	}
@@ -155,12 +182,20 @@ func (h *testAPIHandler) shareReport(ctx context.Context, r *postReportRequest)
 		}
 	}
 
+	err := m.SealTokens(h.keyRing, &messagequeue.Tokens{})
+	if err != nil {
+		l.Error("couldn't seal message tokens", zap.Error(err))
+
+		return err
+	}
+
 	e := messagequeue.Envelope{
-		Kind:    messagequeue.MessagePostReport,
-		Body:    m,
-		TraceID: utils.RequestID(ctx),
+		Kind:     messagequeue.MessagePostReport,
+		Body:     m,
+		TraceID:  utils.RequestID(ctx),
+		Priority: messagequeue.PriorityInteractive,
 	}
-	err := h.mq.Push(ctx, &e, messagequeue.Wait)
+	err = h.mq.Push(ctx, &e, messagequeue.Wait)
 	if err != nil {
 		l.Error("couldn't enqueue message", zap.Error(err))
 	}
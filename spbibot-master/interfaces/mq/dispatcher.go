@@ -2,41 +2,61 @@ package mq
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
 )
 
+// NOTE: Envelope received by another consumer is never forgotten by this one, so postponed receives expire once it hasn't been seen for a while.
+const postponedTTL = time.Hour
+
 type registryEntry struct {
 	w            Worker
 	subscription chan *messagequeue.Envelope
 }
 
+type postponedEnvelope struct {
+	count  int
+	seenAt time.Time
+}
+
 type messageDispatcher struct {
-	registry      map[messagequeue.MessageKind]*registryEntry
-	waitWorkers   sync.WaitGroup
-	workCompleted chan struct{}
-	mq            messagequeue.MessageQueue
-	config        *config.MessageHandlerConfig
-	logger        *zap.Logger
+	registry       map[messagequeue.MessageKind]*registryEntry
+	inFlight       chan struct{}
+	waitWorkers    sync.WaitGroup
+	stopReceiving  context.CancelFunc
+	cancelHandling context.CancelFunc
+	mq             messagequeue.MessageQueue
+	deadLetters    messagequeue.MessageQueue
+	config         *config.MessageHandlerConfig
+	logger         *zap.Logger
+	// postponed counts receives of each Envelope which were postponed as its kind was busy or it asked for a delay, so they don't count towards config.MessageHandlerConfig.MaxReceiveCount.
+	postponed   map[string]*postponedEnvelope
+	postponedMu sync.Mutex
 }
 
 // NewMessageDispatcher creates a messagequeue.MessageQueue -backed Manager.
+// Envelopes which couldn't be handled for config.MessageHandlerConfig.MaxReceiveCount times are moved to dl.
 func NewMessageDispatcher(
 	m messagequeue.MessageQueue,
+	dl messagequeue.MessageQueue,
 	c *config.MessageHandlerConfig,
 	l *zap.Logger,
 ) Manager {
 	return &messageDispatcher{
-		registry:      map[messagequeue.MessageKind]*registryEntry{},
-		waitWorkers:   sync.WaitGroup{},
-		workCompleted: make(chan struct{}),
-		mq:            m,
-		config:        c,
-		logger:        l,
+		registry:    map[messagequeue.MessageKind]*registryEntry{},
+		inFlight:    make(chan struct{}, c.MaxInFlight),
+		waitWorkers: sync.WaitGroup{},
+		mq:          m,
+		deadLetters: dl,
+		config:      c,
+		logger:      l,
+		postponed:   map[string]*postponedEnvelope{},
 	}
 }
 
@@ -47,9 +67,10 @@ func (d *messageDispatcher) RegisterWorker(w Worker) error {
 			return fmt.Errorf("a worker is already registered for %v", k)
 		}
 
+		// NOTE: A kind keeps a backlog as large as its concurrency level, so its workers don't wait for the next receive.
 		e := registryEntry{
 			w:            w,
-			subscription: make(chan *messagequeue.Envelope),
+			subscription: make(chan *messagequeue.Envelope, d.getConcurrencyLevel(k)),
 		}
 		d.registry[k] = &e
 	}
@@ -57,112 +78,383 @@ func (d *messageDispatcher) RegisterWorker(w Worker) error {
 	return nil
 }
 
+// NOTE: Cancelling ctx only stops receiving; messages already passed to workers are handled to the end (see Stop).
 func (d *messageDispatcher) Start(ctx context.Context) {
+	receiveCtx, stopReceiving := context.WithCancel(ctx)
+	handleCtx, cancelHandling := context.WithCancel(detachedContext{ctx})
+	d.stopReceiving = stopReceiving
+	d.cancelHandling = cancelHandling
+
+	d.waitWorkers.Add(1)
 	utils.SafeRoutine(func() {
-		d.waitWorkers.Wait()
-		d.workCompleted <- struct{}{}
+		defer d.waitWorkers.Done()
+
+		d.receive(receiveCtx, handleCtx)
 	})
 
+	for k, w := range d.registry {
+		for i := 0; i < int(d.getConcurrencyLevel(k)); i++ {
+			d.spawnWorker(receiveCtx, handleCtx, w.w, w.subscription, k, i)
+		}
+	}
+}
+
+// Stop stops receiving & waits for in-flight messages to be handled; handling is cancelled once ctx is done.
+func (d *messageDispatcher) Stop(ctx context.Context) error {
+	if d.stopReceiving == nil {
+		return nil
+	}
+
+	d.stopReceiving()
+
+	workCompleted := make(chan struct{})
 	utils.SafeRoutine(func() {
-		logger := utils.WithContext(ctx, d.logger)
-		for {
-			select {
-			case <-ctx.Done():
-				return
+		d.waitWorkers.Wait()
+		close(workCompleted)
+	})
 
-			default:
-				break
-			}
+	select {
+	case <-workCompleted:
+		return nil
 
-			e, err := d.mq.Peek(ctx, messagequeue.Wait)
-			if err != nil {
-				if err != messagequeue.ErrNoMessages {
-					logger.Debug("couldn't receive message", zap.Error(err))
-				}
+	case <-ctx.Done():
+		d.cancelHandling()
 
-				continue
-			}
+		return ctx.Err()
+	}
+}
 
-			ctx = utils.WithActivityInfo(ctx, map[string]string{
-				"activityID":  e.TraceID,
-				"messageID":   e.ID,
-				"messageKind": string(e.Kind),
-			})
-			l := utils.WithContext(ctx, logger)
-
-			w, ok := d.registry[e.Kind]
-			if !ok {
-				l.Error("no suitable worker")
-				err = d.mq.Delete(ctx, e.Handle)
-				if err != nil {
-					l.Error("couldn't delete message", zap.Error(err))
-				}
-
-				continue
-			}
+func (d *messageDispatcher) getConcurrencyLevel(k messagequeue.MessageKind) uint {
+	l, ok := d.config.KindConcurrencyLevels[string(k)]
+	if ok {
+		return l
+	}
+
+	return d.config.ConcurrencyLevel
+}
+
+// NOTE: A slot in d.inFlight is taken before each Peek & freed once Envelope is handled, so we don't receive messages nobody can handle.
+func (d *messageDispatcher) receive(ctx context.Context, handleCtx context.Context) {
+	logger := utils.WithContext(ctx, d.logger)
 
-			w.subscription <- e
+	defer func() {
+		// NOTE: Envelopes left in backlogs aren't handled once receiving stops, so they're made visible again for another consumer.
+		for _, w := range d.registry {
+			d.releaseBacklog(handleCtx, w.subscription, logger)
 		}
-	})
 
-	for k, w := range d.registry {
-		for i := 0; i < int(d.config.ConcurrencyLevel); i++ {
-			d.spawnWorker(ctx, w.w, w.subscription, k, i)
+		err := d.mq.Release(handleCtx)
+		if err != nil {
+			logger.Error("couldn't release buffered messages", zap.Error(err))
 		}
-	}
-}
+	}()
 
-func (d *messageDispatcher) Stop(ctx context.Context) error {
 	for {
 		select {
-		case <-d.workCompleted:
-			return nil
+		case d.inFlight <- struct{}{}:
+			break
 
 		case <-ctx.Done():
-			return ctx.Err()
+			return
+		}
+
+		e, err := d.mq.Peek(ctx, messagequeue.Wait)
+		invalid := (*messagequeue.InvalidEnvelopeError)(nil)
+		if errors.As(err, &invalid) {
+			d.handleInvalid(handleCtx, invalid)
+			<-d.inFlight
+
+			continue
+		}
+
+		if err != nil {
+			<-d.inFlight
+			if ctx.Err() != nil {
+				return
+			}
+
+			if err != messagequeue.ErrNoMessages {
+				logger.Debug("couldn't receive message", zap.Error(err))
+			}
+
+			continue
+		}
+
+		e.ReceiveCount -= d.postponedCount(e.ID)
+
+		messageCtx := utils.WithActivityInfo(handleCtx, map[string]string{
+			"activityID":  e.TraceID,
+			"messageID":   e.ID,
+			"messageKind": string(e.Kind),
+		})
+		l := utils.WithContext(messageCtx, logger)
+
+		w, ok := d.registry[e.Kind]
+		if !ok {
+			l.Error("no suitable worker")
+			d.moveToDeadLetters(messageCtx, e, fmt.Errorf("no suitable worker for %v", e.Kind), l)
+			<-d.inFlight
+
+			continue
+		}
+
+		// NOTE: A kind w/ busy workers & a full backlog would block messages of other kinds, so its message is postponed instead.
+		select {
+		case w.subscription <- e:
+			break
+
+		default:
+			l.Debug("postponed message of busy kind")
+			d.postpone(messageCtx, e, d.config.BusyDelay, l)
+			<-d.inFlight
 		}
 	}
 }
 
-func (d *messageDispatcher) spawnWorker(ctx context.Context, w Worker, subscription <-chan *messagequeue.Envelope, k messagequeue.MessageKind, id int) {
+func (d *messageDispatcher) spawnWorker(
+	ctx context.Context,
+	handleCtx context.Context,
+	w Worker,
+	subscription <-chan *messagequeue.Envelope,
+	k messagequeue.MessageKind,
+	id int,
+) {
 	d.waitWorkers.Add(1)
 	utils.SafeRoutine(func() {
 		defer d.waitWorkers.Done()
 
-		ctx = utils.WithActivityInfo(ctx, map[string]string{
+		handleCtx = utils.WithActivityInfo(handleCtx, map[string]string{
 			"messageKind": string(k),
 			"workerID":    strconv.FormatInt(int64(id), 10),
 		})
-		logger := utils.WithContext(ctx, d.logger)
+		logger := utils.WithContext(handleCtx, d.logger)
 		logger.Debug("started")
 
 		for {
 			select {
+			case e := <-subscription:
+				d.handle(handleCtx, w, e, logger)
+				<-d.inFlight
+
 			case <-ctx.Done():
-				return
+				logger.Debug("stopped")
 
-			default:
-				break
+				return
 			}
+		}
+	})
+}
+
+func (d *messageDispatcher) handle(ctx context.Context, w Worker, e *messagequeue.Envelope, logger *zap.Logger) {
+	ctx = utils.WithActivityInfo(ctx, map[string]string{
+		"activityID": e.TraceID,
+		"messageID":  e.ID,
+	})
+	l := utils.WithContext(ctx, logger)
+	l.Debug("received message", zap.Int("receiveCount", e.ReceiveCount))
+
+	// NOTE: Envelope is deleted only after it's been handled, so it's received again if we crash meanwhile.
+	stopExtending := d.extendVisibility(ctx, e, l)
+	err := w.Handle(ctx, e)
+	stopExtending()
+	if err != nil {
+		l.Error("couldn't handle message", zap.Error(err))
+		d.handleFailure(ctx, e, err, l)
+
+		return
+	}
+
+	err = d.mq.Delete(ctx, e.Handle)
+	if err != nil {
+		l.Error("couldn't delete message", zap.Error(err))
+	}
+
+	d.forgetPostponed(e.ID)
+}
+
+// NOTE: Envelope which hasn't been passed to a worker is made visible again, so another consumer doesn't wait for visibility timeout.
+func (d *messageDispatcher) release(ctx context.Context, e *messagequeue.Envelope, l *zap.Logger) {
+	err := d.mq.ChangeVisibility(ctx, e.Handle, 0)
+	if err != nil {
+		l.Error("couldn't release message", zap.Error(err))
+	}
+}
+
+func (d *messageDispatcher) releaseBacklog(ctx context.Context, subscription chan *messagequeue.Envelope, l *zap.Logger) {
+	for {
+		select {
+		case e := <-subscription:
+			d.release(ctx, e, l)
+			<-d.inFlight
 
-			e := <-subscription
+		default:
+			return
+		}
+	}
+}
 
-			ctx = utils.WithActivityInfo(ctx, map[string]string{
-				"activityID": e.TraceID,
-				"messageID":  e.ID,
-			})
-			l := utils.WithContext(ctx, logger)
-			l.Debug("received message")
+// postpone makes Envelope visible again after delay, rather than at once, not to receive it over & over while it can't be handled; such a receive isn't counted.
+func (d *messageDispatcher) postpone(ctx context.Context, e *messagequeue.Envelope, delay time.Duration, l *zap.Logger) {
+	err := d.mq.ChangeVisibility(ctx, e.Handle, delay)
+	if err != nil {
+		l.Error("couldn't postpone message", zap.Error(err))
 
-			err := d.mq.Delete(ctx, e.Handle)
-			if err != nil {
-				l.Error("couldn't delete message", zap.Error(err))
+		return
+	}
+
+	d.postponedMu.Lock()
+	defer d.postponedMu.Unlock()
+
+	now := time.Now()
+	for id, p := range d.postponed {
+		if now.Sub(p.seenAt) > postponedTTL {
+			delete(d.postponed, id)
+		}
+	}
+
+	p, ok := d.postponed[e.ID]
+	if !ok {
+		p = &postponedEnvelope{}
+		d.postponed[e.ID] = p
+	}
+
+	p.count++
+	p.seenAt = now
+}
+
+// postponedCount tells how many receives of Envelope were postponed; they're kept from expiring as it's received again.
+func (d *messageDispatcher) postponedCount(id string) int {
+	d.postponedMu.Lock()
+	defer d.postponedMu.Unlock()
+
+	p, ok := d.postponed[id]
+	if !ok {
+		return 0
+	}
+
+	p.seenAt = time.Now()
+
+	return p.count
+}
+
+// NOTE: Envelope is forgotten once it's deleted, so postponed receives are kept only while it may be received again.
+func (d *messageDispatcher) forgetPostponed(id string) {
+	d.postponedMu.Lock()
+	defer d.postponedMu.Unlock()
+
+	delete(d.postponed, id)
+}
+
+// NOTE: Long-running handlers would otherwise let Envelope reappear in the queue & be handled twice.
+func (d *messageDispatcher) extendVisibility(ctx context.Context, e *messagequeue.Envelope, l *zap.Logger) context.CancelFunc {
+	extendCtx, cancelExtending := context.WithCancel(ctx)
+	utils.SafeRoutine(func() {
+		t := time.NewTicker(d.config.VisibilityExtension / 2)
+		defer t.Stop()
+
+		for {
+			err := d.mq.ChangeVisibility(extendCtx, e.Handle, d.config.VisibilityExtension)
+			if err != nil && extendCtx.Err() == nil {
+				l.Warn("couldn't extend message visibility", zap.Error(err))
 			}
 
-			err = w.Handle(ctx, e)
-			if err != nil {
-				l.Error("couldn't handle message", zap.Error(err))
+			select {
+			case <-t.C:
+				break
+
+			case <-extendCtx.Done():
+				return
 			}
 		}
 	})
+
+	return cancelExtending
+}
+
+// NOTE: Envelope of an unknown version may be handled by a newer consumer, so it isn't dropped at once.
+func (d *messageDispatcher) handleInvalid(ctx context.Context, invalid *messagequeue.InvalidEnvelopeError) {
+	e := invalid.Envelope
+	ctx = utils.WithActivityInfo(ctx, map[string]string{
+		"activityID":  e.TraceID,
+		"messageID":   e.ID,
+		"messageKind": string(e.Kind),
+	})
+	l := utils.WithContext(ctx, d.logger)
+	l.Error("received invalid message", zap.Error(invalid.Err), zap.Int("version", e.Version))
+
+	if errors.Is(invalid.Err, messagequeue.ErrUnsupportedVersion) {
+		d.handleFailure(ctx, e, invalid.Err, l)
+
+		return
+	}
+
+	d.moveToDeadLetters(ctx, e, invalid.Err, l)
+}
+
+func (d *messageDispatcher) handleFailure(ctx context.Context, e *messagequeue.Envelope, handlingErr error, l *zap.Logger) {
+	delayed := (*messagequeue.DelayedError)(nil)
+	if errors.As(handlingErr, &delayed) {
+		l.Debug("postponed message", zap.Duration("delay", delayed.Delay))
+		d.postpone(ctx, e, delayed.Delay, l)
+
+		return
+	}
+
+	nonRetryable := (*messagequeue.NonRetryableError)(nil)
+	if e.ReceiveCount >= d.config.MaxReceiveCount || errors.As(handlingErr, &nonRetryable) {
+		d.moveToDeadLetters(ctx, e, handlingErr, l)
+
+		return
+	}
+
+	err := d.mq.ChangeVisibility(ctx, e.Handle, d.config.RetryDelay)
+	if err != nil {
+		l.Error("couldn't schedule message redelivery", zap.Error(err))
+	}
+}
+
+func (d *messageDispatcher) moveToDeadLetters(ctx context.Context, e *messagequeue.Envelope, handlingErr error, l *zap.Logger) {
+	m := messagequeue.DeadLetterMessage{
+		Envelope:     e,
+		Error:        handlingErr.Error(),
+		ReceiveCount: e.ReceiveCount,
+		FailedAt:     time.Now().UTC(),
+	}
+	dl := messagequeue.Envelope{
+		Kind:    messagequeue.MessageDeadLetter,
+		Body:    m,
+		TraceID: e.TraceID,
+	}
+	err := d.deadLetters.Push(ctx, &dl, messagequeue.Wait)
+	if err != nil {
+		l.Error("couldn't move message to dead letters", zap.Error(err))
+
+		return
+	}
+
+	l.Warn("moved message to dead letters", zap.Error(handlingErr))
+
+	err = d.mq.Delete(ctx, e.Handle)
+	if err != nil {
+		l.Error("couldn't delete message", zap.Error(err))
+	}
+
+	d.forgetPostponed(e.ID)
+}
+
+// detachedContext keeps values of a parent context, but isn't cancelled along w/ it.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
 }
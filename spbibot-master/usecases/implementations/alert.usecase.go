@@ -118,6 +118,7 @@ func (alertUsecase *AlertUsecase) ShowInitialCreateAlertModal(ctx context.Contex
 		return
 	}
 
+	reports = RemoveNonReports(reports)
 	var modalView slack.ModalViewRequest
 	if len(reports) > 0 {
 		modal := modals.NewInitialAlertModal(constants.CreateAlertLabel, constants.CloseLabel, constants.OkLabel, reports, o.ChannelID)
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +15,12 @@ import (
 
 )
 
+// scheduledPostingWindow is an interval between scheduled posting runs.
+const scheduledPostingWindow = 30 * time.Minute
+
+// NOTE: An arbitrary namespace for name-based UUIDs of scheduled messages.
+var scheduledMessageNamespace = uuid.MustParse("5a0f6e3c-8d2b-4f7e-9c41-2e7b8d6a1f30")
+
 // ReportUsecase represent the data-struct for view usecases
 type ReportUsecase struct {
 	powerBiServiceClient   powerbi.ServiceClient
@@ -21,6 +28,7 @@ type ReportUsecase struct {
 	postingTaskRepository  domain.PostReportTaskRepository
 	userRepository         domain.UserRepository
 	mq                     messagequeue.MessageQueue
+	keyRing                *messagequeue.KeyRing
 	dbTimeout              time.Duration
 	logger                 *zap.Logger
 	featureToggles         *config.FeatureTogglesConfig
@@ -37,6 +45,7 @@ func NewReportUsecase(
 	postingTaskRepository domain.PostReportTaskRepository,
 	userRepository domain.UserRepository,
 	m messagequeue.MessageQueue,
+	k *messagequeue.KeyRing,
 	dbTimeout time.Duration,
 	l *zap.Logger,
 	f *config.FeatureTogglesConfig,
@@ -51,6 +60,7 @@ func NewReportUsecase(
 		postingTaskRepository:  postingTaskRepository,
 		userRepository:         userRepository,
 		mq:                     m,
+		keyRing:                k,
 		dbTimeout:              dbTimeout,
 		logger:                 l,
 		featureToggles:         f,
@@ -71,6 +81,72 @@ func (reportUsecase *ReportUsecase) GetPages(userID *domain.SlackUserID, reportI
 	return ps.Value, nil
 }
 
+// UpdateChooseVisualControls lists visuals of a report page & lets a user choose one of them in v.
+func (reportUsecase *ReportUsecase) UpdateChooseVisualControls(ctx context.Context, v *slack.View, userID *domain.SlackUserID, reportID, pageID string) {
+	l := utils.
+		WithContext(ctx, reportUsecase.logger).
+		With(zap.String("userID", userID.ID), zap.String("workspaceID", userID.WorkspaceID), zap.String("reportID", reportID))
+
+	u, err := reportUsecase.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		l.Error("couldn't get user", zap.Error(err))
+
+		return
+	}
+
+	w, err := reportUsecase.workspaceRepository.GetByID(ctx, userID.WorkspaceID)
+	if err != nil {
+		l.Error("couldn't get workspace", zap.Error(err))
+
+		return
+	}
+
+	// NOTE: A failure is shown as a page w/o visuals, so the modal doesn't hang in the loading state.
+	vs, err := alertUtil.GetPageVisuals(u.GetAccessToken(), reportID, pageID)
+	if err != nil {
+		l.Error("couldn't obtain visuals", zap.Error(err), zap.String("pageID", pageID))
+	}
+
+	api := slack.New(w.BotAccessToken)
+	_, err = api.UpdateView(*modals.ShowChooseVisualControls(v, pageID, vs, false), v.ExternalID, "", v.ID)
+	if err != nil {
+		l.Error("couldn't update visuals view", zap.Error(err))
+	}
+}
+
+// UpdateChooseBookmarkControls lists bookmarks of a report & lets a user choose one of them in v.
+func (reportUsecase *ReportUsecase) UpdateChooseBookmarkControls(ctx context.Context, v *slack.View, userID *domain.SlackUserID, reportID string) {
+	l := utils.
+		WithContext(ctx, reportUsecase.logger).
+		With(zap.String("userID", userID.ID), zap.String("workspaceID", userID.WorkspaceID), zap.String("reportID", reportID))
+
+	u, err := reportUsecase.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		l.Error("couldn't get user", zap.Error(err))
+
+		return
+	}
+
+	w, err := reportUsecase.workspaceRepository.GetByID(ctx, userID.WorkspaceID)
+	if err != nil {
+		l.Error("couldn't get workspace", zap.Error(err))
+
+		return
+	}
+
+	// NOTE: A failure is shown as a report w/o bookmarks, so it's still shared as is.
+	bs, err := alertUtil.GetBookmarks(u.GetAccessToken(), reportID)
+	if err != nil {
+		l.Error("couldn't obtain bookmarks", zap.Error(err))
+	}
+
+	api := slack.New(w.BotAccessToken)
+	_, err = api.UpdateView(*modals.ShowChooseBookmarkControls(v, reportID, bs), v.ExternalID, "", v.ID)
+	if err != nil {
+		l.Error("couldn't update bookmarks view", zap.Error(err))
+	}
+}
+
 // ShowSelectReportModal shows select report modal dialog
 func (reportUsecase *ReportUsecase) ShowSelectReportModal(ctx context.Context, o *usecases.ModalOptions) {
 	l := utils.
@@ -142,6 +218,7 @@ func (reportUsecase *ReportUsecase) ShowChooseReportModal(ctx context.Context, o
 		return
 	}
 
+	reports = RemoveNonReports(reports)
 	var modal modals.ISlackModal
 	if len(reports) > 0 {
 		reducedReports := ReduceReportQuantity(reports, *o.User.GetSlackUserID())
@@ -383,8 +460,12 @@ func (reportUsecase *ReportUsecase) postScheduledReports(ctx context.Context) er
 	reportUsecase.activePagesFilter.Handle(ctx, tasks)
 
 	ts, _ := reportUsecase.GetActualScheduledReports(ctx) //here we get checked reports
+	window := time.Now().UTC().Truncate(scheduledPostingWindow)
 
-	for _, t := range ts {
+	// NOTE: A page of the same report due for the same user is rendered once & posted to each channel it's due in. Reports of different users are never coalesced, as they may see different data.
+	groups := map[string][]*scheduledPage{}
+	keys := []string(nil)
+	for ti, t := range ts {
 		slackUserID := domain.SlackUserID{
 			WorkspaceID: t.WorkspaceID,
 			ID:          t.UserID,
@@ -409,45 +490,97 @@ func (reportUsecase *ReportUsecase) postScheduledReports(ctx context.Context) er
 			pageIDsToNames[p.Name] = p.DisplayName
 		}
 
-		pms := []*messagequeue.PageMessage(nil)
-		for _, i := range t.PageIDs {
-			pm := messagequeue.PageMessage{
-				ID:   i,
-				Name: pageIDsToNames[i],
+		// NOTE: Date tokens of filters are resolved on each run in the time zone of a task, so e.g. "last 7 days" moves along.
+		location, err := time.LoadLocation(t.TZ)
+		if err != nil {
+			l.Warn("couldn't load time zone", zap.Error(err), zap.String("tz", t.TZ))
+
+			location = time.UTC
+		}
+
+		t.Filters = domain.ResolveFilterTokens(t.Filters, time.Now().In(location))
+		filtersJSON, err := json.Marshal(t.Filters)
+		if err != nil {
+			l.Error("couldn't marshal filters", zap.Error(err))
+
+			continue
+		}
+
+		// NOTE: A document holds all pages of a task, so they're posted by a single message; an image of each page is posted by a message of its own.
+		batches := [][]string{t.PageIDs}
+		if !messagequeue.OutputFormat(t.OutputFormat).IsDocument() {
+			batches = nil
+			for _, i := range t.PageIDs {
+				batches = append(batches, []string{i})
 			}
-			pms = append(pms, &pm)
 		}
 
-		for _, page := range pms {
-			m := messagequeue.PostReportMessage{
-				RenderReportMessage: &messagequeue.RenderReportMessage{
-					ClientID:    "slack",
-					ReportID:    t.ReportID,
-					ReportName:  report.GetName(),
-					Pages:       []*messagequeue.PageMessage{page},
-					UserID:      t.UserID,
-					ChannelID:   t.ChannelID,
-					WorkspaceID: t.WorkspaceID,
-					UniqueID:    uuid.New().String(),
-				},
-				IsScheduled: true,
+		for _, is := range batches {
+			sp := scheduledPage{
+				taskIndex:  ti,
+				reportName: report.GetName(),
 			}
-			e := messagequeue.Envelope{
-				Kind:    messagequeue.MessagePostReport,
-				Body:    m,
-				TraceID: strconv.FormatInt(t.ID, 10),
+			for _, i := range is {
+				sp.pages = append(sp.pages, &messagequeue.PageMessage{
+					ID:   i,
+					Name: pageIDsToNames[i],
+				})
 			}
-			err = reportUsecase.mq.Push(ctx, &e, messagequeue.Wait)
-			if err != nil {
-				l.Error("couldn't enqueue message", zap.Error(err))
+
+			// NOTE: A single visual (or a page w/ data attached, a bookmark applied, filters, a mobile layout or another format) is rendered apart from the same page w/o them.
+			k := fmt.Sprintf("%v/%v/%v/%v/%v/%v/%v/%s/%v/%v", t.WorkspaceID, t.UserID, t.ReportID, sp.pageIDs(), t.VisualName, t.DataFormat, t.BookmarkName, filtersJSON, t.Layout, t.OutputFormat)
+			_, ok := groups[k]
+			if !ok {
+				keys = append(keys, k)
+			}
+
+			groups[k] = append(groups[k], &sp)
+		}
+	}
+
+	// NOTE: Messages of all tasks are pushed at once; owners maps each of them to its (first) task.
+	es := []*messagequeue.Envelope(nil)
+	owners := []int(nil)
+	for _, k := range keys {
+		sps := groups[k]
+		e, err := newScheduledEnvelope(reportUsecase.keyRing, ts, sps, k, window)
+		if err != nil {
+			l.Error("couldn't seal message tokens", zap.Error(err), zap.Int64("taskID", ts[sps[0].taskIndex].ID))
+
+			continue
+		}
+
+		es = append(es, e)
+		owners = append(owners, sps[0].taskIndex)
+	}
+
+	if len(es) > 0 {
+		err := reportUsecase.mq.PushBatch(ctx, es, messagequeue.Wait)
+		if err != nil {
+			be, ok := err.(*messagequeue.BatchError)
+			for i, e := range es {
+				pushErr := err
+				if ok {
+					pushErr = be.Errs[i]
+				}
+
+				if pushErr == nil {
+					continue
+				}
+
+				t := ts[owners[i]]
+				l.Error("couldn't enqueue message", zap.Error(pushErr), zap.String("traceID", e.TraceID))
 
 				reportProperty := json.RawMessage(fmt.Sprintf(`{"reportID": "%v"}`, t.ReportID))
 				p := amplitude.Properties{
 					"report": &reportProperty,
 				}
-				analytics.DefaultAmplitudeClient().Send(analytics.EventKindReportsScheduleFailed, slackUserID.WorkspaceID, slackUserID.ID, p)
+				analytics.DefaultAmplitudeClient().Send(analytics.EventKindReportsScheduleFailed, t.WorkspaceID, t.UserID, p)
 			}
 		}
+	}
+
+	for _, t := range ts {
 		if t.IsEveryHour {
 			err := reportUsecase.postingTaskRepository.UpdateHourlyReports(ctx, t.ID)
 			if err != nil {
@@ -458,6 +591,147 @@ func (reportUsecase *ReportUsecase) postScheduledReports(ctx context.Context) er
 	return nil
 }
 
+// scheduledPage is a page of a task due to be posted; it's all pages of the task if they're posted as a document.
+type scheduledPage struct {
+	taskIndex  int
+	reportName string
+	pages      []*messagequeue.PageMessage
+}
+
+// pageIDs joins IDs of pages, so it's an ID of the page itself unless they're posted as a document.
+func (sp *scheduledPage) pageIDs() string {
+	ids := []string(nil)
+	for _, p := range sp.pages {
+		ids = append(ids, p.ID)
+	}
+
+	return strings.Join(ids, ",")
+}
+
+// newScheduledEnvelope creates a message posting a page (or a document of pages) to each of tasks it's due in; sps share the same owner, report, pages & visual.
+// NOTE: Slack tokens are looked up by report engine, so sealed ones are empty; they're sealed w/ k anyway not to produce plaintext ones.
+func newScheduledEnvelope(k *messagequeue.KeyRing, ts []*domain.PostReportTask, sps []*scheduledPage, key string, window time.Time) (*messagequeue.Envelope, error) {
+	sp := sps[0]
+	t := ts[sp.taskIndex]
+	r := messagequeue.RenderReportMessage{
+		ClientID:     "slack",
+		ReportID:     t.ReportID,
+		ReportName:   sp.reportName,
+		Pages:        sp.pages,
+		UserID:       t.UserID,
+		ChannelID:    t.ChannelID,
+		WorkspaceID:  t.WorkspaceID,
+		UniqueID:     newScheduledMessageID(t.ID, sp.pageIDs(), window),
+		VisualName:   t.VisualName,
+		DataFormat:   messagequeue.DataFormat(t.DataFormat),
+		BookmarkName: t.BookmarkName,
+		Filters:      newTypedFilterMessages(t.Filters),
+		Layout:       messagequeue.Layout(t.Layout),
+		OutputFormat: messagequeue.OutputFormat(t.OutputFormat),
+	}
+	e := messagequeue.Envelope{
+		Kind: messagequeue.MessagePostReport,
+		Body: messagequeue.PostReportMessage{
+			RenderReportMessage: &r,
+			IsScheduled:         true,
+		},
+		TraceID:  strconv.FormatInt(t.ID, 10),
+		Priority: messagequeue.PriorityScheduled,
+	}
+	if len(sps) == 1 {
+		err := r.SealTokens(k, &messagequeue.Tokens{})
+		if err != nil {
+			return nil, err
+		}
+
+		return &e, nil
+	}
+
+	dts := []*messagequeue.DeliveryTargetMessage(nil)
+	for _, sp := range sps {
+		t := ts[sp.taskIndex]
+		dt := messagequeue.DeliveryTargetMessage{
+			ClientID:    "slack",
+			UserID:      t.UserID,
+			ChannelID:   t.ChannelID,
+			WorkspaceID: t.WorkspaceID,
+			UniqueID:    newScheduledMessageID(t.ID, sp.pageIDs(), window),
+		}
+		dts = append(dts, &dt)
+	}
+
+	name := fmt.Sprintf("%v/%v", key, window.Format(time.RFC3339))
+	r.ChannelID = ""
+	r.UniqueID = uuid.NewSHA1(scheduledMessageNamespace, []byte(name)).String()
+	e.Kind = messagequeue.MessageDistributeReport
+	e.Body = messagequeue.DistributeReportMessage{
+		RenderReportMessage: &r,
+		Targets:             dts,
+		IsScheduled:         true,
+	}
+
+	// NOTE: UniqueID is bound to sealed tokens, so they're sealed once it's final.
+	err := r.SealTokens(k, &messagequeue.Tokens{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// newTypedFilterMessages maps filters of a domain.PostReportTask to filters of a messagequeue.RenderReportMessage.
+func newTypedFilterMessages(fs []*domain.TypedFilter) []*messagequeue.TypedFilterMessage {
+	ms := []*messagequeue.TypedFilterMessage(nil)
+	for _, f := range fs {
+		m := messagequeue.TypedFilterMessage{
+			Kind:            messagequeue.FilterKind(f.Kind),
+			Target:          newFilterTargetMessage(f.Target),
+			Operator:        f.Operator,
+			Values:          f.Values,
+			LogicalOperator: f.LogicalOperator,
+			TimeUnitsCount:  f.TimeUnitsCount,
+			TimeUnit:        f.TimeUnit,
+			IncludeToday:    f.IncludeToday,
+			ItemCount:       f.ItemCount,
+			OrderBy:         newFilterTargetMessage(f.OrderBy),
+			Tuples:          f.Tuples,
+		}
+		for _, c := range f.Conditions {
+			m.Conditions = append(m.Conditions, &messagequeue.FilterConditionMessage{
+				Operator: c.Operator,
+				Value:    c.Value,
+			})
+		}
+
+		for _, t := range f.Targets {
+			m.Targets = append(m.Targets, newFilterTargetMessage(t))
+		}
+
+		ms = append(ms, &m)
+	}
+
+	return ms
+}
+
+func newFilterTargetMessage(t *domain.FilterTarget) *messagequeue.FilterTargetMessage {
+	if t == nil {
+		return nil
+	}
+
+	return &messagequeue.FilterTargetMessage{
+		Table:   t.Table,
+		Column:  t.Column,
+		Measure: t.Measure,
+	}
+}
+
+// NOTE: Scheduled message ID is derived from the task, the page & the posting window, so a run repeated within the same window (e.g. after restart) doesn't post a report twice.
+func newScheduledMessageID(taskID int64, pageID string, window time.Time) string {
+	name := fmt.Sprintf("%v/%v/%v", taskID, pageID, window.Format(time.RFC3339))
+
+	return uuid.NewSHA1(scheduledMessageNamespace, []byte(name)).String()
+}
+
 // RemoveEmptyReports delete reports which doesn't have scheduled reports.
 func RemoveEmptyReports(ctx context.Context, reportsBI domain.GroupedReports, reportUsecase usecases.ReportUsecase, u domain.User) (domain.GroupedReports, error) {
 	dbReportIDs, err := reportUsecase.GetPowerBIReportIDsByUser(ctx, *u.GetSlackUserID())
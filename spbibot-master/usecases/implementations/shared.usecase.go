@@ -1,7 +1,5 @@
 package implementations
 
-
-
 func removeUnused(allReportsBI domain.GroupedReports, usedReportIDs []string) domain.GroupedReports {
 	newReportsBI := make(map[*domain.Group]*domain.ReportsContainer)
 
@@ -21,6 +19,28 @@ func removeUnused(allReportsBI domain.GroupedReports, usedReportIDs []string) do
 	return newReportsBI
 }
 
+// RemoveNonReports keeps reports only, since alerts & filters need pages & visuals, which dashboards, tiles & paginated reports don't have.
+func RemoveNonReports(allReportsBI domain.GroupedReports) domain.GroupedReports {
+	newReportsBI := make(map[*domain.Group]*domain.ReportsContainer)
+
+	for i, workspace := range allReportsBI {
+		var reports []domain.IReport
+		for _, report := range workspace.Value {
+			if report.GetType() == domain.ReportTypeReport {
+				reports = append(reports, report)
+			}
+		}
+		if len(reports) > 0 {
+			newReportsBI[i] = &domain.ReportsContainer{
+				Type:  workspace.Type,
+				Value: reports,
+			}
+		}
+	}
+
+	return newReportsBI
+}
+
 func contains(dbReportIDs []string, reportPowerBI domain.IReport) bool {
 	for _, rep := range dbReportIDs {
 		if rep == reportPowerBI.GetID() {
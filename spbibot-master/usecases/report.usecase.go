@@ -3,6 +3,8 @@ package usecases
 import (
 	"context"
 
+	"github.com/slack-go/slack"
+
 
 )
 
@@ -10,6 +12,8 @@ import (
 type ReportUsecase interface {
 	GetGroupedReports(userID domain.SlackUserID) (domain.GroupedReports, error)
 	GetPages(userID *domain.SlackUserID, reportID string) ([]*domain.Page, error)
+	UpdateChooseVisualControls(ctx context.Context, v *slack.View, userID *domain.SlackUserID, reportID, pageID string)
+	UpdateChooseBookmarkControls(ctx context.Context, v *slack.View, userID *domain.SlackUserID, reportID string)
 	GetScheduledReports(ctx context.Context, u domain.SlackUserID, reportID string) ([]*domain.PostReportTask, error)
 	GetPowerBIReportIDsByUser(ctx context.Context, u domain.SlackUserID) ([]string, error)
 	GetActualScheduledReports(ctx context.Context) ([]*domain.PostReportTask, error)
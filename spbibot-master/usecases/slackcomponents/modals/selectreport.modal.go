@@ -156,6 +156,8 @@ type ShareReportInput struct {
 	ReuseFilter     *ReuseFilterInput
 	EditFilter      *EditInFilterInput
 	SaveFilter      *SaveInFilterInput
+	// Filters are filters added w/ "Add another filter" followed by the one being composed.
+	Filters []*domain.TypedFilter
 }
 
 // NewShareReportInput builds a ShareReportInput from a slack.View.
@@ -165,12 +167,21 @@ func NewShareReportInput(v *slack.View) (*ShareReportInput, error) {
 		return nil, err
 	}
 
-	return &ShareReportInput{
+	i := ShareReportInput{
 		ReportSelection: r,
 		ReuseFilter:     newReuseFilterInput(v),
 		EditFilter:      newEditInFilterInput(v),
 		SaveFilter:      newSaveInFilterInput(v),
-	}, nil
+	}
+	if v.CallbackID == constants.CallbackIDShareReportEditFilter || v.CallbackID == constants.CallbackIDShareReportSaveFilter {
+		i.Filters = r.Filters
+		f, _, _ := NewComposedFilterInput(v)
+		if f != nil {
+			i.Filters = append(i.Filters, f)
+		}
+	}
+
+	return &i, nil
 }
 
 // PageInput represents a page item.
@@ -186,6 +197,41 @@ type ReportSelectionInput struct {
 	ReportName  string       `json:"reportName"`
 	ApplyFilter bool         `json:"applyFilter"`
 	Pages       []*PageInput `json:"pages"`
+	// SingleVisual is set if a single visual is shared instead of whole pages.
+	SingleVisual bool `json:"singleVisual,omitempty"`
+	// VisualName is a title of the chosen visual, which belongs to a page of VisualPageID.
+	VisualName   string `json:"visualName,omitempty"`
+	VisualPageID string `json:"visualPageID,omitempty"`
+	// DataFormat is a format data of visuals is attached in along w/ images; it's empty if data isn't attached.
+	DataFormat string `json:"dataFormat,omitempty"`
+	// Layout is a mobile layout pages are rendered in; it's empty for the master one.
+	Layout string `json:"layout,omitempty"`
+	// OutputFormat is a format pages are posted in; it's empty for an image of each page.
+	OutputFormat string `json:"outputFormat,omitempty"`
+	// BookmarkName is a name of the chosen bookmark; it's empty if no bookmark is applied.
+	BookmarkName        string `json:"bookmarkName,omitempty"`
+	BookmarkDisplayName string `json:"bookmarkDisplayName,omitempty"`
+	// Filters are filters added already w/ "Add another filter"; the one being composed isn't among them.
+	Filters []*domain.TypedFilter `json:"filters,omitempty"`
+	// SavedFilterID is an id of a saved filter chosen for a scheduled report; it's empty if no filter is applied.
+	SavedFilterID string `json:"savedFilterID,omitempty"`
+}
+
+// ValidateVisual returns a warning to show if a single visual is shared, but it isn't chosen for exactly one page.
+func (i *ReportSelectionInput) ValidateVisual() string {
+	if !i.SingleVisual {
+		return ""
+	}
+
+	if len(i.Pages) != 1 {
+		return constants.WarningVisualNeedsSinglePage
+	}
+
+	if i.VisualName == "" || i.VisualPageID != i.Pages[0].ID {
+		return constants.WarningChooseVisual
+	}
+
+	return ""
 }
 
 // NewReportSelectionInput builds a ReportSelectionInput from a slack.View.
@@ -233,8 +279,9 @@ func newReportSelectionInputFromState(s *slack.ViewState, bs []slack.Block) *Rep
 
 	i.ChannelID = s.Values[constants.BlockIDChannel][constants.ActionIDChannel].SelectedConversation
 
+	// NOTE: Filters aren't applied to a dashboard, a tile or a paginated report, so it's shared w/o them.
 	applyFilterOptions := s.Values[constants.BlockIDApplyFilter][constants.ActionIDApplyFilter].SelectedOptions
-	i.ApplyFilter = len(applyFilterOptions) == 1 && applyFilterOptions[0].Value == constants.ValueApplyFilter
+	i.ApplyFilter = len(applyFilterOptions) == 1 && applyFilterOptions[0].Value == constants.ValueApplyFilter && IsReportOfPages(i.ReportID)
 
 	pages := []*PageInput(nil)
 	pagesBlock := findBlockState(s, constants.BlockIDPages)
@@ -251,6 +298,59 @@ func newReportSelectionInputFromState(s *slack.ViewState, bs []slack.Block) *Rep
 		i.Pages = pages
 	}
 
+	shareModeBlock := findBlockState(s, constants.BlockIDShareMode)
+	if shareModeBlock != nil {
+		i.SingleVisual = shareModeBlock[constants.ActionIDShareMode].SelectedOption.Value == constants.ValueShareModeVisual
+	}
+
+	attachDataBlock := findBlockState(s, constants.BlockIDAttachData)
+	if attachDataBlock != nil {
+		v := attachDataBlock[constants.ActionIDAttachData].SelectedOption.Value
+		if v == constants.ValueAttachDataCSV || v == constants.ValueAttachDataZIP {
+			i.DataFormat = v
+		}
+	}
+
+	layoutBlock := findBlockState(s, constants.BlockIDLayout)
+	if layoutBlock != nil {
+		v := layoutBlock[constants.ActionIDLayout].SelectedOption.Value
+		if v == constants.ValueLayoutMobilePortrait || v == constants.ValueLayoutMobileLandscape {
+			i.Layout = v
+		}
+	}
+
+	outputFormatBlock := findBlockState(s, constants.BlockIDOutputFormat)
+	if outputFormatBlock != nil {
+		v := outputFormatBlock[constants.ActionIDOutputFormat].SelectedOption.Value
+		if v == constants.ValueOutputFormatPDF || v == constants.ValueOutputFormatXLSX {
+			i.OutputFormat = v
+		}
+	}
+
+	bookmarkBlock := findBlockState(s, constants.BlockIDBookmark)
+	if bookmarkBlock != nil {
+		bookmarkOption := bookmarkBlock[constants.ActionIDBookmark].SelectedOption
+		if bookmarkOption.Text != nil {
+			i.BookmarkName = bookmarkOption.Value
+			i.BookmarkDisplayName = bookmarkOption.Text.Text
+		}
+	}
+
+	savedFilterBlock := findBlockState(s, constants.BlockIDSavedFilter)
+	if savedFilterBlock != nil {
+		i.SavedFilterID = savedFilterBlock[constants.ActionIDSavedFilter].SelectedOption.Value
+	}
+
+	// NOTE: Visual block id is suffixed w/ the page id, so a visual chosen before the page was changed is detected.
+	visualBlockID := FindBlock(bs, constants.BlockIDVisual)
+	if i.SingleVisual && visualBlockID != "" {
+		visualOption := s.Values[visualBlockID][constants.ActionIDVisual].SelectedOption
+		if visualOption.Text != nil {
+			i.VisualName = visualOption.Text.Text
+			i.VisualPageID = strings.TrimPrefix(visualBlockID, constants.BlockIDVisual)
+		}
+	}
+
 	return &i
 }
 
@@ -302,10 +402,11 @@ func newEditInFilterInput(v *slack.View) *EditInFilterInput {
 			conditionOperator = v.State.Values[constants.BlockIDConditionOperator][constants.ActionIDConditionOperator].SelectedOption.Value
 		}
 
+		// NOTE: Inputs of a filter are suffixed w/ a number of filters added before it, so they're looked up by prefix.
 		return &EditInFilterInput{
-			Column:                  v.State.Values[constants.BlockIDColumn][constants.ActionIDColumn].Value,
-			Table:                   v.State.Values[constants.BlockIDTable][constants.ActionIDTable].Value,
-			Value:                   v.State.Values[constants.BlockIDValue][constants.ActionIDValue].Value,
+			Column:                  findBlockState(v.State, constants.BlockIDColumn)[constants.ActionIDColumn].Value,
+			Table:                   findBlockState(v.State, constants.BlockIDTable)[constants.ActionIDTable].Value,
+			Value:                   findBlockState(v.State, constants.BlockIDValue)[constants.ActionIDValue].Value,
 			ConditionOperator:       conditionOperator,
 			LogicalOperator:         logicalOperation,
 			SecondValue:             v.State.Values[constants.BlockIDSecondValue][constants.ActionIDSecondValue].Value,
@@ -316,6 +417,33 @@ func newEditInFilterInput(v *slack.View) *EditInFilterInput {
 	return nil
 }
 
+// TypedFilter maps i to a filter of domain.TypedFilterAdvanced; the second condition is added if a logical operator is chosen.
+func (i *EditInFilterInput) TypedFilter() *domain.TypedFilter {
+	f := domain.TypedFilter{
+		Kind: domain.TypedFilterAdvanced,
+		Target: &domain.FilterTarget{
+			Table:  i.Table,
+			Column: i.Column,
+		},
+		LogicalOperator: i.LogicalOperator,
+		Conditions: []*domain.FilterCondition{
+			{
+				Operator: i.ConditionOperator,
+				Value:    i.Value,
+			},
+		},
+	}
+	if i.LogicalOperator != "" {
+		c := domain.FilterCondition{
+			Operator: i.SecondConditionOperator,
+			Value:    i.SecondValue,
+		}
+		f.Conditions = append(f.Conditions, &c)
+	}
+
+	return &f
+}
+
 // SaveInFilterInput holds user-entered values of the "save a filter" modal.
 type SaveInFilterInput struct {
 	EditInFilterInput
@@ -370,6 +498,41 @@ func withDisabledSelectionControls(v *slack.View) (*slack.ModalViewRequest, erro
 			return nil, err
 		}
 
+		r.Blocks.BlockSet = removeChooseVisualControls(r.Blocks.BlockSet)
+		if state.SingleVisual {
+			visualText := slackcomponents.GetSlackMarkdownTextBlock(fmt.Sprintf("*%v*: %v", constants.PlaceholderVisual, state.VisualName))
+			visualSection := slack.NewSectionBlock(visualText, nil, nil)
+			r.Blocks.BlockSet = append(r.Blocks.BlockSet, visualSection)
+		}
+
+		r.Blocks.BlockSet = removeChooseBookmarkControls(r.Blocks.BlockSet)
+		if state.BookmarkName != "" {
+			bookmarkText := slackcomponents.GetSlackMarkdownTextBlock(fmt.Sprintf("*%v*: %v", constants.PlaceholderBookmark, state.BookmarkDisplayName))
+			bookmarkSection := slack.NewSectionBlock(bookmarkText, nil, nil)
+			r.Blocks.BlockSet = append(r.Blocks.BlockSet, bookmarkSection)
+		}
+
+		r.Blocks.BlockSet = removeAttachDataControls(r.Blocks.BlockSet)
+		if state.DataFormat != "" {
+			dataText := slackcomponents.GetSlackMarkdownTextBlock(fmt.Sprintf("*%v*: %v", constants.PlaceholderAttachData, strings.ToUpper(state.DataFormat)))
+			dataSection := slack.NewSectionBlock(dataText, nil, nil)
+			r.Blocks.BlockSet = append(r.Blocks.BlockSet, dataSection)
+		}
+
+		r.Blocks.BlockSet = removeLayoutControls(r.Blocks.BlockSet)
+		if state.Layout != "" {
+			layoutText := slackcomponents.GetSlackMarkdownTextBlock(fmt.Sprintf("*%v*: %v", constants.PlaceholderLayout, layoutLabels[state.Layout]))
+			layoutSection := slack.NewSectionBlock(layoutText, nil, nil)
+			r.Blocks.BlockSet = append(r.Blocks.BlockSet, layoutSection)
+		}
+
+		r.Blocks.BlockSet = removeOutputFormatControls(r.Blocks.BlockSet)
+		if state.OutputFormat != "" {
+			outputFormatText := slackcomponents.GetSlackMarkdownTextBlock(fmt.Sprintf("*%v*: %v", constants.PlaceholderOutputFormat, outputFormatLabels[state.OutputFormat]))
+			outputFormatSection := slack.NewSectionBlock(outputFormatText, nil, nil)
+			r.Blocks.BlockSet = append(r.Blocks.BlockSet, outputFormatSection)
+		}
+
 		stateJSON, err := json.Marshal(state)
 		if err != nil {
 			return nil, err
@@ -389,12 +552,22 @@ func ShowReuseFilterControls(v *slack.View, f []*domain.Filter) (*slack.ModalVie
 	}
 
 	if r.CallbackID == constants.CallbackIDShareReportSaveFilter || r.CallbackID == constants.CallbackIDShareReportEditFilter {
-		r.Blocks.BlockSet = RemoveBlock(r.Blocks.BlockSet, constants.BlockIDFilterHeader)
-		r.Blocks.BlockSet = RemoveBlock(r.Blocks.BlockSet, constants.BlockIDTable)
-		r.Blocks.BlockSet = RemoveBlock(r.Blocks.BlockSet, constants.BlockIDColumn)
-		r.Blocks.BlockSet = RemoveBlock(r.Blocks.BlockSet, constants.BlockIDValue)
-		r.Blocks.BlockSet = RemoveBlock(r.Blocks.BlockSet, constants.BlockIDAddSecondFilter)
-		r.Blocks.BlockSet = RemoveBlock(r.Blocks.BlockSet, constants.BlockIDSaveFilter)
+		r.Blocks.BlockSet = removeBlocksByPrefix(r.Blocks.BlockSet, filterInputBlockIDs...)
+		r.Blocks.BlockSet = removeBlocksByPrefix(r.Blocks.BlockSet, constants.BlockIDFilterHeader, constants.BlockIDFilterKind, constants.BlockIDAddedFilter, constants.BlockIDAddAnotherFilter, constants.BlockIDSaveFilter)
+
+		// NOTE: Filters added already are dropped along w/ their summaries.
+		state, err := newReportSelectionInputFromPrivateMetadata(r.PrivateMetadata)
+		if err != nil {
+			return nil, err
+		}
+
+		state.Filters = nil
+		stateJSON, err := json.Marshal(state)
+		if err != nil {
+			return nil, err
+		}
+
+		r.PrivateMetadata = string(stateJSON)
 	}
 
 	if r.CallbackID == constants.CallbackIDShareReportSaveFilter {
@@ -439,28 +612,18 @@ func ShowEditFilterControls(v *slack.View) (*slack.ModalViewRequest, error) {
 	filterText := slackcomponents.GetSlackPlainTextBlock(constants.HeaderComposeFilter)
 	filterSection := slack.NewSectionBlock(filterText, nil, nil, slack.SectionBlockOptionBlockID(constants.BlockIDFilterHeader))
 
-	tableText := slackcomponents.GetSlackPlainTextBlock("Table name")
-	tableField := slack.NewPlainTextInputBlockElement(tableText, constants.ActionIDTable)
-	tableInput := slack.NewInputBlock(constants.BlockIDTable, tableText, tableField)
-
-	columnText := slackcomponents.GetSlackPlainTextBlock("Column name")
-	columnField := slack.NewPlainTextInputBlockElement(columnText, constants.ActionIDColumn)
-	columnInput := slack.NewInputBlock(constants.BlockIDColumn, columnText, columnField)
-
-	valueText := slackcomponents.GetSlackPlainTextBlock("Value")
-	valueField := slack.NewPlainTextInputBlockElement(valueText, constants.ActionIDValue)
-	valueInput := slack.NewInputBlock(constants.BlockIDValue, valueText, valueField)
-
-	addFilterText := slackcomponents.GetSlackPlainTextBlock("Add advanced filter")
-	addFilterField := slack.NewButtonBlockElement(constants.ActionIDAddSecondFilter, constants.ValueAddSecondFilter, addFilterText)
-	addFilterAction := slack.NewActionBlock(constants.BlockIDAddSecondFilter, addFilterField)
+	addAnotherFilterText := slackcomponents.GetSlackPlainTextBlock(constants.LabelAddAnotherFilter)
+	addAnotherFilterField := slack.NewButtonBlockElement(constants.ActionIDAddAnotherFilter, constants.ValueAddAnotherFilter, addAnotherFilterText)
+	addAnotherFilterAction := slack.NewActionBlock(constants.BlockIDAddAnotherFilter, addAnotherFilterField)
 
 	saveFilterLabel := slackcomponents.GetSlackPlainTextBlock("Save filter")
 	saveFilterOption := slack.NewOptionBlockObject(constants.ValueSaveFilter, saveFilterLabel, nil)
 	saveFilterCheckbox := slack.NewCheckboxGroupsBlockElement(constants.ActionIDSaveFilter, saveFilterOption)
 	saveFilterAction := slack.NewActionBlock(constants.BlockIDSaveFilter, saveFilterCheckbox)
 
-	r.Blocks.BlockSet = append(r.Blocks.BlockSet, filterSection, tableInput, columnInput, valueInput, addFilterAction, saveFilterAction)
+	r.Blocks.BlockSet = append(r.Blocks.BlockSet, filterSection, newFilterKindAction(domain.TypedFilterAdvanced, ""))
+	r.Blocks.BlockSet = append(r.Blocks.BlockSet, newFilterInputs(domain.TypedFilterAdvanced, "")...)
+	r.Blocks.BlockSet = append(r.Blocks.BlockSet, addAnotherFilterAction, saveFilterAction)
 
 	r.CallbackID = constants.CallbackIDShareReportEditFilter
 
@@ -484,6 +647,392 @@ func ShowSaveFilterControls(v *slack.View) *slack.ModalViewRequest {
 	return r
 }
 
+// ShowFilterKindControls replaces inputs of the filter being composed w/ inputs of a filter of kind.
+func ShowFilterKindControls(v *slack.View, kind domain.TypedFilterKind) *slack.ModalViewRequest {
+	r := CopyModalRequest(v)
+
+	kindBlockID := FindBlock(r.Blocks.BlockSet, constants.BlockIDFilterKind)
+	tag := strings.TrimPrefix(kindBlockID, constants.BlockIDFilterKind)
+	r.Blocks.BlockSet = removeBlocksByPrefix(r.Blocks.BlockSet, filterInputBlockIDs...)
+	r.Blocks.BlockSet, _ = replaceBlock(r.Blocks.BlockSet, kindBlockID, newFilterKindAction(kind, tag))
+	r.Blocks.BlockSet, _ = addBlockAfter(r.Blocks.BlockSet, kindBlockID, newFilterInputs(kind, tag)...)
+
+	return r
+}
+
+// AddComposedFilter keeps the filter being composed in private metadata & shows empty inputs of the next filter applied along w/ it.
+// A warning is shown instead if the filter isn't filled in.
+func AddComposedFilter(v *slack.View) (*slack.ModalViewRequest, error) {
+	r := CopyModalRequest(v)
+	r.Blocks.BlockSet = RemoveBlock(r.Blocks.BlockSet, constants.BlockIDFilterWarning)
+
+	f, _, warning := NewComposedFilterInput(v)
+	if f == nil {
+		if warning == "" {
+			warning = constants.WarningIncompleteFilter
+		}
+
+		warningText := slackcomponents.GetSlackMarkdownTextBlock(warning)
+		warningContext := slack.NewContextBlock(constants.BlockIDFilterWarning, warningText)
+		r.Blocks.BlockSet, _ = addBlockBefore(r.Blocks.BlockSet, warningContext, constants.BlockIDAddAnotherFilter)
+
+		return r, nil
+	}
+
+	state, err := newReportSelectionInputFromPrivateMetadata(v.PrivateMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	state.Filters = append(state.Filters, f)
+	stateJSON, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+
+	r.PrivateMetadata = string(stateJSON)
+
+	// NOTE: Block ids of the next filter are suffixed w/ a number of filters added, so Slack doesn't fill them in w/ values of the previous one.
+	n := len(state.Filters)
+	tag := strconv.Itoa(n)
+	afterBlockID := constants.BlockIDFilterHeader
+	if n > 1 {
+		afterBlockID = constants.BlockIDAddedFilter + strconv.Itoa(n-2)
+	}
+
+	addedFilterText := slackcomponents.GetSlackMarkdownTextBlock(fmt.Sprintf("*Filter %v*: %v", n, f))
+	addedFilterSection := slack.NewSectionBlock(addedFilterText, nil, nil, slack.SectionBlockOptionBlockID(constants.BlockIDAddedFilter+strconv.Itoa(n-1)))
+
+	r.Blocks.BlockSet = removeBlocksByPrefix(r.Blocks.BlockSet, filterInputBlockIDs...)
+	r.Blocks.BlockSet = removeBlocksByPrefix(r.Blocks.BlockSet, constants.BlockIDFilterKind)
+	r.Blocks.BlockSet, _ = addBlockAfter(r.Blocks.BlockSet, afterBlockID, addedFilterSection, newFilterKindAction(domain.TypedFilterAdvanced, tag))
+	r.Blocks.BlockSet, _ = addBlockAfter(r.Blocks.BlockSet, constants.BlockIDFilterKind+tag, newFilterInputs(domain.TypedFilterAdvanced, tag)...)
+
+	return r, nil
+}
+
+// NewComposedFilterInput builds the filter being composed from a slack.View; nil is returned if it isn't filled in.
+// If a value is invalid, an id of its block is returned along w/ a warning to show.
+func NewComposedFilterInput(v *slack.View) (*domain.TypedFilter, string, string) {
+	kind := domain.TypedFilterKind(findBlockState(v.State, constants.BlockIDFilterKind)[constants.ActionIDFilterKind].SelectedOption.Value)
+	if kind == "" || kind == domain.TypedFilterAdvanced {
+		i := newEditInFilterInput(v)
+		if i == nil || i.Table == "" || i.Column == "" || i.Value == "" {
+			return nil, "", ""
+		}
+
+		return i.TypedFilter(), "", ""
+	}
+
+	target := &domain.FilterTarget{
+		Table:  blockValue(v.State, constants.BlockIDTable, constants.ActionIDTable),
+		Column: blockValue(v.State, constants.BlockIDColumn, constants.ActionIDColumn),
+	}
+	hasTarget := target.Table != "" && target.Column != ""
+	operator := findBlockState(v.State, constants.BlockIDFilterOperator)[constants.ActionIDFilterOperator].SelectedOption.Value
+
+	switch kind {
+	case domain.TypedFilterBasic:
+		values := splitLines(blockValue(v.State, constants.BlockIDFilterValues, constants.ActionIDFilterValues))
+		if !hasTarget || operator == "" || len(values) == 0 {
+			return nil, "", ""
+		}
+
+		return &domain.TypedFilter{
+			Kind:     kind,
+			Target:   target,
+			Operator: operator,
+			Values:   values,
+		}, "", ""
+
+	case domain.TypedFilterRelativeDate, domain.TypedFilterRelativeTime:
+		count := blockValue(v.State, constants.BlockIDRelativeCount, constants.ActionIDRelativeCount)
+		unit := findBlockState(v.State, constants.BlockIDRelativeUnit)[constants.ActionIDRelativeUnit].SelectedOption.Value
+		if !hasTarget || operator == "" || count == "" || unit == "" {
+			return nil, "", ""
+		}
+
+		n, err := strconv.Atoi(count)
+		if err != nil || n <= 0 {
+			return nil, FindBlock(v.Blocks.BlockSet, constants.BlockIDRelativeCount), constants.WarningNotPositiveNumber
+		}
+
+		f := domain.TypedFilter{
+			Kind:           kind,
+			Target:         target,
+			Operator:       operator,
+			TimeUnitsCount: n,
+			TimeUnit:       unit,
+		}
+		if kind == domain.TypedFilterRelativeDate {
+			f.IncludeToday = len(findBlockState(v.State, constants.BlockIDIncludeToday)[constants.ActionIDIncludeToday].SelectedOptions) != 0
+		}
+
+		return &f, "", ""
+
+	case domain.TypedFilterTopN:
+		count := blockValue(v.State, constants.BlockIDItemCount, constants.ActionIDItemCount)
+		orderBy := &domain.FilterTarget{
+			Table:   blockValue(v.State, constants.BlockIDOrderByTable, constants.ActionIDOrderByTable),
+			Measure: blockValue(v.State, constants.BlockIDOrderByMeasure, constants.ActionIDOrderByMeasure),
+		}
+		if !hasTarget || operator == "" || count == "" || orderBy.Table == "" || orderBy.Measure == "" {
+			return nil, "", ""
+		}
+
+		n, err := strconv.Atoi(count)
+		if err != nil || n <= 0 {
+			return nil, FindBlock(v.Blocks.BlockSet, constants.BlockIDItemCount), constants.WarningNotPositiveNumber
+		}
+
+		return &domain.TypedFilter{
+			Kind:      kind,
+			Target:    target,
+			Operator:  operator,
+			ItemCount: n,
+			OrderBy:   orderBy,
+		}, "", ""
+
+	case domain.TypedFilterTuple:
+		columns := splitLines(blockValue(v.State, constants.BlockIDTupleColumns, constants.ActionIDTupleColumns))
+		tuples := splitLines(blockValue(v.State, constants.BlockIDTupleValues, constants.ActionIDTupleValues))
+		if len(columns) == 0 || len(tuples) == 0 {
+			return nil, "", ""
+		}
+
+		f := domain.TypedFilter{
+			Kind: kind,
+		}
+		for _, c := range columns {
+			ps := strings.SplitN(c, ".", 2)
+			if len(ps) != 2 || strings.TrimSpace(ps[0]) == "" || strings.TrimSpace(ps[1]) == "" {
+				return nil, FindBlock(v.Blocks.BlockSet, constants.BlockIDTupleColumns), constants.WarningInvalidTupleColumns
+			}
+
+			t := domain.FilterTarget{
+				Table:  strings.TrimSpace(ps[0]),
+				Column: strings.TrimSpace(ps[1]),
+			}
+			f.Targets = append(f.Targets, &t)
+		}
+
+		for _, t := range tuples {
+			vs := strings.Split(t, ",")
+			if len(vs) != len(f.Targets) {
+				return nil, FindBlock(v.Blocks.BlockSet, constants.BlockIDTupleValues), constants.WarningInvalidTupleValues
+			}
+
+			for i := range vs {
+				vs[i] = strings.TrimSpace(vs[i])
+			}
+
+			f.Tuples = append(f.Tuples, vs)
+		}
+
+		return &f, "", ""
+	}
+
+	return nil, "", ""
+}
+
+// filterInputBlockIDs are block id prefixes of inputs of the filter being composed, of any kind.
+var filterInputBlockIDs = []string{
+	constants.BlockIDTable,
+	constants.BlockIDColumn,
+	constants.BlockIDValue,
+	constants.BlockIDConditionOperator,
+	constants.BlockIDLogicalOperator,
+	constants.BlockIDSecondConditionOperator,
+	constants.BlockIDSecondValue,
+	constants.BlockIDAddSecondFilter,
+	constants.BlockIDRemoveSecondFilter,
+	constants.BlockIDFilterOperator,
+	constants.BlockIDFilterValues,
+	constants.BlockIDRelativeCount,
+	constants.BlockIDRelativeUnit,
+	constants.BlockIDIncludeToday,
+	constants.BlockIDItemCount,
+	constants.BlockIDOrderByTable,
+	constants.BlockIDOrderByMeasure,
+	constants.BlockIDTupleColumns,
+	constants.BlockIDTupleValues,
+	constants.BlockIDFilterWarning,
+}
+
+type filterOption struct {
+	value string
+	label string
+}
+
+var (
+	filterKinds = []filterOption{
+		{string(domain.TypedFilterAdvanced), "Advanced"},
+		{string(domain.TypedFilterBasic), "Basic"},
+		{string(domain.TypedFilterRelativeDate), "Relative date"},
+		{string(domain.TypedFilterRelativeTime), "Relative time"},
+		{string(domain.TypedFilterTopN), "Top N"},
+		{string(domain.TypedFilterTuple), "Multiple columns"},
+	}
+	basicFilterOperators = []filterOption{
+		{"In", "Is any of"},
+		{"NotIn", "Is none of"},
+	}
+	relativeFilterOperators = []filterOption{
+		{"InLast", "In the last"},
+		{"InThis", "In this"},
+		{"InNext", "In the next"},
+	}
+	topNFilterOperators = []filterOption{
+		{"Top", "Top"},
+		{"Bottom", "Bottom"},
+	}
+	relativeDateUnits = []filterOption{
+		{"Days", "Days"},
+		{"Weeks", "Weeks"},
+		{"CalendarWeeks", "Calendar weeks"},
+		{"Months", "Months"},
+		{"CalendarMonths", "Calendar months"},
+		{"Years", "Years"},
+		{"CalendarYears", "Calendar years"},
+	}
+	relativeTimeUnits = []filterOption{
+		{"Minutes", "Minutes"},
+		{"Hours", "Hours"},
+	}
+)
+
+func buildFilterOptions(fos []filterOption) []*slack.OptionBlockObject {
+	os := []*slack.OptionBlockObject(nil)
+	for _, o := range fos {
+		t := slackcomponents.GetSlackPlainTextBlock(o.label)
+		os = append(os, slack.NewOptionBlockObject(o.value, t, nil))
+	}
+
+	return os
+}
+
+// newFilterKindAction builds the filter type dropdown w/ kind chosen.
+func newFilterKindAction(kind domain.TypedFilterKind, tag string) *slack.ActionBlock {
+	kindPlaceholder := slackcomponents.GetSlackPlainTextBlock(constants.PlaceholderFilterKind)
+	kindSelect := slack.NewOptionsSelectBlockElement(
+		slack.OptTypeStatic,
+		kindPlaceholder,
+		constants.ActionIDFilterKind,
+		buildFilterOptions(filterKinds)...,
+	)
+	for _, o := range kindSelect.Options {
+		if o.Value == string(kind) {
+			kindSelect.InitialOption = o
+		}
+	}
+
+	return slack.NewActionBlock(constants.BlockIDFilterKind+tag, kindSelect)
+}
+
+// newFilterInputs builds inputs of a filter of kind; block ids are suffixed w/ tag.
+func newFilterInputs(kind domain.TypedFilterKind, tag string) []slack.Block {
+	tableInput := newFilterTextInput(constants.BlockIDTable+tag, constants.ActionIDTable, "Table name", false)
+	columnInput := newFilterTextInput(constants.BlockIDColumn+tag, constants.ActionIDColumn, "Column name", false)
+
+	switch kind {
+	case domain.TypedFilterBasic:
+		operatorInput := newFilterSelectInput(constants.BlockIDFilterOperator+tag, constants.ActionIDFilterOperator, "Condition", basicFilterOperators)
+		valuesInput := newFilterTextInput(constants.BlockIDFilterValues+tag, constants.ActionIDFilterValues, "Values, one per line", true)
+
+		return []slack.Block{tableInput, columnInput, operatorInput, valuesInput}
+
+	case domain.TypedFilterRelativeDate, domain.TypedFilterRelativeTime:
+		units := relativeDateUnits
+		if kind == domain.TypedFilterRelativeTime {
+			units = relativeTimeUnits
+		}
+
+		operatorInput := newFilterSelectInput(constants.BlockIDFilterOperator+tag, constants.ActionIDFilterOperator, "Condition", relativeFilterOperators)
+		countInput := newFilterTextInput(constants.BlockIDRelativeCount+tag, constants.ActionIDRelativeCount, "Count", false)
+		unitInput := newFilterSelectInput(constants.BlockIDRelativeUnit+tag, constants.ActionIDRelativeUnit, "Unit", units)
+		bs := []slack.Block{tableInput, columnInput, operatorInput, countInput, unitInput}
+		if kind == domain.TypedFilterRelativeDate {
+			includeTodayLabel := slackcomponents.GetSlackPlainTextBlock("Include today")
+			includeTodayOption := slack.NewOptionBlockObject(constants.ValueIncludeToday, includeTodayLabel, nil)
+			includeTodayCheckbox := slack.NewCheckboxGroupsBlockElement(constants.ActionIDIncludeToday, includeTodayOption)
+			includeTodayInput := slack.NewInputBlock(constants.BlockIDIncludeToday+tag, includeTodayLabel, includeTodayCheckbox)
+			includeTodayInput.Optional = true
+			bs = append(bs, includeTodayInput)
+		}
+
+		return bs
+
+	case domain.TypedFilterTopN:
+		operatorInput := newFilterSelectInput(constants.BlockIDFilterOperator+tag, constants.ActionIDFilterOperator, "Show items", topNFilterOperators)
+		countInput := newFilterTextInput(constants.BlockIDItemCount+tag, constants.ActionIDItemCount, "Number of items", false)
+		orderByTableInput := newFilterTextInput(constants.BlockIDOrderByTable+tag, constants.ActionIDOrderByTable, "By value of table", false)
+		orderByMeasureInput := newFilterTextInput(constants.BlockIDOrderByMeasure+tag, constants.ActionIDOrderByMeasure, "By value of measure", false)
+
+		return []slack.Block{tableInput, columnInput, operatorInput, countInput, orderByTableInput, orderByMeasureInput}
+
+	case domain.TypedFilterTuple:
+		columnsInput := newFilterTextInput(constants.BlockIDTupleColumns+tag, constants.ActionIDTupleColumns, "Columns, one per line like Table.Column", true)
+		valuesInput := newFilterTextInput(constants.BlockIDTupleValues+tag, constants.ActionIDTupleValues, "Combinations, one per line w/ values separated by commas", true)
+
+		return []slack.Block{columnsInput, valuesInput}
+	}
+
+	valueInput := newFilterTextInput(constants.BlockIDValue+tag, constants.ActionIDValue, "Value", false)
+
+	addFilterText := slackcomponents.GetSlackPlainTextBlock("Add advanced filter")
+	addFilterField := slack.NewButtonBlockElement(constants.ActionIDAddSecondFilter, constants.ValueAddSecondFilter, addFilterText)
+	addFilterAction := slack.NewActionBlock(constants.BlockIDAddSecondFilter, addFilterField)
+
+	return []slack.Block{tableInput, columnInput, valueInput, addFilterAction}
+}
+
+func newFilterTextInput(blockID, actionID, label string, multiline bool) *slack.InputBlock {
+	t := slackcomponents.GetSlackPlainTextBlock(label)
+	f := slack.NewPlainTextInputBlockElement(t, actionID)
+	f.Multiline = multiline
+
+	return slack.NewInputBlock(blockID, t, f)
+}
+
+func newFilterSelectInput(blockID, actionID, label string, fos []filterOption) *slack.InputBlock {
+	t := slackcomponents.GetSlackPlainTextBlock(label)
+	e := slack.NewOptionsSelectBlockElement(
+		slack.OptTypeStatic,
+		t,
+		actionID,
+		buildFilterOptions(fos)...,
+	)
+
+	return slack.NewInputBlock(blockID, t, e)
+}
+
+func blockValue(s *slack.ViewState, idPrefix, actionID string) string {
+	return strings.TrimSpace(findBlockState(s, idPrefix)[actionID].Value)
+}
+
+// splitLines splits s into trimmed lines; empty ones are skipped.
+func splitLines(s string) []string {
+	ls := []string(nil)
+	for _, l := range strings.Split(s, "\n") {
+		l = strings.TrimSpace(l)
+		if l != "" {
+			ls = append(ls, l)
+		}
+	}
+
+	return ls
+}
+
+func removeBlocksByPrefix(bs []slack.Block, idPrefixes ...string) []slack.Block {
+	for _, p := range idPrefixes {
+		for id := FindBlock(bs, p); id != ""; id = FindBlock(bs, p) {
+			bs = RemoveBlock(bs, id)
+		}
+	}
+
+	return bs
+}
+
 func getConditionOperators() []*slack.OptionBlockObject {
 	os := []*slack.OptionBlockObject{}
 
@@ -577,7 +1126,7 @@ func FindReportsInChosenPBIWorkspace(v *slack.View, gr domain.GroupedReports, pb
 	return &foundReports
 }
 
-//FindReportsByInput finds reports by user input
+// FindReportsByInput finds reports by user input
 func FindReportsByInput(v *slack.View, gr domain.GroupedReports) *slack.ModalViewRequest {
 	r := CopyModalRequest(v)
 	reportSearchInputBlockID := FindBlock(r.Blocks.BlockSet, constants.BlockIDSearchReportInput)
@@ -728,10 +1277,13 @@ func ShowAddFilterControls(v *slack.View) *slack.ModalViewRequest {
 	removeFilterField := slack.NewButtonBlockElement(constants.ActionIDRemoveSecondFilter, constants.ValueRemoveSecondFilter, removeFilterText)
 	removeFilterAction := slack.NewActionBlock(constants.BlockIDRemoveSecondFilter, removeFilterField)
 
-	r.Blocks.BlockSet, _ = addBlockAfter(r.Blocks.BlockSet, constants.BlockIDColumn, conditionOperatorInput)
-	r.Blocks.BlockSet, _ = addBlockAfter(r.Blocks.BlockSet, constants.BlockIDValue, operationInput, selectSecondOptionInput, valueInput, removeFilterAction)
+	r.Blocks.BlockSet, _ = addBlockAfter(r.Blocks.BlockSet, FindBlock(r.Blocks.BlockSet, constants.BlockIDColumn), conditionOperatorInput)
+	r.Blocks.BlockSet, _ = addBlockAfter(r.Blocks.BlockSet, FindBlock(r.Blocks.BlockSet, constants.BlockIDValue), operationInput, selectSecondOptionInput, valueInput, removeFilterAction)
 
-	r.CallbackID = constants.CallbackIDCreateFilter
+	// NOTE: A filter composed to share a report is shared (or saved) on submit, so its callback is kept.
+	if !strings.HasPrefix(r.CallbackID, constants.CallbackIDShareReport) {
+		r.CallbackID = constants.CallbackIDCreateFilter
+	}
 
 	return r
 }
@@ -750,9 +1302,7 @@ func HideAddFilterControls(v *slack.View) *slack.ModalViewRequest {
 	addFilterField := slack.NewButtonBlockElement(constants.ActionIDAddSecondFilter, constants.ValueAddSecondFilter, addFilterText)
 	addFilterAction := slack.NewActionBlock(constants.BlockIDAddSecondFilter, addFilterField)
 
-	r.Blocks.BlockSet, _ = addBlockAfter(r.Blocks.BlockSet, constants.BlockIDValue, addFilterAction)
-
-	r.CallbackID = constants.CallbackIDShareReportEditFilter
+	r.Blocks.BlockSet, _ = addBlockAfter(r.Blocks.BlockSet, FindBlock(r.Blocks.BlockSet, constants.BlockIDValue), addFilterAction)
 
 	return r
 }
@@ -960,8 +1510,43 @@ func ShowManageFilterCurrentUpdateControls(v *slack.View, filter map[string]stri
 	return r
 }
 
+// ShowManageTypedFilterUpdateControls displays controls for update dialog of a filter combining typed filters; only its name can be changed.
+// NOTE: Its description is shown in a block w/ an id suffixed w/ the filter id, so the filter is found on submit.
+func ShowManageTypedFilterUpdateControls(v *slack.View, f *domain.Filter) *slack.ModalViewRequest {
+	r := CopyModalRequest(v)
+
+	descriptionText := slackcomponents.GetSlackMarkdownTextBlock(fmt.Sprintf("*%v*: %v", constants.PlaceholderFilter, domain.DescribeFilters(f.Definition.([]*domain.TypedFilter))))
+	descriptionSection := slack.NewSectionBlock(descriptionText, nil, nil, slack.SectionBlockOptionBlockID(constants.BlockIDFilterToUpdate+strconv.FormatInt(f.ID, 10)))
+
+	name := f.Name
+	if len(name) > constants.MaxLenghthOfFilterName {
+		name = name[:constants.MaxLenghthOfFilterName]
+	}
+
+	nameHeading := slackcomponents.GetSlackPlainTextBlock("Filter name")
+	initialNameText := slack.PlainTextInputBlockElement{
+		Type:         slack.METPlainTextInput,
+		ActionID:     constants.ActionIDName,
+		InitialValue: name,
+		MaxLength:    constants.MaxLenghthOfFilterName,
+	}
+	nameInput := slack.NewInputBlock(constants.BlockIDName, nameHeading, initialNameText)
+
+	r.Blocks.BlockSet = append(r.Blocks.BlockSet[:len(r.Blocks.BlockSet)-2], descriptionSection, nameInput)
+
+	title := name
+	if len(title) > constants.MaxLenghthOfFilterNameForTitle-1 {
+		title = title[:constants.MaxLenghthOfFilterNameForTitle-1] + "…"
+	}
+	r.Title = slackcomponents.GetSlackPlainTextBlock(title)
+	r.CallbackID = constants.CallbackIDUpdateCurrentFilter
+
+	return r
+}
+
 // ShowOrUpdateChoosePagesControls shows or updates page selection controls.
-func ShowOrUpdateChoosePagesControls(v *slack.View, ps []*domain.Page, stateTag string) (*slack.ModalViewRequest, error) {
+// Saved filters fs of the report are offered to a scheduled report only.
+func ShowOrUpdateChoosePagesControls(v *slack.View, ps []*domain.Page, fs []*domain.Filter, stateTag string) (*slack.ModalViewRequest, error) {
 	r := CopyModalRequest(v)
 
 	pagesPlaceholder := slackcomponents.GetSlackPlainTextBlock(constants.PlaceholderPages)
@@ -973,14 +1558,254 @@ func ShowOrUpdateChoosePagesControls(v *slack.View, ps []*domain.Page, stateTag
 
 	r.Blocks.BlockSet, _ = replaceBlockOrAddAfter(r.Blocks.BlockSet, pagesInput, i, afterBlockID)
 
+	// NOTE: Alerts are checked against card visuals of their own, so a single visual can't be shared there.
+	if strings.HasPrefix(r.CallbackID, constants.CallbackIDSaveAlert) {
+		return r, nil
+	}
+
+	r.Blocks.BlockSet = removeChooseVisualControls(r.Blocks.BlockSet)
+	r.Blocks.BlockSet = removeShareModeControls(r.Blocks.BlockSet)
+
+	// NOTE: Only a paginated report can be exported into a workbook.
+	r.Blocks.BlockSet = removeOutputFormatControls(r.Blocks.BlockSet)
+	outputFormatPNG := slack.NewOptionBlockObject(constants.ValueOutputFormatPNG, slackcomponents.GetSlackPlainTextBlock(constants.LabelOutputFormatPNG), nil)
+	outputFormatPDF := slack.NewOptionBlockObject(constants.ValueOutputFormatPDF, slackcomponents.GetSlackPlainTextBlock(constants.LabelOutputFormatPDF), nil)
+	outputFormatOptions := []*slack.OptionBlockObject{outputFormatPNG, outputFormatPDF}
+	if ref, err := domain.ParseReportID(stateTag); err == nil && ref.Type == domain.ReportTypePaginated {
+		outputFormatXLSX := slack.NewOptionBlockObject(constants.ValueOutputFormatXLSX, slackcomponents.GetSlackPlainTextBlock(constants.LabelOutputFormatXLSX), nil)
+		outputFormatOptions = append(outputFormatOptions, outputFormatXLSX)
+	}
+
+	outputFormatRadio := slack.NewRadioButtonsBlockElement(constants.ActionIDOutputFormat, outputFormatOptions...)
+	outputFormatRadio.InitialOption = outputFormatPNG
+	outputFormatLabel := slackcomponents.GetSlackPlainTextBlock(constants.PlaceholderOutputFormat)
+	outputFormatInput := slack.NewInputBlock(constants.BlockIDOutputFormat+stateTag, outputFormatLabel, outputFormatRadio)
+	outputFormatInput.Optional = true
+	r.Blocks.BlockSet = append(r.Blocks.BlockSet, outputFormatInput)
+
+	// NOTE: A dashboard, a tile or a paginated report is rendered as a whole, so it has no visuals, bookmarks, data, layouts or filters to choose; stateTag is an ID of a report chosen.
+	if !IsReportOfPages(stateTag) {
+		r.Blocks.BlockSet = removeChooseBookmarkControls(r.Blocks.BlockSet)
+		r.Blocks.BlockSet = removeAttachDataControls(r.Blocks.BlockSet)
+		r.Blocks.BlockSet = removeLayoutControls(r.Blocks.BlockSet)
+		r.Blocks.BlockSet = removeChooseSavedFilterControls(r.Blocks.BlockSet)
+
+		return r, nil
+	}
+
+	shareModeLabel := slackcomponents.GetSlackPlainTextBlock(constants.LabelShareModePage)
+	shareModePage := slack.NewOptionBlockObject(constants.ValueShareModePage, shareModeLabel, nil)
+	shareModeVisual := slack.NewOptionBlockObject(constants.ValueShareModeVisual, slackcomponents.GetSlackPlainTextBlock(constants.LabelShareModeVisual), nil)
+	shareModeRadio := slack.NewRadioButtonsBlockElement(constants.ActionIDShareMode, shareModePage, shareModeVisual)
+	shareModeRadio.InitialOption = shareModePage
+	// NOTE: Block id is mutated along w/ the pages one, so the choice is reset for another report.
+	shareModeAction := slack.NewActionBlock(constants.BlockIDShareMode+stateTag, shareModeRadio)
+	r.Blocks.BlockSet, _ = addBlockAfter(r.Blocks.BlockSet, constants.BlockIDPages+stateTag, shareModeAction)
+
+	// NOTE: Bookmarks are listed by a browser, so they're shown as loading till ShowChooseBookmarkControls is called.
+	r.Blocks.BlockSet = removeChooseBookmarkControls(r.Blocks.BlockSet)
+	bookmarkText := slackcomponents.GetSlackMarkdownTextBlock(constants.LabelLoadingBookmarks)
+	bookmarkSection := slack.NewSectionBlock(bookmarkText, nil, nil, slack.SectionBlockOptionBlockID(constants.BlockIDBookmark+stateTag))
+	r.Blocks.BlockSet, _ = addBlockAfter(r.Blocks.BlockSet, constants.BlockIDShareMode+stateTag, bookmarkSection)
+
+	r.Blocks.BlockSet = removeAttachDataControls(r.Blocks.BlockSet)
+	attachDataNone := slack.NewOptionBlockObject(constants.ValueAttachDataNone, slackcomponents.GetSlackPlainTextBlock(constants.LabelAttachDataNone), nil)
+	attachDataCSV := slack.NewOptionBlockObject(constants.ValueAttachDataCSV, slackcomponents.GetSlackPlainTextBlock(constants.LabelAttachDataCSV), nil)
+	attachDataZIP := slack.NewOptionBlockObject(constants.ValueAttachDataZIP, slackcomponents.GetSlackPlainTextBlock(constants.LabelAttachDataZIP), nil)
+	attachDataRadio := slack.NewRadioButtonsBlockElement(constants.ActionIDAttachData, attachDataNone, attachDataCSV, attachDataZIP)
+	attachDataRadio.InitialOption = attachDataNone
+	attachDataLabel := slackcomponents.GetSlackPlainTextBlock(constants.PlaceholderAttachData)
+	attachDataInput := slack.NewInputBlock(constants.BlockIDAttachData+stateTag, attachDataLabel, attachDataRadio)
+	attachDataInput.Optional = true
+	r.Blocks.BlockSet = append(r.Blocks.BlockSet, attachDataInput)
+
+	r.Blocks.BlockSet = removeLayoutControls(r.Blocks.BlockSet)
+	layoutMaster := slack.NewOptionBlockObject(constants.ValueLayoutMaster, slackcomponents.GetSlackPlainTextBlock(constants.LabelLayoutMaster), nil)
+	layoutMobilePortrait := slack.NewOptionBlockObject(constants.ValueLayoutMobilePortrait, slackcomponents.GetSlackPlainTextBlock(constants.LabelLayoutMobilePortrait), nil)
+	layoutMobileLandscape := slack.NewOptionBlockObject(constants.ValueLayoutMobileLandscape, slackcomponents.GetSlackPlainTextBlock(constants.LabelLayoutMobileLandscape), nil)
+	layoutRadio := slack.NewRadioButtonsBlockElement(constants.ActionIDLayout, layoutMaster, layoutMobilePortrait, layoutMobileLandscape)
+	layoutRadio.InitialOption = layoutMaster
+	layoutLabel := slackcomponents.GetSlackPlainTextBlock(constants.PlaceholderLayout)
+	layoutInput := slack.NewInputBlock(constants.BlockIDLayout+stateTag, layoutLabel, layoutRadio)
+	layoutInput.Optional = true
+	layoutInput.Hint = slackcomponents.GetSlackPlainTextBlock(constants.HintLayout)
+	r.Blocks.BlockSet = append(r.Blocks.BlockSet, layoutInput)
+
+	r.Blocks.BlockSet = removeChooseSavedFilterControls(r.Blocks.BlockSet)
+	if strings.HasPrefix(r.CallbackID, constants.CallbackIDScheduleReport) && len(fs) != 0 {
+		savedFilterPlaceholder := slackcomponents.GetSlackPlainTextBlock(constants.PlaceholderSavedFilter)
+		savedFilterSelect := buildFiltersSelect(fs, savedFilterPlaceholder, constants.ActionIDSavedFilter)
+		savedFilterInput := slack.NewInputBlock(constants.BlockIDSavedFilter+stateTag, savedFilterPlaceholder, savedFilterSelect)
+		savedFilterInput.Optional = true
+		savedFilterInput.Hint = slackcomponents.GetSlackPlainTextBlock(constants.HintSavedFilter)
+		r.Blocks.BlockSet = append(r.Blocks.BlockSet, savedFilterInput)
+	}
+
 	return r, nil
 }
 
-//UpdateChooseReportControls updates report selection controls
+// IsReportOfPages tells if a report of reportID is a report rather than a dashboard, a tile or a paginated report.
+func IsReportOfPages(reportID string) bool {
+	ref, err := domain.ParseReportID(reportID)
+
+	return err == nil && ref.Type == domain.ReportTypeReport
+}
+
+func removeShareModeControls(bs []slack.Block) []slack.Block {
+	shareModeBlockID := FindBlock(bs, constants.BlockIDShareMode)
+	if shareModeBlockID == "" {
+		return bs
+	}
+
+	return RemoveBlock(bs, shareModeBlockID)
+}
+
+func removeChooseSavedFilterControls(bs []slack.Block) []slack.Block {
+	savedFilterBlockID := FindBlock(bs, constants.BlockIDSavedFilter)
+	if savedFilterBlockID == "" {
+		return bs
+	}
+
+	return RemoveBlock(bs, savedFilterBlockID)
+}
+
+func removeAttachDataControls(bs []slack.Block) []slack.Block {
+	attachDataBlockID := FindBlock(bs, constants.BlockIDAttachData)
+	if attachDataBlockID == "" {
+		return bs
+	}
+
+	return RemoveBlock(bs, attachDataBlockID)
+}
+
+func removeLayoutControls(bs []slack.Block) []slack.Block {
+	layoutBlockID := FindBlock(bs, constants.BlockIDLayout)
+	if layoutBlockID == "" {
+		return bs
+	}
+
+	return RemoveBlock(bs, layoutBlockID)
+}
+
+func removeOutputFormatControls(bs []slack.Block) []slack.Block {
+	outputFormatBlockID := FindBlock(bs, constants.BlockIDOutputFormat)
+	if outputFormatBlockID == "" {
+		return bs
+	}
+
+	return RemoveBlock(bs, outputFormatBlockID)
+}
+
+// outputFormatLabels maps values of the "format" radio buttons to their labels.
+var outputFormatLabels = map[string]string{
+	constants.ValueOutputFormatPNG:  constants.LabelOutputFormatPNG,
+	constants.ValueOutputFormatPDF:  constants.LabelOutputFormatPDF,
+	constants.ValueOutputFormatXLSX: constants.LabelOutputFormatXLSX,
+}
+
+// layoutLabels maps values of the "layout" radio buttons to their labels.
+var layoutLabels = map[string]string{
+	constants.ValueLayoutMaster:          constants.LabelLayoutMaster,
+	constants.ValueLayoutMobilePortrait:  constants.LabelLayoutMobilePortrait,
+	constants.ValueLayoutMobileLandscape: constants.LabelLayoutMobileLandscape,
+}
+
+// ShowChooseBookmarkControls shows an optional bookmark selection for a report of reportID; it's hidden if the report has no bookmarks.
+func ShowChooseBookmarkControls(v *slack.View, reportID string, bs []*domain.Bookmark) *slack.ModalViewRequest {
+	r := CopyModalRequest(v)
+	bookmarkBlockID := FindBlock(r.Blocks.BlockSet, constants.BlockIDBookmark)
+	if bookmarkBlockID == "" {
+		return r
+	}
+
+	if len(bs) == 0 {
+		r.Blocks.BlockSet = RemoveBlock(r.Blocks.BlockSet, bookmarkBlockID)
+
+		return r
+	}
+
+	bookmarkPlaceholder := slackcomponents.GetSlackPlainTextBlock(constants.PlaceholderBookmark)
+	bookmarkSelect := buildBookmarksSelect(constants.ActionIDBookmark, bookmarkPlaceholder, bs)
+	bookmarkInput := slack.NewInputBlock(constants.BlockIDBookmark+reportID, bookmarkPlaceholder, bookmarkSelect)
+	bookmarkInput.Optional = true
+	r.Blocks.BlockSet, _ = replaceBlock(r.Blocks.BlockSet, bookmarkBlockID, bookmarkInput)
+
+	return r
+}
+
+func removeChooseBookmarkControls(bs []slack.Block) []slack.Block {
+	bookmarkBlockID := FindBlock(bs, constants.BlockIDBookmark)
+	if bookmarkBlockID == "" {
+		return bs
+	}
+
+	return RemoveBlock(bs, bookmarkBlockID)
+}
+
+// ShowChooseVisualControls shows a single visual selection for a page of pageID, or a notice why a visual can't be chosen.
+// Visuals are listed by a browser, so loading is set while they're being listed.
+func ShowChooseVisualControls(v *slack.View, pageID string, vs []string, loading bool) *slack.ModalViewRequest {
+	r := CopyModalRequest(v)
+
+	var visualBlock slack.Block
+	notice := ""
+	switch {
+	case pageID == "":
+		notice = constants.WarningVisualNeedsSinglePage
+
+	case loading:
+		notice = constants.LabelLoadingVisuals
+
+	case len(vs) == 0:
+		notice = constants.WarningNoVisuals
+
+	default:
+		visualPlaceholder := slackcomponents.GetSlackPlainTextBlock(constants.PlaceholderVisual)
+		visualSelect := buildVisualsSelect(vs, visualPlaceholder, constants.ActionIDVisual)
+		visualBlock = slack.NewInputBlock(constants.BlockIDVisual+pageID, visualPlaceholder, visualSelect)
+	}
+
+	if notice != "" {
+		noticeText := slackcomponents.GetSlackMarkdownTextBlock(notice)
+		visualBlock = slack.NewSectionBlock(noticeText, nil, nil, slack.SectionBlockOptionBlockID(constants.BlockIDVisual+pageID))
+	}
+
+	r.Blocks.BlockSet = removeChooseVisualControls(r.Blocks.BlockSet)
+	r.Blocks.BlockSet, _ = addBlockAfter(r.Blocks.BlockSet, FindBlock(r.Blocks.BlockSet, constants.BlockIDShareMode), visualBlock)
+
+	return r
+}
+
+// HideChooseVisualControls hides a single visual selection.
+func HideChooseVisualControls(v *slack.View) *slack.ModalViewRequest {
+	r := CopyModalRequest(v)
+	r.Blocks.BlockSet = removeChooseVisualControls(r.Blocks.BlockSet)
+
+	return r
+}
+
+func removeChooseVisualControls(bs []slack.Block) []slack.Block {
+	visualBlockID := FindBlock(bs, constants.BlockIDVisual)
+	if visualBlockID == "" {
+		return bs
+	}
+
+	return RemoveBlock(bs, visualBlockID)
+}
+
+// UpdateChooseReportControls updates report selection controls
 func UpdateChooseReportControls(v *slack.View, rs domain.GroupedReports, stateTag string) *slack.ModalViewRequest {
 	r := CopyModalRequest(v)
 	pagesBlock := FindBlock(r.Blocks.BlockSet, constants.BlockIDPages)
 	r.Blocks.BlockSet = RemoveBlock(r.Blocks.BlockSet, pagesBlock)
+	if shareModeBlock := FindBlock(r.Blocks.BlockSet, constants.BlockIDShareMode); shareModeBlock != "" {
+		r.Blocks.BlockSet = RemoveBlock(r.Blocks.BlockSet, shareModeBlock)
+	}
+	r.Blocks.BlockSet = removeChooseVisualControls(r.Blocks.BlockSet)
+	r.Blocks.BlockSet = removeChooseBookmarkControls(r.Blocks.BlockSet)
+	r.Blocks.BlockSet = removeAttachDataControls(r.Blocks.BlockSet)
+	r.Blocks.BlockSet = removeLayoutControls(r.Blocks.BlockSet)
+	r.Blocks.BlockSet = removeOutputFormatControls(r.Blocks.BlockSet)
 
 	notAllReportsPresentLabel := slackcomponents.GetSlackMarkdownTextBlock(constants.LabelNotAllReportsInList)
 	notAllReportsPresentSection := slack.NewSectionBlock(notAllReportsPresentLabel, nil, nil)
@@ -1228,7 +2053,13 @@ func buildWorkspacesOptions(ws []*domain.Group) []*slack.OptionBlockObject {
 func buildReportsOptions(rs *domain.ReportsContainer) []*slack.OptionBlockObject {
 	os := []*slack.OptionBlockObject(nil)
 	for _, r := range rs.Value {
-		t := slackcomponents.GetSlackPlainTextBlock(r.GetName())
+		// NOTE: Dashboards & tiles are listed along w/ reports, so they're labeled w/ a kind.
+		n := r.GetName()
+		if r.GetType() != domain.ReportTypeReport {
+			n = fmt.Sprintf("%v (%v)", n, r.GetType())
+		}
+
+		t := slackcomponents.GetSlackPlainTextBlock(n)
 		o := slack.NewOptionBlockObject(r.GetID(), t, nil)
 		os = append(os, o)
 	}
@@ -1285,6 +2116,22 @@ func buildPagesOptions(ps []*domain.Page) []*slack.OptionBlockObject {
 	return os
 }
 
+func buildBookmarksSelect(actionID string, placeholder *slack.TextBlockObject, bs []*domain.Bookmark) *slack.SelectBlockElement {
+	os := []*slack.OptionBlockObject(nil)
+	for _, b := range bs {
+		t := slackcomponents.GetSlackPlainTextBlock(b.DisplayName)
+		o := slack.NewOptionBlockObject(b.Name, t, nil)
+		os = append(os, o)
+	}
+
+	return slack.NewOptionsSelectBlockElement(
+		slack.OptTypeStatic,
+		placeholder,
+		actionID,
+		os...,
+	)
+}
+
 func (m *SelectReportModal) getReportsQuantity() int {
 	var sum int
 	for _, v := range m.GroupedReports {
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"encoding/json"
 	"errors"
 	"os"
 	"path/filepath"
@@ -17,22 +18,76 @@ import (
 type options struct {
 	AccessToken string `json:"accessToken"`
 	ReportID    string `json:"reportId"`
+	// PageName is the first page if unset.
+	PageName string `json:"pageName,omitempty"`
+	// VisualType is any type if unset.
+	VisualType string `json:"visualType,omitempty"`
+	// Bookmarks makes bookmarks listed instead of visuals.
+	Bookmarks bool `json:"bookmarks,omitempty"`
 }
 
 const (
-	visualsIndicator = "visuals_"
+	visualsIndicator   = "visuals_"
+	bookmarksIndicator = "bookmarks_"
+	cardVisualType     = "card"
 )
 
 // GetVisuals returns a list of available visual for report
 // TODO: Support multiple pages.
 func GetVisuals(accessToken, reportID string) ([]string, error) {
-	l := zap.L().With(zap.String("reportID", reportID))
+	return getVisuals(&options{
+		AccessToken: accessToken,
+		ReportID:    reportID,
+		VisualType:  cardVisualType,
+	})
+}
 
-	o := options{
+// GetPageVisuals returns titles of all visuals of a report page; visuals w/o a title are skipped.
+func GetPageVisuals(accessToken, reportID, pageName string) ([]string, error) {
+	return getVisuals(&options{
 		AccessToken: accessToken,
 		ReportID:    reportID,
+		PageName:    pageName,
+	})
+}
+
+func getVisuals(o *options) ([]string, error) {
+	data, err := readEmbeddedReport(o, visualsIndicator)
+	if err != nil {
+		return []string{}, err
+	}
+
+	if data == "" {
+		return []string{}, nil
+	}
+
+	return strings.Split(data, "\n"), nil
+}
+
+// GetBookmarks returns bookmarks of a report; bookmarks of a group are returned in place of the group.
+func GetBookmarks(accessToken, reportID string) ([]*domain.Bookmark, error) {
+	data, err := readEmbeddedReport(&options{
+		AccessToken: accessToken,
+		ReportID:    reportID,
+		Bookmarks:   true,
+	}, bookmarksIndicator)
+	if err != nil {
+		return nil, err
+	}
+
+	bs := []*domain.Bookmark(nil)
+	err = json.Unmarshal([]byte(data), &bs)
+	if err != nil {
+		return nil, err
 	}
 
+	return bs, nil
+}
+
+// readEmbeddedReport embeds a report w/ getVisualsTemplate & returns a message it logs to console prefixed w/ indicator.
+func readEmbeddedReport(o *options, indicator string) (string, error) {
+	l := zap.L().With(zap.String("reportID", o.ReportID), zap.String("pageName", o.PageName))
+
 	getVisualsTemplate := filepath.Join("resources", "getVisualsTemplate.html")
 	reportHTMLPath, err := utils.GetEmbeddedReport(o.ReportID, getVisualsTemplate, "{{options}}", o)
 
@@ -41,13 +96,13 @@ func GetVisuals(accessToken, reportID string) ([]string, error) {
 	if err != nil {
 		l.Error("couldn't build template", zap.Error(err))
 
-		return nil, err
+		return "", err
 	}
 
 	b, err := browser.GetBrowserInstance()
 	if err != nil {
 		l.Error("couldn't get browser instance", zap.Error(err))
-		return nil, err
+		return "", err
 	}
 
 	ctx, cancel := chromedp.NewContext(*b.GetContext())
@@ -65,38 +120,40 @@ func GetVisuals(accessToken, reportID string) ([]string, error) {
 	if err != nil {
 		l.Error("couldn't build absolute path for template", zap.Error(err), zap.String("reportPath", reportHTMLPath))
 
-		return nil, err
+		return "", err
 	}
 
 	var consoleData string
 	var consoleError error
-	visualsChan := make(chan []string)
-	defer close(visualsChan)
+	// NOTE: Channel is buffered, so a message logged after a timeout doesn't block the listener.
+	doneChan := make(chan struct{}, 1)
 
 	chromedp.ListenTarget(ctx, func(ev interface{}) {
 		switch ev := ev.(type) {
 		case *runtime.EventConsoleAPICalled: // watch for console.info()/console.error()
-			stringValue := string(ev.Args[0].Value)
-			if strings.Contains(stringValue, visualsIndicator) {
-				consoleData = strings.Replace(stringValue, visualsIndicator, "", 1)
-				consoleData = strings.Trim(consoleData, "\"")
-
-				switch ev.Type {
-				case runtime.APITypeInfo:
-					if consoleData == "" {
-						visualsChan <- []string{}
-					} else {
-						visualArray := strings.Split(consoleData, "\\n")
-						visualsChan <- visualArray
-					}
-
-				case runtime.APITypeError:
-					consoleError = errors.New("Get data of visual failed. " + consoleData)
-					visualsChan <- []string{}
+			// NOTE: Messages are logged as strings, so escaped characters (e.g. line breaks) are decoded.
+			stringValue := ""
+			if len(ev.Args) == 0 || json.Unmarshal(ev.Args[0].Value, &stringValue) != nil {
+				return
+			}
+
+			if strings.HasPrefix(stringValue, indicator) {
+				consoleData = strings.TrimPrefix(stringValue, indicator)
+
+				if ev.Type == runtime.APITypeError {
+					consoleError = errors.New("Get data of report failed. " + consoleData)
+				}
+
+				select {
+				case doneChan <- struct{}{}:
+				default:
 				}
 			} else if strings.Contains(stringValue, reportErrorIndicator) {
 				consoleError = domain.ErrReportNotLoaded
-				visualsChan <- []string{}
+				select {
+				case doneChan <- struct{}{}:
+				default:
+				}
 			}
 		}
 	})
@@ -104,31 +161,28 @@ func GetVisuals(accessToken, reportID string) ([]string, error) {
 	openHTMLTask := chromedp.Tasks{chromedp.Navigate(filepath.Join("file:///", htmlReportAbsPath))}
 	if err := chromedp.Run(ctx, openHTMLTask); err != nil {
 		l.Error("couldn't open html file", zap.Error(err))
-		return []string{}, err
+		return "", err
 	}
 
-	l.Info("Console data: ", zap.String("Console data", consoleData))
-
-	var visuals []string
 	t := time.NewTimer(listenConsoleTimeout)
-checkCondition:
-	for {
-		select {
-		case visuals = <-visualsChan:
-			break checkCondition
+	defer t.Stop()
 
-		case <-t.C:
-			l.Error("condition checking timed out")
+	select {
+	case <-doneChan:
 
-			return []string{}, errors.New("timeout exception")
-		}
+	case <-t.C:
+		l.Error("condition checking timed out")
+
+		return "", errors.New("timeout exception")
 	}
 
 	if consoleError != nil {
 		l.Error("Console error", zap.String("Error", consoleError.Error()))
 
-		return []string{}, consoleError
+		return "", consoleError
 	}
 
-	return visuals, nil
+	l.Info("Console data: ", zap.String("Console data", consoleData))
+
+	return consoleData, nil
 }
@@ -10,6 +10,8 @@ import (
 type browser struct {
 	browserContext   context.Context
 	closeBrowserFunc context.CancelFunc
+	// getContext is set if a Chrome instance is owned by someone else, who may replace it.
+	getContext func() context.Context
 }
 
 // Browser provides a interface to browser functions
@@ -20,6 +22,12 @@ type Browser interface {
 var brInstance *browser
 
 func (b *browser) GetContext() *context.Context {
+	if b.getContext != nil {
+		ctx := b.getContext()
+
+		return &ctx
+	}
+
 	return &b.browserContext
 }
 
@@ -35,6 +43,14 @@ func GetBrowserInstance() (Browser, error) {
 	return brInstance, err
 }
 
+// UseContext makes browser instance reuse a Chrome instance owned by someone else; getContext is called each time, so a replaced instance is picked up.
+func UseContext(getContext func() context.Context) {
+	brInstance = &browser{
+		closeBrowserFunc: func() {},
+		getContext:       getContext,
+	}
+}
+
 // Dispose releases browser instance
 func Dispose() {
 	if brInstance != nil && brInstance.browserContext != nil {
@@ -0,0 +1,78 @@
+package messagequeue
+
+import (
+	"context"
+	"fmt"
+)
+
+// BatchError will be returned by MessageQueue.PushBatch if some of Envelope weren't pushed.
+type BatchError struct {
+	// Errs are ordered as pushed Envelope; nil means Envelope was pushed.
+	Errs []error
+}
+
+func (e *BatchError) Error() string {
+	n := 0
+	firstErr := error(nil)
+	for _, err := range e.Errs {
+		if err == nil {
+			continue
+		}
+
+		n++
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return fmt.Sprintf("couldn't push %v of %v messages: %v", n, len(e.Errs), firstErr)
+}
+
+// newBatchError returns BatchError if any of errs is set, nil otherwise.
+func newBatchError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return &BatchError{
+				Errs: errs,
+			}
+		}
+	}
+
+	return nil
+}
+
+// pushEach is a PushBatch fallback for MessageQueue w/o a batch API.
+func pushEach(ctx context.Context, q MessageQueue, es []*Envelope, w WaitOption) error {
+	errs := make([]error, len(es))
+	for i, e := range es {
+		errs[i] = q.Push(ctx, e, w)
+	}
+
+	return newBatchError(errs)
+}
+
+// pushSubset pushes es[i] for each of is to q at once; failures are recorded to errs at original indexes.
+func pushSubset(ctx context.Context, q MessageQueue, es []*Envelope, is []int, w WaitOption, errs []error) {
+	if len(is) == 0 {
+		return
+	}
+
+	ses := make([]*Envelope, 0, len(is))
+	for _, i := range is {
+		ses = append(ses, es[i])
+	}
+
+	err := q.PushBatch(ctx, ses, w)
+	if err == nil {
+		return
+	}
+
+	be, ok := err.(*BatchError)
+	for j, i := range is {
+		if ok {
+			errs[i] = be.Errs[j]
+		} else {
+			errs[i] = err
+		}
+	}
+}
@@ -3,18 +3,37 @@ package messagequeue
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"time"
 )
 
 // MessageKind allows to discern messages from each other.
 type MessageKind string
 
+// Priority allows to receive urgent messages before others.
+type Priority string
+
+const (
+	// PriorityInteractive is for messages someone is waiting for right now.
+	PriorityInteractive Priority = "interactive"
+	// PriorityScheduled is for messages produced by background jobs.
+	PriorityScheduled Priority = "scheduled"
+)
+
 // Envelope holds a message & its metadata.
 type Envelope struct {
-	ID      string      `json:"id,omitempty"`
-	Kind    MessageKind `json:"kind,omitempty"`
-	Body    interface{} `json:"body"`
-	TraceID string      `json:"traceID,omitempty"`
-	Handle  string      `json:"-"`
+	ID   string      `json:"id,omitempty"`
+	Kind MessageKind `json:"kind,omitempty"`
+	// Version tells which Schema of Kind Envelope.Body follows.
+	Version  int         `json:"version,omitempty"`
+	Body     interface{} `json:"body"`
+	TraceID  string      `json:"traceID,omitempty"`
+	Priority Priority    `json:"priority,omitempty"`
+	Handle   string      `json:"-"`
+	// Delay postpones delivery of a pushed Envelope; it isn't kept after Push.
+	Delay time.Duration `json:"-"`
+	// ReceiveCount tells how many times Envelope has been received, including current attempt.
+	ReceiveCount int `json:"-"`
 }
 
 // UnmarshalJSON extends json.UnmarshalJSON behavior to work w/ Envelope.Body.
@@ -34,6 +53,24 @@ func (e *Envelope) UnmarshalJSON(bs []byte) error {
 	return nil
 }
 
+// GetPriority returns Envelope.Priority, treating unset priority as PriorityInteractive.
+func (e *Envelope) GetPriority() Priority {
+	if e.Priority == PriorityScheduled {
+		return PriorityScheduled
+	}
+
+	return PriorityInteractive
+}
+
+// GroupID tells which Envelope are received in order: the ones of the same Kind & TraceID, so a slow or failing Envelope holds back only the rest of its trace rather than its whole Kind.
+func (e *Envelope) GroupID() string {
+	if e.TraceID == "" {
+		return string(e.Kind)
+	}
+
+	return fmt.Sprintf("%v:%v", e.Kind, e.TraceID)
+}
+
 // Unpack allows extracting Envelope.Body to arbitrary type determined by caller.
 func (e *Envelope) Unpack(v func(e *Envelope, j json.RawMessage) (interface{}, error)) (interface{}, error) {
 	return v(e, *e.Body.(*json.RawMessage))
@@ -52,6 +89,45 @@ const (
 // MessageQueue represents a message queue.
 type MessageQueue interface {
 	Push(ctx context.Context, m *Envelope, w WaitOption) error
+	// PushBatch pushes es at once where the underlying queue allows it; if some of es weren't pushed, BatchError is returned.
+	PushBatch(ctx context.Context, es []*Envelope, w WaitOption) error
 	Peek(ctx context.Context, w WaitOption) (*Envelope, error)
 	Delete(ctx context.Context, h string) error
+	// ChangeVisibility hides a received Envelope from other consumers for d starting from now.
+	ChangeVisibility(ctx context.Context, h string, d time.Duration) error
+	// Release returns Envelope received from the underlying queue, but not returned by Peek yet, so other consumers can receive it.
+	Release(ctx context.Context) error
+}
+
+// Counter is implemented by MessageQueue able to tell how many Envelope it keeps.
+type Counter interface {
+	// Count returns an approximate number of Envelope, including in-flight & delayed ones.
+	Count(ctx context.Context) (int, error)
+}
+
+// NonRetryableError wraps an error of handling Envelope which redelivery wouldn't fix, so Envelope is moved to dead letters at once.
+type NonRetryableError struct {
+	Err error
+}
+
+func (e *NonRetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NonRetryableError) Unwrap() error {
+	return e.Err
+}
+
+// DelayedError wraps an error of handling Envelope which can't be fixed before Delay, so Envelope is received again after it; such a receive doesn't count towards max receive count.
+type DelayedError struct {
+	Err   error
+	Delay time.Duration
+}
+
+func (e *DelayedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DelayedError) Unwrap() error {
+	return e.Err
 }
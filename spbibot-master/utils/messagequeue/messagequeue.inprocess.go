@@ -4,20 +4,42 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/segmentio/ksuid"
 )
 
 const bufferCapacity = 16
 
+// NOTE: A long poll is over after a while, as w/ other implementations, so a caller gets a chance to check other queues.
+const pollingInterval = 20 * time.Second
+
+type inProcessMessage struct {
+	body         json.RawMessage
+	receiveCount int
+}
+
+type inFlightMessage struct {
+	*inProcessMessage
+	visibleAt time.Time
+	timer     *time.Timer
+}
+
 type inProcessMessageQueue struct {
-	messages chan json.RawMessage
+	messages          chan *inProcessMessage
+	inFlight          map[string]*inFlightMessage
+	inFlightLocker    sync.Locker
+	visibilityTimeout time.Duration
 }
 
 // NewInProcessMessageQueue creates a MessageQueue for local testing.
-func NewInProcessMessageQueue() MessageQueue {
+func NewInProcessMessageQueue(visibilityTimeout time.Duration) MessageQueue {
 	return &inProcessMessageQueue{
-		messages: make(chan json.RawMessage, bufferCapacity),
+		messages:          make(chan *inProcessMessage, bufferCapacity),
+		inFlight:          map[string]*inFlightMessage{},
+		inFlightLocker:    &sync.Mutex{},
+		visibilityTimeout: visibilityTimeout,
 	}
 }
 
@@ -29,9 +51,20 @@ func (q *inProcessMessageQueue) Push(ctx context.Context, e *Envelope, w WaitOpt
 		return err
 	}
 
+	m := &inProcessMessage{
+		body: j,
+	}
+	if e.Delay > 0 {
+		time.AfterFunc(e.Delay, func() {
+			q.messages <- m
+		})
+
+		return nil
+	}
+
 	if w == NoWait {
 		select {
-		case q.messages <- j:
+		case q.messages <- m:
 			return nil
 
 		case <-ctx.Done():
@@ -42,7 +75,7 @@ func (q *inProcessMessageQueue) Push(ctx context.Context, e *Envelope, w WaitOpt
 		}
 	} else {
 		select {
-		case q.messages <- j:
+		case q.messages <- m:
 			return nil
 
 		case <-ctx.Done():
@@ -51,11 +84,15 @@ func (q *inProcessMessageQueue) Push(ctx context.Context, e *Envelope, w WaitOpt
 	}
 }
 
+func (q *inProcessMessageQueue) PushBatch(ctx context.Context, es []*Envelope, w WaitOption) error {
+	return pushEach(ctx, q, es, w)
+}
+
 func (q *inProcessMessageQueue) Peek(ctx context.Context, w WaitOption) (*Envelope, error) {
-	j := json.RawMessage{}
+	m := (*inProcessMessage)(nil)
 	if w == NoWait {
 		select {
-		case j = <-q.messages:
+		case m = <-q.messages:
 			break
 
 		case <-ctx.Done():
@@ -66,23 +103,91 @@ func (q *inProcessMessageQueue) Peek(ctx context.Context, w WaitOption) (*Envelo
 		}
 	} else {
 		select {
-		case j = <-q.messages:
+		case m = <-q.messages:
 			break
 
 		case <-ctx.Done():
 			return nil, ctx.Err()
+
+		case <-time.After(pollingInterval):
+			return nil, ErrNoMessages
 		}
 	}
 
+	m.receiveCount++
+
 	e := Envelope{}
-	err := json.Unmarshal(j, &e)
+	err := json.Unmarshal(m.body, &e)
 	if err != nil {
 		return nil, err
 	}
 
+	h := ksuid.New().String()
+	e.Handle = h
+	e.ReceiveCount = m.receiveCount
+
+	q.inFlightLocker.Lock()
+	defer q.inFlightLocker.Unlock()
+
+	q.inFlight[h] = &inFlightMessage{
+		inProcessMessage: m,
+		visibleAt:        time.Now().Add(q.visibilityTimeout),
+		timer: time.AfterFunc(q.visibilityTimeout, func() {
+			q.release(h)
+		}),
+	}
+
 	return &e, nil
 }
 
-func (q *inProcessMessageQueue) Delete(_ context.Context, _ string) error {
+func (q *inProcessMessageQueue) Delete(_ context.Context, h string) error {
+	q.inFlightLocker.Lock()
+	defer q.inFlightLocker.Unlock()
+
+	m, ok := q.inFlight[h]
+	if !ok {
+		return ErrInvalidHandle
+	}
+
+	m.timer.Stop()
+	delete(q.inFlight, h)
+
+	return nil
+}
+
+func (q *inProcessMessageQueue) ChangeVisibility(_ context.Context, h string, d time.Duration) error {
+	q.inFlightLocker.Lock()
+	defer q.inFlightLocker.Unlock()
+
+	m, ok := q.inFlight[h]
+	if !ok {
+		return ErrInvalidHandle
+	}
+
+	m.visibleAt = time.Now().Add(d)
+	m.timer.Reset(d)
+
 	return nil
 }
+
+// NOTE: Nothing to release, as messages are taken from the channel one by one.
+func (q *inProcessMessageQueue) Release(_ context.Context) error {
+	return nil
+}
+
+// NOTE: An expired message is returned back to the queue, so it can be received again.
+func (q *inProcessMessageQueue) release(h string) {
+	q.inFlightLocker.Lock()
+	defer q.inFlightLocker.Unlock()
+
+	m, ok := q.inFlight[h]
+	if !ok || time.Now().Before(m.visibleAt) {
+		return
+	}
+
+	delete(q.inFlight, h)
+
+	go func() {
+		q.messages <- m.inProcessMessage
+	}()
+}
@@ -0,0 +1,385 @@
+package messagequeue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"go.uber.org/zap"
+
+)
+
+// NOTE: Polling a table is cheap, but not free; so we don't hammer the DB while waiting for incoming messages.
+const mysqlPollingStep = time.Second
+
+type mysqlMessageQueue struct {
+	buffer       []*Envelope
+	bufferLocker sync.Locker
+	db           *sql.DB
+	config       *config.MessageQueueConfig
+	logger       *zap.Logger
+}
+
+// NewMySQLMessageQueue creates a MySQL-backed MessageQueue.
+func NewMySQLMessageQueue(db *sql.DB, c *config.MessageQueueConfig, l *zap.Logger) MessageQueue {
+	return &mysqlMessageQueue{
+		buffer:       []*Envelope(nil),
+		bufferLocker: &sync.Mutex{},
+		db:           db,
+		config:       c,
+		logger:       l,
+	}
+}
+
+func (q *mysqlMessageQueue) Push(ctx context.Context, e *Envelope, _ WaitOption) error {
+	e.ID = ksuid.New().String()
+
+	j, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	return q.insert(ctx, e, j)
+}
+
+// NOTE: Envelope are inserted by a single statement; if it fails, they're inserted one by one, so a single bad row doesn't fail the others.
+func (q *mysqlMessageQueue) PushBatch(ctx context.Context, es []*Envelope, _ WaitOption) error {
+	if len(es) == 0 {
+		return nil
+	}
+
+	errs := make([]error, len(es))
+	js := make([][]byte, len(es))
+	is := []int(nil)
+	values := []string(nil)
+	args := []interface{}(nil)
+	for i, e := range es {
+		e.ID = ksuid.New().String()
+
+		j, err := json.Marshal(e)
+		if err != nil {
+			errs[i] = err
+
+			continue
+		}
+
+		js[i] = j
+		is = append(is, i)
+		values = append(values, "(?, ?, ?, ?, ?, TIMESTAMPADD(MICROSECOND, ?, UTC_TIMESTAMP(6)), UTC_TIMESTAMP(6))")
+		args = append(args, e.ID, q.config.URL, string(e.Kind), j, e.TraceID, e.Delay.Microseconds())
+	}
+
+	if len(is) == 0 {
+		return newBatchError(errs)
+	}
+
+	query := `INSERT INTO queueMessages (id, queue, kind, body, traceID, visibleAt, createdAt) VALUES ` + strings.Join(values, ", ")
+	_, err := q.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		q.logger.Warn("couldn't push messages at once, pushing them one by one", zap.Int("count", len(is)), zap.Error(err))
+
+		for _, i := range is {
+			errs[i] = q.insert(ctx, es[i], js[i])
+		}
+	}
+
+	return newBatchError(errs)
+}
+
+// insert stores a single marshaled Envelope.
+func (q *mysqlMessageQueue) insert(ctx context.Context, e *Envelope, j []byte) error {
+	query := `INSERT INTO queueMessages SET id=?, queue=?, kind=?, body=?, traceID=?, visibleAt=TIMESTAMPADD(MICROSECOND, ?, UTC_TIMESTAMP(6)), createdAt=UTC_TIMESTAMP(6)`
+	_, err := q.db.ExecContext(ctx, query, e.ID, q.config.URL, string(e.Kind), j, e.TraceID, e.Delay.Microseconds())
+
+	return err
+}
+
+// NOTE: The buffer is locked only while it's accessed, so Release doesn't wait for polling to end.
+func (q *mysqlMessageQueue) Peek(ctx context.Context, w WaitOption) (*Envelope, error) {
+	e := q.getBuffered()
+	if e != nil {
+		return e, nil
+	}
+
+	pollingCtx, cancelPolling := context.WithTimeout(ctx, q.config.PollingInterval)
+	defer cancelPolling()
+
+	for {
+		es, err := q.receive(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(es) > 0 {
+			q.fillBuffer(es)
+
+			return q.getBuffered(), nil
+		}
+
+		if w == NoWait {
+			return nil, ErrNoMessages
+		}
+
+		select {
+		case <-time.After(mysqlPollingStep):
+			break
+
+		case <-pollingCtx.Done():
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			return nil, ErrNoMessages
+		}
+	}
+}
+
+func (q *mysqlMessageQueue) Delete(ctx context.Context, h string) error {
+	query := `DELETE FROM queueMessages WHERE queue=? AND receiptHandle=?`
+
+	return q.executeByHandle(ctx, query, q.config.URL, h)
+}
+
+func (q *mysqlMessageQueue) ChangeVisibility(ctx context.Context, h string, d time.Duration) error {
+	query := `UPDATE queueMessages SET visibleAt=TIMESTAMPADD(MICROSECOND, ?, UTC_TIMESTAMP(6)) WHERE queue=? AND receiptHandle=?`
+
+	return q.executeByHandle(ctx, query, d.Microseconds(), q.config.URL, h)
+}
+
+// NOTE: Buffered Envelope haven't been returned by Peek, so they're put back as if they weren't received: their receives don't count & they don't hold back the ones behind them.
+func (q *mysqlMessageQueue) Release(ctx context.Context) error {
+	query := `UPDATE queueMessages SET receiptHandle=NULL, receiveCount=receiveCount-1, visibleAt=UTC_TIMESTAMP(6) WHERE queue=? AND receiptHandle=?`
+	firstErr := error(nil)
+	for _, e := range q.takeBuffered() {
+		err := q.executeByHandle(ctx, query, q.config.URL, e.Handle)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+func (q *mysqlMessageQueue) Count(ctx context.Context) (int, error) {
+	query := `SELECT COUNT(*) FROM queueMessages WHERE queue=?`
+	n := 0
+	err := q.db.QueryRowContext(ctx, query, q.config.URL).Scan(&n)
+
+	return n, err
+}
+
+func (q *mysqlMessageQueue) executeByHandle(ctx context.Context, query string, args ...interface{}) error {
+	res, err := q.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return ErrInvalidHandle
+	}
+
+	return nil
+}
+
+// messageGroup identifies Envelope received in order, see Envelope.GroupID.
+type messageGroup struct {
+	kind    string
+	traceID string
+}
+
+// NOTE: Messages are claimed in insertion order per group (see Envelope.GroupID), & a group w/ an earlier message in flight is skipped, so envelopes of the same group are received first-in-first-out (as w/ SQS FIFO queues) while a stuck group doesn't hold back the rest of them.
+// Messages of a group are locked from the first one w/o skipping locked rows, so a concurrent receive waits for them to be claimed instead of claiming the ones behind them.
+func (q *mysqlMessageQueue) receive(ctx context.Context) (es []*Envelope, err error) {
+	n := int(q.config.BatchSize)
+	groups, err := q.visibleGroups(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(groups) == 0 {
+		return nil, nil
+	}
+
+	// NOTE: Groups are locked in the same order by every consumer, so concurrent receives don't deadlock.
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].kind != groups[j].kind {
+			return groups[i].kind < groups[j].kind
+		}
+
+		return groups[i].traceID < groups[j].traceID
+	})
+
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err == nil {
+			err = tx.Commit()
+
+			return
+		}
+
+		err2 := tx.Rollback()
+		if err2 != nil {
+			q.logger.Error("couldn't rollback transaction", zap.Error(err2))
+		}
+	}()
+
+	// NOTE: A batch is shared among groups, so a group w/ a backlog doesn't take all of it.
+	perGroup := n / len(groups)
+	if perGroup < 1 {
+		perGroup = 1
+	}
+
+	// NOTE: Messages in flight are selected along w/ visible ones, so the ones behind them are left for later; new delayed messages aren't in flight yet, so they don't hold back the rest.
+	query := `SELECT sequence, body, receiveCount, receiptHandle IS NOT NULL AND visibleAt > UTC_TIMESTAMP(6)
+			  FROM queueMessages
+			  WHERE queue=? AND kind=? AND traceID=? AND (visibleAt <= UTC_TIMESTAMP(6) OR receiptHandle IS NOT NULL)
+			  ORDER BY sequence
+			  LIMIT ?
+			  FOR UPDATE`
+	sequences := []int64(nil)
+	bodies := [][]byte(nil)
+	receiveCounts := []int(nil)
+	for _, g := range groups {
+		limit := n - len(sequences)
+		if limit <= 0 {
+			break
+		}
+
+		if limit > perGroup {
+			limit = perGroup
+		}
+
+		rows, err := tx.QueryContext(ctx, query, q.config.URL, g.kind, g.traceID, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			sequence := int64(0)
+			body := []byte(nil)
+			receiveCount := 0
+			inFlight := false
+			err = rows.Scan(&sequence, &body, &receiveCount, &inFlight)
+			if err != nil {
+				_ = rows.Close()
+
+				return nil, err
+			}
+
+			if inFlight {
+				break
+			}
+
+			sequences = append(sequences, sequence)
+			bodies = append(bodies, body)
+			receiveCounts = append(receiveCounts, receiveCount)
+		}
+
+		err = rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	query = `UPDATE queueMessages
+			 SET receiptHandle=?, receiveCount=receiveCount+1, visibleAt=TIMESTAMPADD(MICROSECOND, ?, UTC_TIMESTAMP(6))
+			 WHERE sequence=?`
+	for i, sequence := range sequences {
+		h := ksuid.New().String()
+		_, err = tx.ExecContext(ctx, query, h, q.config.VisibilityTimeout.Microseconds(), sequence)
+		if err != nil {
+			return nil, err
+		}
+
+		// NOTE: A malformed message stays claimed & reappears after visibility timeout, same as w/ SQS.
+		e := Envelope{}
+		err = json.Unmarshal(bodies[i], &e)
+		if err != nil {
+			q.logger.Error("couldn't unmarshal message", zap.Error(err), zap.Int64("sequence", sequence))
+			err = nil
+
+			continue
+		}
+
+		e.Handle = h
+		e.ReceiveCount = receiveCounts[i] + 1
+		es = append(es, &e)
+	}
+
+	return es, nil
+}
+
+// visibleGroups lists up to limit groups of messages ready to be received, the ones waiting longest first.
+func (q *mysqlMessageQueue) visibleGroups(ctx context.Context, limit int) ([]*messageGroup, error) {
+	query := `SELECT kind, traceID
+			  FROM queueMessages
+			  WHERE queue=? AND visibleAt <= UTC_TIMESTAMP(6)
+			  GROUP BY kind, traceID
+			  ORDER BY MIN(sequence)
+			  LIMIT ?`
+	rows, err := q.db.QueryContext(ctx, query, q.config.URL, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	groups := []*messageGroup(nil)
+	for rows.Next() {
+		g := messageGroup{}
+		err := rows.Scan(&g.kind, &g.traceID)
+		if err != nil {
+			return nil, err
+		}
+
+		groups = append(groups, &g)
+	}
+
+	return groups, rows.Err()
+}
+
+func (q *mysqlMessageQueue) fillBuffer(es []*Envelope) {
+	q.bufferLocker.Lock()
+	defer q.bufferLocker.Unlock()
+
+	q.buffer = append(q.buffer, es...)
+}
+
+// getBuffered returns nil if there are no buffered Envelope.
+func (q *mysqlMessageQueue) getBuffered() *Envelope {
+	q.bufferLocker.Lock()
+	defer q.bufferLocker.Unlock()
+
+	if len(q.buffer) == 0 {
+		return nil
+	}
+
+	e := q.buffer[0]
+	q.buffer = q.buffer[1:]
+
+	return e
+}
+
+func (q *mysqlMessageQueue) takeBuffered() []*Envelope {
+	q.bufferLocker.Lock()
+	defer q.bufferLocker.Unlock()
+
+	es := q.buffer
+	q.buffer = nil
+
+	return es
+}
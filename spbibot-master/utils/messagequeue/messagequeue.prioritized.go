@@ -0,0 +1,153 @@
+package messagequeue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+type prioritizedMessageQueue struct {
+	lanes            map[Priority]MessageQueue
+	interactiveRatio uint
+	peeks            uint
+	peeksLocker      sync.Locker
+}
+
+// NewPrioritizedMessageQueue combines separate lanes for interactive & scheduled Envelope into a single MessageQueue.
+// Peek prefers interactive lane, but every (interactiveRatio+1)-th call scheduled lane goes first, so scheduled work keeps progressing.
+func NewPrioritizedMessageQueue(interactive, scheduled MessageQueue, interactiveRatio uint) MessageQueue {
+	return &prioritizedMessageQueue{
+		lanes: map[Priority]MessageQueue{
+			PriorityInteractive: interactive,
+			PriorityScheduled:   scheduled,
+		},
+		interactiveRatio: interactiveRatio,
+		peeksLocker:      &sync.Mutex{},
+	}
+}
+
+func (q *prioritizedMessageQueue) Push(ctx context.Context, e *Envelope, w WaitOption) error {
+	return q.lanes[e.GetPriority()].Push(ctx, e, w)
+}
+
+func (q *prioritizedMessageQueue) PushBatch(ctx context.Context, es []*Envelope, w WaitOption) error {
+	errs := make([]error, len(es))
+	lanes := map[Priority][]int{}
+	for i, e := range es {
+		p := e.GetPriority()
+		lanes[p] = append(lanes[p], i)
+	}
+
+	for p, is := range lanes {
+		pushSubset(ctx, q.lanes[p], es, is, w, errs)
+	}
+
+	return newBatchError(errs)
+}
+
+func (q *prioritizedMessageQueue) Peek(ctx context.Context, w WaitOption) (*Envelope, error) {
+	firstErr := error(nil)
+	for _, p := range q.nextOrder() {
+		e, err := q.lanes[p].Peek(ctx, NoWait)
+		if err == ErrNoMessages {
+			continue
+		}
+
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+
+			continue
+		}
+
+		e.Handle = packLaneHandle(p, e.Handle)
+
+		return e, nil
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	if w == NoWait {
+		return nil, ErrNoMessages
+	}
+
+	// NOTE: Both lanes are empty, so we long-poll interactive one, as users wait for its Envelope; scheduled lane is checked again on the next call, once the poll is over.
+	e, err := q.lanes[PriorityInteractive].Peek(ctx, Wait)
+	if err != nil {
+		return nil, err
+	}
+
+	e.Handle = packLaneHandle(PriorityInteractive, e.Handle)
+
+	return e, nil
+}
+
+func (q *prioritizedMessageQueue) Delete(ctx context.Context, h string) error {
+	p, h, err := unpackLaneHandle(h)
+	if err != nil {
+		return err
+	}
+
+	return q.lanes[p].Delete(ctx, h)
+}
+
+func (q *prioritizedMessageQueue) ChangeVisibility(ctx context.Context, h string, d time.Duration) error {
+	p, h, err := unpackLaneHandle(h)
+	if err != nil {
+		return err
+	}
+
+	return q.lanes[p].ChangeVisibility(ctx, h, d)
+}
+
+func (q *prioritizedMessageQueue) Release(ctx context.Context) error {
+	firstErr := error(nil)
+	for _, l := range q.lanes {
+		err := l.Release(ctx)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+func (q *prioritizedMessageQueue) nextOrder() []Priority {
+	q.peeksLocker.Lock()
+	defer q.peeksLocker.Unlock()
+
+	q.peeks++
+	if q.peeks > q.interactiveRatio {
+		q.peeks = 0
+
+		return []Priority{PriorityScheduled, PriorityInteractive}
+	}
+
+	return []Priority{PriorityInteractive, PriorityScheduled}
+}
+
+// NOTE: Receipt handles are only meaningful to the lane they came from, so we keep the lane within a handle.
+func packLaneHandle(p Priority, h string) string {
+	return fmt.Sprintf("%v:%v", p, h)
+}
+
+func unpackLaneHandle(h string) (Priority, string, error) {
+	parts := strings.SplitN(h, ":", 2)
+	if len(parts) != 2 {
+		return "", "", ErrInvalidHandle
+	}
+
+	p := Priority(parts[0])
+	switch p {
+	case PriorityInteractive, PriorityScheduled:
+		return p, parts[1], nil
+
+	default:
+		return "", "", ErrInvalidHandle
+	}
+}
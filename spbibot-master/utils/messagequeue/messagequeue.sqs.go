@@ -3,20 +3,32 @@ package messagequeue
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strconv"
 	"sync"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"go.uber.org/zap"
 
+)
 
+// sqsBatchSize is the max number of entries SendMessageBatch accepts.
+const sqsBatchSize = 10
 
-)
+// sqsMaxDelay is the max delay a message of a standard queue can be sent w/.
+const sqsMaxDelay = 15 * time.Minute
 
 type attributeName string
 
 const (
 	attributeMessageKind attributeName = "messageKind"
 	attributeTraceID     attributeName = "traceID"
+	attributeVisibleAt   attributeName = "visibleAt"
+	attributeQueueURL    attributeName = "queueURL"
+
+	attributeApproximateReceiveCount attributeName = sqs.MessageSystemAttributeNameApproximateReceiveCount
 )
 
 // NOTE: This is a workaround for excessive usage of pointers to basic types in AWS SDK.
@@ -32,6 +44,13 @@ const (
 	typeString attributeType = "String"
 )
 
+// NOTE: This is a workaround for excessive usage of pointers to basic types in AWS SDK.
+func getType(t attributeType) *string {
+	s := string(t)
+
+	return &s
+}
+
 type sqsMessageQueue struct {
 	buffer       []*sqs.Message
 	bufferLocker sync.Locker
@@ -57,8 +76,13 @@ func (q *sqsMessageQueue) Push(ctx context.Context, e *Envelope, _ WaitOption) e
 		return err
 	}
 
-	s = s.SetQueueUrl(q.config.URL)
-	_, err = q.sqs.SendMessageWithContext(ctx, s)
+	if e.Delay > 0 {
+		err = q.delay(ctx, s, q.config.URL, time.Now().UTC().Add(e.Delay))
+	} else {
+		s = s.SetQueueUrl(q.config.URL)
+		_, err = q.sqs.SendMessageWithContext(ctx, s)
+	}
+
 	if err != nil {
 		return err
 	}
@@ -68,22 +92,41 @@ func (q *sqsMessageQueue) Push(ctx context.Context, e *Envelope, _ WaitOption) e
 	return nil
 }
 
+func (q *sqsMessageQueue) PushBatch(ctx context.Context, es []*Envelope, _ WaitOption) error {
+	errs := make([]error, len(es))
+	for start := 0; start < len(es); start += sqsBatchSize {
+		end := start + sqsBatchSize
+		if end > len(es) {
+			end = len(es)
+		}
+
+		q.sendBatch(ctx, es, start, end, errs)
+	}
+
+	return newBatchError(errs)
+}
+
 func (q *sqsMessageQueue) Peek(ctx context.Context, w WaitOption) (*Envelope, error) {
 	q.bufferLocker.Lock()
 	defer q.bufferLocker.Unlock()
 
-	if len(q.buffer) > 0 {
-		return q.getBuffered()
+	e, err := q.getBuffered()
+	if err != ErrNoMessages {
+		return e, err
 	}
 
 	i := &sqs.ReceiveMessageInput{}
 	i = i.
+		SetAttributeNames([]*string{
+			getAttribute(attributeApproximateReceiveCount),
+		}).
 		SetMessageAttributeNames([]*string{
 			getAttribute(attributeMessageKind),
 			getAttribute(attributeTraceID),
 		}).
 		SetQueueUrl(q.config.URL).
-		SetMaxNumberOfMessages(int64(q.config.BatchSize))
+		SetMaxNumberOfMessages(int64(q.config.BatchSize)).
+		SetVisibilityTimeout(int64(q.config.VisibilityTimeout.Seconds()))
 	if w == Wait {
 		i = i.SetWaitTimeSeconds(int64(q.config.PollingInterval.Seconds()))
 	}
@@ -112,6 +155,65 @@ func (q *sqsMessageQueue) Delete(ctx context.Context, h string) error {
 	return err
 }
 
+func (q *sqsMessageQueue) ChangeVisibility(ctx context.Context, h string, d time.Duration) error {
+	c := &sqs.ChangeMessageVisibilityInput{}
+	c = c.
+		SetQueueUrl(q.config.URL).
+		SetReceiptHandle(h).
+		SetVisibilityTimeout(int64(d.Seconds()))
+	_, err := q.sqs.ChangeMessageVisibilityWithContext(ctx, c)
+
+	return err
+}
+
+func (q *sqsMessageQueue) Release(ctx context.Context) error {
+	q.bufferLocker.Lock()
+	defer q.bufferLocker.Unlock()
+
+	firstErr := error(nil)
+	for _, m := range q.buffer {
+		err := q.ChangeVisibility(ctx, *m.ReceiptHandle, 0)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	q.buffer = nil
+
+	return firstErr
+}
+
+func (q *sqsMessageQueue) Count(ctx context.Context) (int, error) {
+	i := &sqs.GetQueueAttributesInput{}
+	i = i.
+		SetQueueUrl(q.config.URL).
+		SetAttributeNames([]*string{
+			getAttribute(sqs.QueueAttributeNameApproximateNumberOfMessages),
+			getAttribute(sqs.QueueAttributeNameApproximateNumberOfMessagesNotVisible),
+			getAttribute(sqs.QueueAttributeNameApproximateNumberOfMessagesDelayed),
+		})
+	o, err := q.sqs.GetQueueAttributesWithContext(ctx, i)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, v := range o.Attributes {
+		if v == nil {
+			continue
+		}
+
+		c, err := strconv.Atoi(*v)
+		if err != nil {
+			return 0, err
+		}
+
+		n += c
+	}
+
+	return n, nil
+}
+
 func packEnvelope(e *Envelope) (*sqs.SendMessageInput, error) {
 	j, err := json.Marshal(e)
 	if err != nil {
@@ -120,7 +222,7 @@ func packEnvelope(e *Envelope) (*sqs.SendMessageInput, error) {
 
 	s := &sqs.SendMessageInput{}
 	s = s.
-		SetMessageGroupId(string(e.Kind)).
+		SetMessageGroupId(e.GroupID()).
 		SetMessageBody(string(j)).
 		SetMessageAttributes(map[string]*sqs.MessageAttributeValue{
 			string(attributeMessageKind): (&sqs.MessageAttributeValue{}).
@@ -134,6 +236,226 @@ func packEnvelope(e *Envelope) (*sqs.SendMessageInput, error) {
 	return s, nil
 }
 
+// delay sends a message to the delay queue; it's forwarded to a queue at queueURL by ForwardDelayed once visibleAt comes.
+// NOTE: FIFO queues don't support per-message delays, while hiding a received message would count as its receive & block its message group meanwhile. So delayed messages wait in a standard queue & reach a FIFO one as new messages.
+func (q *sqsMessageQueue) delay(ctx context.Context, s *sqs.SendMessageInput, queueURL string, visibleAt time.Time) error {
+	if q.config.DelayURL == "" {
+		return fmt.Errorf("delay queue must be set to delay messages")
+	}
+
+	s.MessageAttributes[string(attributeVisibleAt)] = (&sqs.MessageAttributeValue{}).
+		SetDataType(string(typeString)).
+		SetStringValue(visibleAt.Format(time.RFC3339Nano))
+	s.MessageAttributes[string(attributeQueueURL)] = (&sqs.MessageAttributeValue{}).
+		SetDataType(string(typeString)).
+		SetStringValue(queueURL)
+
+	d := &sqs.SendMessageInput{}
+	d = d.
+		SetQueueUrl(q.config.DelayURL).
+		SetMessageBody(*s.MessageBody).
+		SetMessageAttributes(s.MessageAttributes).
+		SetDelaySeconds(delaySeconds(visibleAt))
+	_, err := q.sqs.SendMessageWithContext(ctx, d)
+
+	return err
+}
+
+// ForwardDelayed moves due messages of the delay queue of c to queues they were pushed to till ctx is done; it's run by a single routine per process pushing delayed Envelope.
+func ForwardDelayed(ctx context.Context, s *sqs.SQS, c *config.MessageQueueConfig, l *zap.Logger) {
+	q := &sqsMessageQueue{
+		sqs:    s,
+		config: c,
+		logger: l,
+	}
+	for ctx.Err() == nil {
+		err := q.forwardDelayed(ctx)
+		if err == nil || ctx.Err() != nil {
+			continue
+		}
+
+		l.Error("couldn't forward delayed messages", zap.Error(err))
+
+		// NOTE: A failing queue isn't polled in a tight loop.
+		select {
+		case <-time.After(q.config.PollingInterval):
+			break
+
+		case <-ctx.Done():
+			break
+		}
+	}
+}
+
+// forwardDelayed long-polls the delay queue & moves due messages to queues they were pushed to; the rest of them are sent back to it for the rest of their delays.
+func (q *sqsMessageQueue) forwardDelayed(ctx context.Context) error {
+	i := &sqs.ReceiveMessageInput{}
+	i = i.
+		SetMessageAttributeNames([]*string{
+			getAttribute(attributeMessageKind),
+			getAttribute(attributeTraceID),
+			getAttribute(attributeVisibleAt),
+			getAttribute(attributeQueueURL),
+		}).
+		SetQueueUrl(q.config.DelayURL).
+		SetMaxNumberOfMessages(sqsBatchSize).
+		SetVisibilityTimeout(int64(q.config.VisibilityTimeout.Seconds())).
+		SetWaitTimeSeconds(int64(q.config.PollingInterval.Seconds()))
+	o, err := q.sqs.ReceiveMessageWithContext(ctx, i)
+	if err != nil {
+		return err
+	}
+
+	firstErr := error(nil)
+	for _, m := range o.Messages {
+		err := q.forward(ctx, m)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// NOTE: A message is deleted from the delay queue after it's sent, so it may be forwarded twice if deletion fails; deduplication of a FIFO queue drops the second copy.
+func (q *sqsMessageQueue) forward(ctx context.Context, m *sqs.Message) error {
+	attributes := map[string]*sqs.MessageAttributeValue{}
+	for k, v := range m.MessageAttributes {
+		attributes[k] = v
+	}
+
+	visibleAt := time.Time{}
+	v := attributes[string(attributeVisibleAt)]
+	if v != nil && v.StringValue != nil {
+		t, err := time.Parse(time.RFC3339Nano, *v.StringValue)
+		if err == nil {
+			visibleAt = t
+		}
+	}
+
+	s := &sqs.SendMessageInput{}
+	s = s.SetMessageBody(aws.StringValue(m.Body))
+	if time.Until(visibleAt) > 0 {
+		s = s.
+			SetQueueUrl(q.config.DelayURL).
+			SetMessageAttributes(attributes).
+			SetDelaySeconds(delaySeconds(visibleAt))
+	} else {
+		queueURL := q.config.URL
+		u := attributes[string(attributeQueueURL)]
+		if u != nil && u.StringValue != nil {
+			queueURL = *u.StringValue
+		}
+
+		e := Envelope{}
+		k := attributes[string(attributeMessageKind)]
+		if k != nil && k.StringValue != nil {
+			e.Kind = MessageKind(*k.StringValue)
+		}
+
+		t := attributes[string(attributeTraceID)]
+		if t != nil && t.StringValue != nil {
+			e.TraceID = *t.StringValue
+		}
+
+		delete(attributes, string(attributeVisibleAt))
+		delete(attributes, string(attributeQueueURL))
+		s = s.
+			SetQueueUrl(queueURL).
+			SetMessageAttributes(attributes).
+			SetMessageGroupId(e.GroupID())
+	}
+
+	_, err := q.sqs.SendMessageWithContext(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	d := &sqs.DeleteMessageInput{}
+	d = d.
+		SetQueueUrl(q.config.DelayURL).
+		SetReceiptHandle(aws.StringValue(m.ReceiptHandle))
+	_, err = q.sqs.DeleteMessageWithContext(ctx, d)
+
+	return err
+}
+
+// delaySeconds is a delay till visibleAt limited by sqsMaxDelay; it's rounded up not to forward a message too early.
+func delaySeconds(visibleAt time.Time) int64 {
+	d := time.Until(visibleAt)
+	if d > sqsMaxDelay {
+		d = sqsMaxDelay
+	}
+
+	if d <= 0 {
+		return 0
+	}
+
+	return int64((d + time.Second - 1) / time.Second)
+}
+
+// NOTE: Entry IDs are indexes of Envelope, so failed entries are mapped back to them.
+func (q *sqsMessageQueue) sendBatch(ctx context.Context, es []*Envelope, start, end int, errs []error) {
+	entries := []*sqs.SendMessageBatchRequestEntry(nil)
+	for i := start; i < end; i++ {
+		s, err := packEnvelope(es[i])
+		if err != nil {
+			errs[i] = err
+
+			continue
+		}
+
+		if es[i].Delay > 0 {
+			errs[i] = q.delay(ctx, s, q.config.URL, time.Now().UTC().Add(es[i].Delay))
+			if errs[i] == nil {
+				analytics.DefaultAmplitudeClient().Send(analytics.EventQueuedReportGenerationEvent, "", "", nil)
+			}
+
+			continue
+		}
+
+		entry := &sqs.SendMessageBatchRequestEntry{}
+		entry = entry.
+			SetId(strconv.Itoa(i)).
+			SetMessageBody(*s.MessageBody).
+			SetMessageAttributes(s.MessageAttributes).
+			SetMessageGroupId(*s.MessageGroupId)
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		return
+	}
+
+	b := &sqs.SendMessageBatchInput{}
+	b = b.
+		SetQueueUrl(q.config.URL).
+		SetEntries(entries)
+	o, err := q.sqs.SendMessageBatchWithContext(ctx, b)
+	if err != nil {
+		for i := start; i < end; i++ {
+			if errs[i] == nil {
+				errs[i] = err
+			}
+		}
+
+		return
+	}
+
+	for _, f := range o.Failed {
+		i, err := strconv.Atoi(aws.StringValue(f.Id))
+		if err != nil || i < start || i >= end {
+			continue
+		}
+
+		errs[i] = fmt.Errorf("couldn't send message: %v %v", aws.StringValue(f.Code), aws.StringValue(f.Message))
+	}
+
+	for range o.Successful {
+		analytics.DefaultAmplitudeClient().Send(analytics.EventQueuedReportGenerationEvent, "", "", nil)
+	}
+}
+
 func unpackMessage(m *sqs.Message) (*Envelope, error) {
 	e := Envelope{}
 	err := json.Unmarshal([]byte(*m.Body), &e)
@@ -159,6 +481,14 @@ func unpackMessage(m *sqs.Message) (*Envelope, error) {
 		e.TraceID = *t.StringValue
 	}
 
+	c := m.Attributes[string(attributeApproximateReceiveCount)]
+	if c != nil {
+		n, err := strconv.Atoi(*c)
+		if err == nil {
+			e.ReceiveCount = n
+		}
+	}
+
 	return &e, nil
 }
 
@@ -167,12 +497,12 @@ func (q *sqsMessageQueue) fillBuffer(ms []*sqs.Message) {
 }
 
 func (q *sqsMessageQueue) getBuffered() (*Envelope, error) {
+	if len(q.buffer) == 0 {
+		return nil, ErrNoMessages
+	}
+
 	m := q.buffer[0]
 	q.buffer = q.buffer[1:]
-	e, err := unpackMessage(m)
-	if err != nil {
-		return nil, err
-	}
 
-	return e, nil
+	return unpackMessage(m)
 }
@@ -0,0 +1,89 @@
+package messagequeue
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// InvalidEnvelopeError will be returned by MessageQueue.Peek for a received Envelope which doesn't match its Schema.
+// Envelope stays in-flight, so caller decides whether to drop it or let it reappear.
+type InvalidEnvelopeError struct {
+	Envelope *Envelope
+	Err      error
+}
+
+func (e *InvalidEnvelopeError) Error() string {
+	return fmt.Sprintf("invalid envelope %v: %v", e.Envelope.ID, e.Err)
+}
+
+func (e *InvalidEnvelopeError) Unwrap() error {
+	return e.Err
+}
+
+type validatingMessageQueue struct {
+	mq       MessageQueue
+	registry *Registry
+}
+
+// NewValidatingMessageQueue checks each Envelope against r on Push & Peek.
+func NewValidatingMessageQueue(mq MessageQueue, r *Registry) MessageQueue {
+	return &validatingMessageQueue{
+		mq:       mq,
+		registry: r,
+	}
+}
+
+func (q *validatingMessageQueue) Push(ctx context.Context, e *Envelope, w WaitOption) error {
+	err := q.registry.Validate(e)
+	if err != nil {
+		return err
+	}
+
+	return q.mq.Push(ctx, e, w)
+}
+
+// NOTE: Only valid Envelope are pushed, the rest are reported w/ BatchError.
+func (q *validatingMessageQueue) PushBatch(ctx context.Context, es []*Envelope, w WaitOption) error {
+	errs := make([]error, len(es))
+	is := []int(nil)
+	for i, e := range es {
+		errs[i] = q.registry.Validate(e)
+		if errs[i] == nil {
+			is = append(is, i)
+		}
+	}
+
+	pushSubset(ctx, q.mq, es, is, w, errs)
+
+	return newBatchError(errs)
+}
+
+func (q *validatingMessageQueue) Peek(ctx context.Context, w WaitOption) (*Envelope, error) {
+	e, err := q.mq.Peek(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.registry.Decode(e)
+	if err != nil {
+		return nil, &InvalidEnvelopeError{
+			Envelope: e,
+			Err:      err,
+		}
+	}
+
+	return e, nil
+}
+
+func (q *validatingMessageQueue) Delete(ctx context.Context, h string) error {
+	return q.mq.Delete(ctx, h)
+}
+
+func (q *validatingMessageQueue) ChangeVisibility(ctx context.Context, h string, d time.Duration) error {
+	return q.mq.ChangeVisibility(ctx, h, d)
+}
+
+func (q *validatingMessageQueue) Release(ctx context.Context) error {
+	return q.mq.Release(ctx)
+}
@@ -1,15 +1,85 @@
 package messagequeue
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 const (
 	// MessagePostReport is for PostReportMessage.
 	MessagePostReport MessageKind = "postReport"
+	// MessageDeadLetter is for DeadLetterMessage.
+	MessageDeadLetter MessageKind = "deadLetter"
+	// MessageDistributeReport is for DistributeReportMessage.
+	MessageDistributeReport MessageKind = "distributeReport"
+)
+
+// OutputFormat is a file format a report is rendered into.
+type OutputFormat string
+
+const (
+	// OutputFormatPNG renders each page into a separate image.
+	OutputFormatPNG OutputFormat = "png"
+	// OutputFormatPDF renders all pages into a single document w/ a cover page.
+	OutputFormatPDF OutputFormat = "pdf"
+	// OutputFormatXLSX exports a paginated report into a single workbook; other reports are rendered as OutputFormatPNG then.
+	OutputFormatXLSX OutputFormat = "xlsx"
+)
+
+// IsDocument tells if all pages are rendered into a single file of f, so they're posted by a single message.
+func (f OutputFormat) IsDocument() bool {
+	return f == OutputFormatPDF || f == OutputFormatXLSX
+}
+
+// DataFormat is a file format summarized data of visuals is exported into along w/ a report.
+type DataFormat string
+
+const (
+	// DataFormatCSV exports data of each visual into a separate CSV file.
+	DataFormatCSV DataFormat = "csv"
+	// DataFormatZIP exports data of each visual into a CSV file of a single ZIP archive.
+	DataFormatZIP DataFormat = "zip"
+)
+
+// Layout is a layout of report pages; it maps to a layout type of the Power BI JS API.
+type Layout string
+
+const (
+	// LayoutMaster renders pages as designed for desktop.
+	LayoutMaster Layout = "master"
+	// LayoutMobilePortrait renders mobile layouts of pages for phones held upright.
+	LayoutMobilePortrait Layout = "mobilePortrait"
+	// LayoutMobileLandscape renders mobile layouts of pages for phones held sideways.
+	LayoutMobileLandscape Layout = "mobileLandscape"
+)
+
+// FilterKind is a kind of TypedFilterMessage; each of them maps to a filter schema of powerbi-models.
+type FilterKind string
+
+const (
+	// FilterKindBasic keeps rows w/ a value of a column in (or not in) Values.
+	FilterKindBasic FilterKind = "basic"
+	// FilterKindAdvanced keeps rows matching one or two Conditions.
+	FilterKindAdvanced FilterKind = "advanced"
+	// FilterKindRelativeDate keeps rows w/ a date in the last, this or next TimeUnitsCount of TimeUnit relative to today.
+	FilterKindRelativeDate FilterKind = "relativeDate"
+	// FilterKindRelativeTime is FilterKindRelativeDate for minutes & hours relative to now.
+	FilterKindRelativeTime FilterKind = "relativeTime"
+	// FilterKindTopN keeps ItemCount top (or bottom) items of a column ordered by OrderBy.
+	FilterKindTopN FilterKind = "topN"
+	// FilterKindTuple keeps rows w/ values of Targets matching one of Tuples.
+	FilterKindTuple FilterKind = "tuple"
 )
 
 // ErrNoMessages will be returned by MessageQueue.Peek for an empty MessageQueue.
 var ErrNoMessages = fmt.Errorf("no messages to read")
 
+// ErrInvalidHandle will be returned by MessageQueue.Delete for a receipt handle which is unknown or expired.
+var ErrInvalidHandle = fmt.Errorf("receipt handle is invalid or expired")
+
+// ErrPlaintextSecrets will be returned by RenderReportMessage.OpenTokens for unsealed tokens which aren't accepted anymore.
+var ErrPlaintextSecrets = fmt.Errorf("message secrets must be sealed")
+
 // PageMessage keeps page info.
 type PageMessage struct {
 	ID   string `json:"id"`
@@ -27,6 +97,115 @@ type FilterMessage struct {
 	SecondConditionOperator string `json:"secondConditionOperator,omitempty"`
 }
 
+// FilterTargetMessage keeps a column (or a measure) of a table a filter is applied to.
+type FilterTargetMessage struct {
+	Table   string `json:"table"`
+	Column  string `json:"column,omitempty"`
+	Measure string `json:"measure,omitempty"`
+}
+
+// FilterConditionMessage keeps a condition of an advanced filter.
+type FilterConditionMessage struct {
+	Operator string `json:"operator"`
+	Value    string `json:"value,omitempty"`
+}
+
+// TypedFilterMessage keeps info of a filter of Kind; fields which don't belong to Kind are ignored.
+type TypedFilterMessage struct {
+	Kind   FilterKind           `json:"kind"`
+	Target *FilterTargetMessage `json:"target,omitempty"`
+	// Operator is "In" or "NotIn" for FilterKindBasic, "InLast", "InThis" or "InNext" for relative ones & "Top" or "Bottom" for FilterKindTopN.
+	Operator        string                    `json:"operator,omitempty"`
+	Values          []string                  `json:"values,omitempty"`
+	LogicalOperator string                    `json:"logicalOperator,omitempty"`
+	Conditions      []*FilterConditionMessage `json:"conditions,omitempty"`
+	TimeUnitsCount  int                       `json:"timeUnitsCount,omitempty"`
+	// TimeUnit is one of relativeDateTimeUnits for FilterKindRelativeDate & of relativeTimeTimeUnits for FilterKindRelativeTime.
+	TimeUnit     string               `json:"timeUnit,omitempty"`
+	IncludeToday bool                 `json:"includeToday,omitempty"`
+	ItemCount    int                  `json:"itemCount,omitempty"`
+	OrderBy      *FilterTargetMessage `json:"orderBy,omitempty"`
+	// Targets are columns of FilterKindTuple; each of Tuples holds a value for each of them.
+	Targets []*FilterTargetMessage `json:"targets,omitempty"`
+	Tuples  [][]string             `json:"tuples,omitempty"`
+}
+
+var (
+	relativeDateTimeUnits = []string{"Days", "Weeks", "CalendarWeeks", "Months", "CalendarMonths", "Years", "CalendarYears"}
+	relativeTimeTimeUnits = []string{"Minutes", "Hours"}
+)
+
+// Validate checks fields required by Kind are set.
+func (f *TypedFilterMessage) Validate() error {
+	if f.Kind != FilterKindTuple && (f.Target == nil || f.Target.Table == "" || f.Target.Column == "") {
+		return fmt.Errorf("table & column of a %v filter must be set", f.Kind)
+	}
+
+	switch f.Kind {
+	case FilterKindBasic:
+		if !oneOf(f.Operator, "In", "NotIn") || len(f.Values) == 0 {
+			return fmt.Errorf("operator & values of a basic filter must be set")
+		}
+
+	case FilterKindAdvanced:
+		if len(f.Conditions) == 0 || len(f.Conditions) > 2 {
+			return fmt.Errorf("one or two conditions of an advanced filter must be set")
+		}
+
+		if len(f.Conditions) == 2 && !oneOf(f.LogicalOperator, "And", "Or") {
+			return fmt.Errorf("logical operator of an advanced filter must be set")
+		}
+
+	case FilterKindRelativeDate, FilterKindRelativeTime:
+		units := relativeDateTimeUnits
+		if f.Kind == FilterKindRelativeTime {
+			units = relativeTimeTimeUnits
+		}
+
+		if !oneOf(f.Operator, "InLast", "InThis", "InNext") || f.TimeUnitsCount <= 0 || !oneOf(f.TimeUnit, units...) {
+			return fmt.Errorf("operator, time units count & time unit of a %v filter must be set", f.Kind)
+		}
+
+	case FilterKindTopN:
+		if !oneOf(f.Operator, "Top", "Bottom") || f.ItemCount <= 0 || f.OrderBy == nil || f.OrderBy.Table == "" || (f.OrderBy.Column == "") == (f.OrderBy.Measure == "") {
+			return fmt.Errorf("operator, item count & either column or measure to order by of a top N filter must be set")
+		}
+
+	case FilterKindTuple:
+		if len(f.Targets) == 0 || len(f.Tuples) == 0 {
+			return fmt.Errorf("targets & tuples of a tuple filter must be set")
+		}
+
+		for _, t := range f.Targets {
+			if t.Table == "" || t.Column == "" {
+				return fmt.Errorf("table & column of each target of a tuple filter must be set")
+			}
+		}
+
+		for _, t := range f.Tuples {
+			if len(t) != len(f.Targets) {
+				return fmt.Errorf("each tuple must hold a value for each target")
+			}
+		}
+
+	default:
+		return fmt.Errorf("unknown filter kind: %v", f.Kind)
+	}
+
+	return nil
+}
+
+func oneOf(s string, ss ...string) bool {
+	for _, o := range ss {
+		if s == o {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Tokens keeps secrets needed to render & post a report; they're carried in RenderReportMessage sealed only.
 type Tokens struct {
 	BotAccessToken string
 	PowerBIToken   string
@@ -43,7 +222,137 @@ type RenderReportMessage struct {
 	ChannelID   string         `json:"channelID"`
 	WorkspaceID string         `json:"workspaceID"`
 	UniqueID    string         `json:"uniqueID"`
-	Token       Tokens         `json:"tokens"`
+	// NOTE: Token is set by legacy producers only (up to version 1), use SealTokens instead.
+	Token        *Tokens       `json:"tokens,omitempty"`
+	SealedTokens *SealedSecret `json:"sealedTokens,omitempty"`
+	RetryAttempt int           `json:"retryAttempt"`
+	// OutputFormat is OutputFormatPNG if unset.
+	OutputFormat OutputFormat `json:"outputFormat,omitempty"`
+	// VisualName is a title of a single visual to render instead of a whole page; Pages must hold exactly one page then.
+	VisualName string `json:"visualName,omitempty"`
+	// BypassCache makes pages rendered anew even if images rendered for the same data are cached.
+	BypassCache bool `json:"bypassCache,omitempty"`
+	// DataFormat makes data of visuals rendered exported along w/ pages; no data is exported if unset.
+	DataFormat DataFormat `json:"dataFormat,omitempty"`
+	// BookmarkName is a name (rather than a display name) of a report bookmark applied before each page is rendered.
+	BookmarkName string `json:"bookmarkName,omitempty"`
+	// Filters are applied together; Filter is applied along w/ them if it's set by a legacy producer.
+	Filters []*TypedFilterMessage `json:"filters,omitempty"`
+	// ReportParameters are values of parameters of a paginated report; a parameter w/o a value gets its default one.
+	ReportParameters []*ReportParameterMessage `json:"reportParameters,omitempty"`
+	// Layout is LayoutMaster if unset; a page w/o a mobile layout is rendered in LayoutMaster anyway.
+	Layout Layout `json:"layout,omitempty"`
+}
+
+// ReportParameterMessage is a value of a paginated report parameter; a multi-value parameter is set by several of them w/ the same name.
+type ReportParameterMessage struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// SealTokens encrypts t w/ k into SealedTokens; UniqueID must be set beforehand, as it's bound to the secret.
+func (m *RenderReportMessage) SealTokens(k *KeyRing, t *Tokens) error {
+	s, err := k.Seal(t, []byte(m.UniqueID))
+	if err != nil {
+		return err
+	}
+
+	m.Token = nil
+	m.SealedTokens = s
+
+	return nil
+}
+
+// OpenTokens decrypts SealedTokens w/ k. Plaintext Token is returned only if acceptPlaintext is set, otherwise ErrPlaintextSecrets is returned.
+func (m *RenderReportMessage) OpenTokens(k *KeyRing, acceptPlaintext bool) (*Tokens, error) {
+	if m.SealedTokens != nil {
+		t := Tokens{}
+		err := k.Open(m.SealedTokens, []byte(m.UniqueID), &t)
+		if err != nil {
+			return nil, err
+		}
+
+		return &t, nil
+	}
+
+	if m.Token == nil || *m.Token == (Tokens{}) {
+		return &Tokens{}, nil
+	}
+
+	if !acceptPlaintext {
+		return nil, ErrPlaintextSecrets
+	}
+
+	return m.Token, nil
+}
+
+// validateFilters checks each of Filters is valid.
+func (m *RenderReportMessage) validateFilters() error {
+	for _, f := range m.Filters {
+		if f == nil {
+			return fmt.Errorf("filter must be set")
+		}
+
+		err := f.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateReportParameters checks each of ReportParameters is named.
+func (m *RenderReportMessage) validateReportParameters() error {
+	for _, p := range m.ReportParameters {
+		if p == nil || p.Name == "" {
+			return fmt.Errorf("name of each report parameter must be set")
+		}
+	}
+
+	return nil
+}
+
+// validateVisual checks a single visual is chosen on a single page.
+func (m *RenderReportMessage) validateVisual() error {
+	if m.VisualName != "" && len(m.Pages) != 1 {
+		return fmt.Errorf("exactly one page must be set for a visual")
+	}
+
+	return nil
+}
+
+// validateOutputFormat checks f is known.
+func validateOutputFormat(f OutputFormat) error {
+	switch f {
+	case "", OutputFormatPNG, OutputFormatPDF, OutputFormatXLSX:
+		return nil
+
+	default:
+		return fmt.Errorf("unknown output format %v", f)
+	}
+}
+
+// validateDataFormat checks f is known.
+func validateDataFormat(f DataFormat) error {
+	switch f {
+	case "", DataFormatCSV, DataFormatZIP:
+		return nil
+
+	default:
+		return fmt.Errorf("unknown data format %v", f)
+	}
+}
+
+// validateLayout checks l is known.
+func validateLayout(l Layout) error {
+	switch l {
+	case "", LayoutMaster, LayoutMobilePortrait, LayoutMobileLandscape:
+		return nil
+
+	default:
+		return fmt.Errorf("unknown layout %v", l)
+	}
 }
 
 // PostReportMessage is a command to perform report rendering & posting.
@@ -52,3 +361,360 @@ type PostReportMessage struct {
 	IsScheduled bool `json:"isScheduled,omitempty"`
 	SkipPosting bool `json:"skipPosting,omitempty"`
 }
+
+// DeliveryTargetMessage is a recipient of a rendered report.
+type DeliveryTargetMessage struct {
+	ClientID    string `json:"clientID"`
+	UserID      string `json:"userID"`
+	ChannelID   string `json:"channelID"`
+	WorkspaceID string `json:"workspaceID"`
+	// UniqueID identifies delivery to this target, so it's deduplicated separately from the others.
+	UniqueID string `json:"uniqueID"`
+}
+
+// DistributeReportMessage is a command to render a report once & post it to each of Targets.
+// NOTE: Report is rendered on behalf of RenderReportMessage.UserID, its ChannelID is ignored.
+type DistributeReportMessage struct {
+	*RenderReportMessage
+	Targets     []*DeliveryTargetMessage `json:"targets"`
+	IsScheduled bool                     `json:"isScheduled,omitempty"`
+}
+
+// DeadLetterMessage keeps an Envelope which couldn't be handled along w/ the reason.
+type DeadLetterMessage struct {
+	Envelope     *Envelope `json:"envelope"`
+	Error        string    `json:"error"`
+	ReceiveCount int       `json:"receiveCount"`
+	FailedAt     time.Time `json:"failedAt"`
+}
+
+// Validate checks required fields are set.
+func (m *PostReportMessage) Validate() error {
+	if m.RenderReportMessage == nil {
+		return fmt.Errorf("report must be set")
+	}
+
+	if m.ClientID == "" || m.ReportID == "" || m.ChannelID == "" {
+		return fmt.Errorf("client, report & channel must be set")
+	}
+
+	if len(m.Pages) == 0 {
+		return fmt.Errorf("at least one page must be set")
+	}
+
+	if m.Token != nil && m.SealedTokens != nil {
+		return fmt.Errorf("either plaintext or sealed tokens must be set")
+	}
+
+	err := m.validateVisual()
+	if err != nil {
+		return err
+	}
+
+	err = m.validateFilters()
+	if err != nil {
+		return err
+	}
+
+	err = m.validateReportParameters()
+	if err != nil {
+		return err
+	}
+
+	err = validateOutputFormat(m.OutputFormat)
+	if err != nil {
+		return err
+	}
+
+	err = validateDataFormat(m.DataFormat)
+	if err != nil {
+		return err
+	}
+
+	return validateLayout(m.Layout)
+}
+
+// Validate checks required fields are set.
+func (m *DistributeReportMessage) Validate() error {
+	if m.RenderReportMessage == nil {
+		return fmt.Errorf("report must be set")
+	}
+
+	if m.ClientID == "" || m.ReportID == "" || m.UniqueID == "" {
+		return fmt.Errorf("client, report & unique ID must be set")
+	}
+
+	if len(m.Pages) == 0 {
+		return fmt.Errorf("at least one page must be set")
+	}
+
+	if m.Token != nil && m.SealedTokens != nil {
+		return fmt.Errorf("either plaintext or sealed tokens must be set")
+	}
+
+	err := m.validateVisual()
+	if err != nil {
+		return err
+	}
+
+	err = m.validateFilters()
+	if err != nil {
+		return err
+	}
+
+	err = m.validateReportParameters()
+	if err != nil {
+		return err
+	}
+
+	err = validateOutputFormat(m.OutputFormat)
+	if err != nil {
+		return err
+	}
+
+	err = validateDataFormat(m.DataFormat)
+	if err != nil {
+		return err
+	}
+
+	err = validateLayout(m.Layout)
+	if err != nil {
+		return err
+	}
+
+	if len(m.Targets) == 0 {
+		return fmt.Errorf("at least one target must be set")
+	}
+
+	for _, t := range m.Targets {
+		if t.ClientID == "" || t.ChannelID == "" || t.UniqueID == "" {
+			return fmt.Errorf("client, channel & unique ID of each target must be set")
+		}
+	}
+
+	return nil
+}
+
+// Validate checks required fields are set.
+func (m *DeadLetterMessage) Validate() error {
+	if m.Envelope == nil {
+		return fmt.Errorf("envelope must be set")
+	}
+
+	return nil
+}
+
+// DefaultRegistry keeps schemas of all known messages.
+var DefaultRegistry = mustNewRegistry(
+	&Schema{
+		Kind:    MessagePostReport,
+		Version: 1,
+		New: func() interface{} {
+			return &PostReportMessage{}
+		},
+		Fields: []string{
+			"clientID",
+			"reportID",
+			"reportName",
+			"filter",
+			"pages",
+			"userID",
+			"channelID",
+			"workspaceID",
+			"uniqueID",
+			"tokens",
+			"retryAttempt",
+			"isScheduled",
+			"skipPosting",
+		},
+	},
+	// NOTE: Version 2 adds sealed tokens.
+	&Schema{
+		Kind:    MessagePostReport,
+		Version: 2,
+		New: func() interface{} {
+			return &PostReportMessage{}
+		},
+		Fields: []string{"sealedTokens"},
+	},
+	// NOTE: Version 3 adds output format.
+	&Schema{
+		Kind:    MessagePostReport,
+		Version: 3,
+		New: func() interface{} {
+			return &PostReportMessage{}
+		},
+		Fields: []string{"outputFormat"},
+	},
+	// NOTE: Version 4 adds a single visual.
+	&Schema{
+		Kind:    MessagePostReport,
+		Version: 4,
+		New: func() interface{} {
+			return &PostReportMessage{}
+		},
+		Fields: []string{"visualName"},
+	},
+	// NOTE: Version 5 adds cache bypass.
+	&Schema{
+		Kind:    MessagePostReport,
+		Version: 5,
+		New: func() interface{} {
+			return &PostReportMessage{}
+		},
+		Fields: []string{"bypassCache"},
+	},
+	// NOTE: Version 6 adds data export.
+	&Schema{
+		Kind:    MessagePostReport,
+		Version: 6,
+		New: func() interface{} {
+			return &PostReportMessage{}
+		},
+		Fields: []string{"dataFormat"},
+	},
+	// NOTE: Version 7 adds a bookmark.
+	&Schema{
+		Kind:    MessagePostReport,
+		Version: 7,
+		New: func() interface{} {
+			return &PostReportMessage{}
+		},
+		Fields: []string{"bookmarkName"},
+	},
+	// NOTE: Version 8 adds typed filters.
+	&Schema{
+		Kind:    MessagePostReport,
+		Version: 8,
+		New: func() interface{} {
+			return &PostReportMessage{}
+		},
+		Fields: []string{"filters"},
+	},
+	// NOTE: Version 9 adds paginated report parameters & XLSX output format.
+	&Schema{
+		Kind:    MessagePostReport,
+		Version: 9,
+		New: func() interface{} {
+			return &PostReportMessage{}
+		},
+		Fields: []string{"reportParameters"},
+	},
+	// NOTE: Version 10 adds mobile layouts.
+	&Schema{
+		Kind:    MessagePostReport,
+		Version: 10,
+		New: func() interface{} {
+			return &PostReportMessage{}
+		},
+		Fields: []string{"layout"},
+	},
+	&Schema{
+		Kind:    MessageDistributeReport,
+		Version: 1,
+		New: func() interface{} {
+			return &DistributeReportMessage{}
+		},
+		Fields: []string{
+			"clientID",
+			"reportID",
+			"reportName",
+			"filter",
+			"pages",
+			"userID",
+			"channelID",
+			"workspaceID",
+			"uniqueID",
+			"tokens",
+			"retryAttempt",
+			"sealedTokens",
+			"targets",
+			"isScheduled",
+		},
+	},
+	// NOTE: Version 2 adds output format.
+	&Schema{
+		Kind:    MessageDistributeReport,
+		Version: 2,
+		New: func() interface{} {
+			return &DistributeReportMessage{}
+		},
+		Fields: []string{"outputFormat"},
+	},
+	// NOTE: Version 3 adds a single visual.
+	&Schema{
+		Kind:    MessageDistributeReport,
+		Version: 3,
+		New: func() interface{} {
+			return &DistributeReportMessage{}
+		},
+		Fields: []string{"visualName"},
+	},
+	// NOTE: Version 4 adds cache bypass.
+	&Schema{
+		Kind:    MessageDistributeReport,
+		Version: 4,
+		New: func() interface{} {
+			return &DistributeReportMessage{}
+		},
+		Fields: []string{"bypassCache"},
+	},
+	// NOTE: Version 5 adds data export.
+	&Schema{
+		Kind:    MessageDistributeReport,
+		Version: 5,
+		New: func() interface{} {
+			return &DistributeReportMessage{}
+		},
+		Fields: []string{"dataFormat"},
+	},
+	// NOTE: Version 6 adds a bookmark.
+	&Schema{
+		Kind:    MessageDistributeReport,
+		Version: 6,
+		New: func() interface{} {
+			return &DistributeReportMessage{}
+		},
+		Fields: []string{"bookmarkName"},
+	},
+	// NOTE: Version 7 adds typed filters.
+	&Schema{
+		Kind:    MessageDistributeReport,
+		Version: 7,
+		New: func() interface{} {
+			return &DistributeReportMessage{}
+		},
+		Fields: []string{"filters"},
+	},
+	// NOTE: Version 8 adds paginated report parameters & XLSX output format.
+	&Schema{
+		Kind:    MessageDistributeReport,
+		Version: 8,
+		New: func() interface{} {
+			return &DistributeReportMessage{}
+		},
+		Fields: []string{"reportParameters"},
+	},
+	// NOTE: Version 9 adds mobile layouts.
+	&Schema{
+		Kind:    MessageDistributeReport,
+		Version: 9,
+		New: func() interface{} {
+			return &DistributeReportMessage{}
+		},
+		Fields: []string{"layout"},
+	},
+	&Schema{
+		Kind:    MessageDeadLetter,
+		Version: 1,
+		New: func() interface{} {
+			return &DeadLetterMessage{}
+		},
+		Fields: []string{
+			"envelope",
+			"error",
+			"receiveCount",
+			"failedAt",
+		},
+	},
+)
@@ -43,11 +43,18 @@ type ShareOptions struct {
 	ReportID    string         `json:"reportId"`
 	ReportName  string         `json:"reportName"`
 	Filter      *FilterOptions `json:"filter"`
-	Pages       []*PageOptions
-	ChannelID   string
-	UserID      string
-	IsScheduled bool
-	SkipPosting bool
+	// Filters are applied together; a report shared via Slack gets them instead of Filter.
+	Filters      []*domain.TypedFilter `json:"filters,omitempty"`
+	Pages        []*PageOptions
+	ChannelID    string
+	UserID       string
+	IsScheduled  bool
+	SkipPosting  bool
+	VisualName   string
+	DataFormat   string
+	BookmarkName string
+	Layout       string
+	OutputFormat string
 }
 
 // PageOptions holds page parameters.
@@ -74,34 +81,31 @@ func NewShareOptions(s *modals.ShareReportInput) *ShareOptions {
 		ChannelID:  s.ReportSelection.ChannelID,
 		Pages:      ps,
 	}
+	if s.ReportSelection.SingleVisual {
+		o.VisualName = s.ReportSelection.VisualName
+	}
+
+	o.DataFormat = s.ReportSelection.DataFormat
+	o.BookmarkName = s.ReportSelection.BookmarkName
+	o.Layout = s.ReportSelection.Layout
+	o.OutputFormat = s.ReportSelection.OutputFormat
 
 	return &o
 }
 
 // WithSavedFilter adds a saved filter to a report.ShareOptions.
 func WithSavedFilter(o ShareOptions, f *domain.Filter) *ShareOptions {
-	o.Filter = inFilterFromDomain(f)
+	o.Filters = SavedTypedFilters(f)
 
 	return &o
 }
 
-// Set up filter from slack user input
-func inFilterFromInput(f *modals.EditInFilterInput) *FilterOptions {
-	return &FilterOptions{
-		Column:                  f.Column,
-		Table:                   f.Table,
-		Value:                   f.Value,
-		LogicalOperator:         f.LogicalOperator,
-		ConditionOperator:       f.ConditionOperator,
-		SecondValue:             f.SecondValue,
-		SecondConditionOperator: f.SecondConditionOperator,
-	}
-}
-
-// Set up filter from saved filter
-func inFilterFromDomain(f *domain.Filter) *FilterOptions {
-	if f.Kind == domain.FilterKindIn {
-		return &FilterOptions{
+// SavedTypedFilters returns filters a saved filter is applied as.
+// NOTE: A filter of domain.FilterKindIn is an advanced filter w/ one or two conditions.
+func SavedTypedFilters(f *domain.Filter) []*domain.TypedFilter {
+	switch f.Kind {
+	case domain.FilterKindIn:
+		i := modals.EditInFilterInput{
 			Column:                  f.Definition.(*FilterOptions).Column,
 			Table:                   f.Definition.(*FilterOptions).Table,
 			Value:                   f.Definition.(*FilterOptions).Value,
@@ -110,18 +114,19 @@ func inFilterFromDomain(f *domain.Filter) *FilterOptions {
 			SecondConditionOperator: f.Definition.(*FilterOptions).SecondConditionOperator,
 			SecondValue:             f.Definition.(*FilterOptions).SecondValue,
 		}
+
+		return []*domain.TypedFilter{i.TypedFilter()}
+
+	case domain.FilterKindTyped:
+		return f.Definition.([]*domain.TypedFilter)
 	}
 
 	return nil
 }
 
-// WithEditedFilter adds an edited filter to a report.ShareOptions.
+// WithEditedFilter adds filters composed by a user to a report.ShareOptions.
 func WithEditedFilter(o ShareOptions, s *modals.ShareReportInput) *ShareOptions {
-	if s.EditFilter != nil {
-		o.Filter = inFilterFromInput(s.EditFilter)
-	} else if s.SaveFilter != nil {
-		o.Filter = inFilterFromInput(&s.SaveFilter.EditInFilterInput)
-	}
+	o.Filters = s.Filters
 
 	return &o
 }